@@ -23,6 +23,10 @@ import (
 	"github.com/wurt83ow/tinyurl/internal/filekeeper"
 	"github.com/wurt83ow/tinyurl/internal/logger"
 	"github.com/wurt83ow/tinyurl/internal/middleware"
+	"github.com/wurt83ow/tinyurl/internal/services/domains"
+	"github.com/wurt83ow/tinyurl/internal/services/geoip"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
 	"github.com/wurt83ow/tinyurl/internal/storage"
 	"github.com/wurt83ow/tinyurl/internal/worker"
 	"go.uber.org/zap"
@@ -42,12 +46,28 @@ func Run() error {
 		return err
 	}
 
+	// Check the ownership scope of the original URLs
+	scope, err := storage.ParseScope(option.LinkScope())
+	if err != nil {
+		return err
+	}
+
+	// Check the default status code of the redirects
+	if err := controllers.CheckRedirectCode(option.RedirectCode()); err != nil {
+		return err
+	}
+
+	// Check the base URLs of the additional domains of the short URLs
+	if _, err := domains.New(option.ShortURLAdress(), option.Domains()); err != nil {
+		return err
+	}
+
 	// Initialize storage keeper based on configuration
 	var keeper storage.Keeper = nil
 	if option.DataBaseDSN() != "" {
-		keeper = bdkeeper.NewBDKeeper(option.DataBaseDSN, nLogger)
+		keeper = bdkeeper.NewBDKeeper(option.DataBaseDSN, nLogger, scope)
 	} else if option.FileStoragePath() != "" {
-		keeper = filekeeper.NewFileKeeper(option.FileStoragePath, nLogger)
+		keeper = filekeeper.NewFileKeeper(option.FileStoragePath, nLogger, scope)
 	}
 
 	// Close the keeper when the function exits
@@ -59,18 +79,78 @@ func Run() error {
 	ctx := context.Background()
 
 	// Initialize memory storage with the chosen keeper and logger
-	memoryStorage := storage.NewMemoryStorage(keeper, nLogger)
+	memoryStorage := storage.NewMemoryStorage(keeper, nLogger, scope)
+
+	// Initialize the short key generator, the counter based strategies continue
+	// the sequence persisted by the keeper
+	keygen, err := shorturl.NewKeyGenerator(option.KeyStrategy(), option.KeyLength(),
+		option.KeyAlphabet(), option.KeySalt(), shorturl.SequenceFunc(memoryStorage.NextSequence))
+	if err != nil {
+		return err
+	}
+
+	// Initialize the normalizer of the original URLs
+	normalizer, err := shorturl.NewNormalizer(option.NormalizeRules())
+	if err != nil {
+		return err
+	}
+
+	// Initialize the validator of the original URLs
+	validator := shorturl.NewValidator(option.AllowedSchemes(), option.MaxURLLength(), option.DenyPrivateHosts())
+
+	// Initialize the sweeper of the expired URLs
+	sweeper := worker.NewSweeper(nLogger, memoryStorage, worker.DefaultSweepInterval)
+
+	// Initialize the purger of the URLs deleted before the grace period
+	var purgeGrace time.Duration
+	if option.PurgeGrace() != "" {
+		purgeGrace, err = time.ParseDuration(option.PurgeGrace())
+		if err != nil {
+			return fmt.Errorf("invalid purge grace %q: %w", option.PurgeGrace(), err)
+		}
+	}
+	purger := worker.NewPurger(nLogger, memoryStorage, worker.DefaultPurgeInterval, purgeGrace)
+
+	// Initialize the recorder of the click events of the redirects
+	clicks := worker.NewClickRecorder(nLogger, memoryStorage, worker.DefaultClickFlushInterval, worker.DefaultClickBatchSize)
+
+	// Open the GeoIP database of the country targeting rules, reloaded on SIGHUP
+	var locator controllers.GeoLocator
+	if option.GeoIPDB() != "" {
+		geo, err := geoip.Open(option.GeoIPDB())
+		if err != nil {
+			return fmt.Errorf("cannot open GeoIP database %q: %w", option.GeoIPDB(), err)
+		}
+		defer geo.Close()
+
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				if err := geo.Reload(); err != nil {
+					nLogger.Info("cannot reload GeoIP database", zap.Error(err))
+					continue
+				}
+				nLogger.Info("GeoIP database reloaded", zap.String("path", option.GeoIPDB()))
+			}
+		}()
+
+		locator = geo
+	}
 
 	// Initialize worker, authorization, and controller
 	worker := worker.NewWorker(nLogger, memoryStorage)
 	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
-	controller := controllers.NewBaseController(memoryStorage, option, nLogger, worker, authz)
+
+	// The failed password attempts of a link are limited across the HTTP and gRPC servers
+	limiter := linkpass.NewLimiter(linkpass.DefaultMaxAttempts, linkpass.DefaultWindow)
+	controller := controllers.NewBaseController(memoryStorage, option, nLogger, worker, authz, keygen, normalizer, validator, limiter, locator, clicks)
 
 	// Create a gRPC server instance
 	grpcServer := grpc.NewServer()
 
 	// Register the gRPC service
-	pb.RegisterURLServiceServer(grpcServer, controllers.NewUsersServer(memoryStorage, option, nLogger, worker, authz))
+	pb.RegisterURLServiceServer(grpcServer, controllers.NewUsersServer(memoryStorage, option, nLogger, worker, authz, keygen, normalizer, validator, limiter, locator, clicks))
 
 	// Add support for reflection API
 	reflection.Register(grpcServer)
@@ -95,6 +175,15 @@ func Run() error {
 	// Start the worker
 	worker.Start(ctx)
 
+	// Start the sweeper of the expired URLs
+	sweeper.Start(ctx)
+
+	// Start the purger of the deleted URLs
+	purger.Start(ctx)
+
+	// Start the recorder of the click events
+	clicks.Start(ctx)
+
 	// Create a new Chi router
 	r := chi.NewRouter()
 
@@ -134,11 +223,21 @@ func Run() error {
 		// Start the worker
 		worker.Stop()
 
+		// Stop the sweeper
+		sweeper.Stop()
+
+		// Stop the purger
+		purger.Stop()
+
 		// Shutdown gracefully shuts down the server, including waiting for requests to complete
 		if err := server.Shutdown(ctx); err != nil {
 			nLogger.Info("Error shutting down server", zap.Error(err))
 		}
 
+		// Stop the recorder of the click events once the redirects are served,
+		// the buffered events are saved
+		clicks.Stop()
+
 		// Graceful shutdown for gRPC server
 		grpcServer.GracefulStop()
 	}()
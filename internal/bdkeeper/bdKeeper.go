@@ -11,6 +11,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/hll"
 	"github.com/wurt83ow/tinyurl/internal/storage"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -34,13 +36,15 @@ type Log interface {
 
 // BDKeeper is a PostgreSQL-backed implementation of the storage.Keeper interface.
 type BDKeeper struct {
-	conn *sql.DB
-	log  Log
+	conn  *sql.DB
+	log   Log
+	scope string
 }
 
 // NewBDKeeper creates a new BDKeeper instance with the provided DSN (data source name) function and logger.
 // It establishes a connection to the PostgreSQL database, performs any required migrations, and returns the BDKeeper instance.
-func NewBDKeeper(dsn func() string, log Log) *BDKeeper {
+// The scope defines whether an original URL is unique across all users or per user.
+func NewBDKeeper(dsn func() string, log Log, scope string) *BDKeeper {
 	addr := dsn()
 	if addr == "" {
 		log.Info("database dsn is empty")
@@ -88,11 +92,22 @@ func NewBDKeeper(dsn func() string, log Log) *BDKeeper {
 		return nil
 	}
 
+	// The urls shortened before the scopes were introduced are in the global scope,
+	// they are moved to the scopes of their users if the urls are unique per user
+	if scope == storage.ScopeUser {
+		_, err = conn.Exec(`UPDATE dataurl SET scope_id = user_id WHERE scope_id = '' AND user_id <> ''`)
+		if err != nil {
+			log.Info("Error while scoping the urls: ", zap.Error(err))
+			return nil
+		}
+	}
+
 	log.Info("Connected!")
 
 	return &BDKeeper{
-		conn: conn,
-		log:  log,
+		conn:  conn,
+		log:   log,
+		scope: scope,
 	}
 }
 
@@ -100,8 +115,23 @@ func NewBDKeeper(dsn func() string, log Log) *BDKeeper {
 func (bdk *BDKeeper) Load() (storage.StorageURL, error) {
 	ctx := context.Background()
 
+	// get the targeting rules, the split variants and the tags of the urls
+	rules, err := bdk.loadRules(ctx, "TRUE")
+	if err != nil {
+		return nil, err
+	}
+	variants, err := bdk.loadVariants(ctx, "TRUE")
+	if err != nil {
+		return nil, err
+	}
+	tags, err := bdk.loadTags(ctx, "TRUE")
+	if err != nil {
+		return nil, err
+	}
+
 	// get data from bd
-	rows, err := bdk.conn.QueryContext(ctx, `SELECT correlation_id, short_url, original_url, user_id, is_deleted FROM dataurl`)
+	rows, err := bdk.conn.QueryContext(ctx, `SELECT correlation_id, short_url, original_url, user_id, is_deleted, expires_at, max_clicks, clicks,
+		password_hash, deleted_at, redirect_code, query_policy, utm_source, utm_medium, utm_campaign, sticky, created_at, note, domain FROM dataurl`)
 
 	if err != nil {
 		return nil, err
@@ -115,10 +145,14 @@ func (bdk *BDKeeper) Load() (storage.StorageURL, error) {
 
 		s := reflect.ValueOf(&record).Elem()
 		numCols := s.NumField()
-		columns := make([]interface{}, numCols)
+		columns := make([]interface{}, 0, numCols)
 		for i := 0; i < numCols; i++ {
+			// the fields stored outside of the dataurl table are loaded separately
+			if s.Type().Field(i).Tag.Get("db") == "-" {
+				continue
+			}
 			field := s.Field(i)
-			columns[i] = field.Addr().Interface()
+			columns = append(columns, field.Addr().Interface())
 		}
 
 		err = rows.Scan(columns...)
@@ -133,7 +167,10 @@ func (bdk *BDKeeper) Load() (storage.StorageURL, error) {
 		}
 
 		key := u.Path
-		key = strings.Replace(key, "/", "", -1)
+		key = storage.DomainKey(record.Domain, strings.Replace(key, "/", "", -1))
+		record.Rules = rules[record.UUID]
+		record.Variants = variants[record.UUID]
+		record.Tags = tags[record.UUID]
 		data[key] = record
 	}
 
@@ -209,29 +246,65 @@ func (bdk *BDKeeper) GetURLsCount() (int, error) {
 	return bdk.getCount("dataurl")
 }
 
+// NextSequence implements storage.SequenceKeeper.
+// The numbers are issued by the short_key_seq sequence, shared by the servers of the database.
+func (bdk *BDKeeper) NextSequence() (uint64, error) {
+	ctx := context.Background()
+
+	var n int64
+	if err := bdk.conn.QueryRowContext(ctx, `SELECT nextval('short_key_seq')`).Scan(&n); err != nil {
+		return 0, err
+	}
+
+	return uint64(n), nil
+}
+
 // UpdateBatch updates the is_deleted flag for the specified URLs in the PostgreSQL database.
 func (bdk *BDKeeper) UpdateBatch(data ...models.DeleteURL) error {
+	return bdk.setDeleted(true, data...)
+}
+
+// RestoreBatch clears the is_deleted flag for the specified URLs in the PostgreSQL database.
+func (bdk *BDKeeper) RestoreBatch(data ...models.DeleteURL) error {
+	return bdk.setDeleted(false, data...)
+}
+
+// setDeleted sets the is_deleted flag for the specified URLs of their users, given by
+// their storage keys, see storage.DomainKey. The key must be the whole last segment of
+// the short URL, so that the URLs of the keys containing it are not changed.
+// The deletion time is kept from the first deletion and cleared on restore.
+func (bdk *BDKeeper) setDeleted(deleted bool, data ...models.DeleteURL) error {
 	ctx := context.Background()
 
 	valueStrings := make([]string, 0, len(data))
-	valueArgs := make([]interface{}, 0, len(data)*2)
+	valueArgs := make([]interface{}, 0, len(data)*3+1)
+	valueArgs = append(valueArgs, deleted)
 	i := 0
 
 	for _, u := range data {
 		for _, k := range u.ShortURLs {
-			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d)", i*2+1, i*2+2))
-			valueArgs = append(valueArgs, k)
+			domain, key := storage.SplitKey(k)
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d)", i*3+2, i*3+3, i*3+4))
+			valueArgs = append(valueArgs, key)
+			valueArgs = append(valueArgs, domain)
 			valueArgs = append(valueArgs, u.UserID)
 			i++
 		}
 	}
+
+	if i == 0 {
+		return nil
+	}
+
 	stmt := fmt.Sprintf(
-		`WITH _data (short_url, user_id) 
+		`WITH _data (short_key, domain, user_id)
 		AS (VALUES %s)
 		UPDATE dataurl AS d
-		SET is_deleted = TRUE
+		SET is_deleted = $1,
+			deleted_at = CASE WHEN $1 THEN COALESCE(d.deleted_at, now()) END
 		FROM _data
-		WHERE d.short_url LIKE '%%' || _data.short_url || '%%'
+		WHERE right(d.short_url, length(_data.short_key) + 1) = '/' || _data.short_key
+			AND d.domain = _data.domain
 			AND d.user_id = _data.user_id`,
 		strings.Join(valueStrings, ","))
 	_, err := bdk.conn.ExecContext(ctx, stmt, valueArgs...)
@@ -243,6 +316,413 @@ func (bdk *BDKeeper) UpdateBatch(data ...models.DeleteURL) error {
 	return nil
 }
 
+// Purge physically deletes the specified soft-deleted URLs and their clicks from the PostgreSQL database.
+func (bdk *BDKeeper) Purge(shortURLs ...string) error {
+	if len(shortURLs) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+
+	placeholders := make([]string, 0, len(shortURLs))
+	args := make([]interface{}, 0, len(shortURLs))
+	for i, u := range shortURLs {
+		placeholders = append(placeholders, fmt.Sprintf("$%d", i+1))
+		args = append(args, u)
+	}
+
+	// the clicks and the sketches of the purged urls are deleted along with them,
+	// so that they are not counted for the urls reusing their keys
+	stmt := fmt.Sprintf(
+		`WITH purged AS (
+			DELETE FROM dataurl
+			WHERE is_deleted
+				AND short_url IN (%s)
+			RETURNING short_url, domain
+		), purged_clicks AS (
+			DELETE FROM clicks c
+			USING purged p
+			WHERE c.domain = p.domain
+				AND right(p.short_url, length(c.short_url) + 1) = '/' || c.short_url
+		)
+		DELETE FROM click_sketches s
+		USING purged p
+		WHERE s.domain = p.domain
+			AND right(p.short_url, length(s.short_url) + 1) = '/' || s.short_url`,
+		strings.Join(placeholders, ","))
+	_, err := bdk.conn.ExecContext(ctx, stmt, args...)
+
+	return err
+}
+
+// Update changes the original URL and replaces the tags of the record stored under the key,
+// keeping the key. It returns storage.ErrNotFound if the user has no live record under the key and
+// storage.ErrConflict together with the existing record if the original URL is
+// already shortened in the scope.
+func (bdk *BDKeeper) Update(key string, data models.DataURL) (models.DataURL, error) {
+	ctx := context.Background()
+
+	res, err := bdk.conn.ExecContext(ctx,
+		`UPDATE dataurl
+		SET original_url = $1
+		WHERE short_url = $2
+			AND user_id = $3
+			AND NOT is_deleted`,
+		data.OriginalURL, data.ShortURL, data.UserID)
+
+	var e *pgconn.PgError
+	if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+		bdk.log.Info("unique field violation on column: ", zap.Error(err))
+
+		m, nerr := bdk.getURL(ctx, "d.scope_id = $1 AND d.original_url = $2 AND d.domain = $3",
+			storage.Owner(bdk.scope, data.UserID), data.OriginalURL, data.Domain)
+		if nerr != nil {
+			return data, nerr
+		}
+
+		return m, storage.ErrConflict
+	}
+	if err != nil {
+		return data, err
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return data, storage.ErrNotFound
+	}
+
+	m, err := bdk.getURL(ctx, "d.short_url = $1", data.ShortURL)
+	if err != nil {
+		return m, err
+	}
+
+	// replace the tags of the updated record
+	if _, err = bdk.conn.ExecContext(ctx, `DELETE FROM dataurl_tags WHERE url_id = $1`, m.UUID); err != nil {
+		return m, err
+	}
+	m.Tags = data.Tags
+	if err = bdk.saveTags(ctx, m); err != nil {
+		return m, err
+	}
+
+	return m, nil
+}
+
+// AddClick counts a redirect of the click-limited URL stored under the key. The counter is
+// incremented in a single conditional update, so that concurrent redirects cannot exceed
+// the limit. It returns the updated data, or storage.ErrClicksExhausted if the limit is reached.
+func (bdk *BDKeeper) AddClick(key string, data models.DataURL) (models.DataURL, error) {
+	ctx := context.Background()
+
+	err := bdk.conn.QueryRowContext(ctx,
+		`UPDATE dataurl
+		SET clicks = clicks + 1
+		WHERE short_url = $1
+			AND clicks < max_clicks
+		RETURNING clicks`,
+		data.ShortURL).Scan(&data.Clicks)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		data.Clicks = data.MaxClicks
+		return data, storage.ErrClicksExhausted
+	}
+	if err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+// AddVariantClick counts a redirect to the variant, numbered from one, of the split URL
+// stored under the key. It returns the data with the updated counter of the variant.
+func (bdk *BDKeeper) AddVariantClick(key string, data models.DataURL, variant int) (models.DataURL, error) {
+	ctx := context.Background()
+
+	err := bdk.conn.QueryRowContext(ctx,
+		`UPDATE url_variants v
+		SET clicks = v.clicks + 1
+		FROM dataurl d
+		WHERE d.correlation_id = v.url_id
+			AND d.short_url = $1
+			AND v.position = $2
+		RETURNING v.clicks`,
+		data.ShortURL, variant-1).Scan(&data.Variants[variant-1].Clicks)
+	if errors.Is(err, sql.ErrNoRows) {
+		return data, storage.ErrNotFound
+	}
+
+	return data, err
+}
+
+// clicksPerInsert is the number of the click events inserted by one statement,
+// it keeps the number of the statement parameters within the PostgreSQL limit.
+const clicksPerInsert = 1000
+
+// SaveClicks implements storage.ClickKeeper.
+// The click events are batch-inserted into the clicks table, the short key and the domain
+// of the URL are split from the storage key of the event, see storage.DomainKey. The visitors
+// of the events are added to the HyperLogLog sketches of their URLs and hours in the same
+// transaction.
+func (bdk *BDKeeper) SaveClicks(events ...models.ClickEvent) error {
+	ctx := context.Background()
+
+	tx, err := bdk.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for rest := events; len(rest) != 0; {
+		n := min(len(rest), clicksPerInsert)
+
+		valueStrings := make([]string, 0, n)
+		valueArgs := make([]interface{}, 0, n*8)
+		for i, e := range rest[:n] {
+			domain, key := storage.SplitKey(e.Key)
+			valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+				i*8+1, i*8+2, i*8+3, i*8+4, i*8+5, i*8+6, i*8+7, i*8+8))
+			valueArgs = append(valueArgs, key, domain, e.Time, e.Referrer, e.UserAgent, e.IP, e.UserID, e.Country)
+		}
+
+		stmt := fmt.Sprintf(
+			`INSERT INTO clicks (short_url, domain, clicked_at, referrer, user_agent, ip, user_id, country)
+			VALUES %s`,
+			strings.Join(valueStrings, ","))
+		if _, err = tx.ExecContext(ctx, stmt, valueArgs...); err != nil {
+			return err
+		}
+
+		rest = rest[n:]
+	}
+
+	// the sketches of the visitors of the events by URL and hour
+	type urlHour struct {
+		key   string
+		start time.Time
+	}
+	sketches := make(map[urlHour]*hll.Sketch)
+	for _, e := range events {
+		h := urlHour{key: e.Key, start: e.Time.UTC().Truncate(time.Hour)}
+		v, ok := sketches[h]
+		if !ok {
+			v = hll.New()
+			sketches[h] = v
+		}
+		v.Add(e.Visitor())
+	}
+
+	// the sketches are merged in order, so that the concurrent transactions of the servers
+	// lock them in the same order
+	hours := make([]urlHour, 0, len(sketches))
+	for h := range sketches {
+		hours = append(hours, h)
+	}
+	sort.Slice(hours, func(i, j int) bool {
+		if hours[i].key != hours[j].key {
+			return hours[i].key < hours[j].key
+		}
+		return hours[i].start.Before(hours[j].start)
+	})
+
+	for _, h := range hours {
+		if err = bdk.mergeSketch(ctx, tx, h.key, h.start, sketches[h]); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// mergeSketch merges the sketch of the visitors into the one stored for the URL under the key
+// and the hour starting at the time. A new sketch is inserted, a stored one is locked, merged
+// and updated, so that the servers saving the visitors of the same hour keep each other's.
+func (bdk *BDKeeper) mergeSketch(ctx context.Context, tx *sql.Tx, k string, start time.Time, visitors *hll.Sketch) error {
+	domain, key := storage.SplitKey(k)
+
+	b, err := visitors.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO click_sketches (short_url, domain, bucket_start, sketch)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (domain, short_url, bucket_start) DO NOTHING`,
+		key, domain, start, b)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n != 0 {
+		return err
+	}
+
+	var stored []byte
+	err = tx.QueryRowContext(ctx,
+		`SELECT sketch
+		FROM click_sketches
+		WHERE short_url = $1
+			AND domain = $2
+			AND bucket_start = $3
+		FOR UPDATE`,
+		key, domain, start).Scan(&stored)
+	if err != nil {
+		return err
+	}
+
+	// a malformed stored sketch is replaced
+	merged, err := hll.Parse(stored)
+	if err != nil {
+		bdk.log.Info("cannot parse sketch: ", zap.String("key", k), zap.Error(err))
+		merged = hll.New()
+	}
+	merged.Merge(visitors)
+
+	if b, err = merged.MarshalBinary(); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE click_sketches
+		SET sketch = $4
+		WHERE short_url = $1
+			AND domain = $2
+			AND bucket_start = $3`,
+		key, domain, start, b)
+
+	return err
+}
+
+// ClickStats implements storage.ClickStatsKeeper.
+// The clicks are rolled up by the database: the totals, the buckets of the interval of
+// the query, truncated in UTC, and the top referrers, countries and user agents. The unique
+// visitors are estimated by the union of the stored sketches of the hours of the range.
+func (bdk *BDKeeper) ClickStats(key string, q storage.StatsQuery) (models.URLStats, error) {
+	ctx := context.Background()
+
+	var stats models.URLStats
+	domain, key := storage.SplitKey(key)
+	args := []interface{}{key, domain, q.From, q.To}
+	cond := `short_url = $1 AND domain = $2 AND clicked_at >= $3 AND clicked_at < $4`
+
+	err := bdk.conn.QueryRowContext(ctx, fmt.Sprintf(
+		`SELECT count(*)
+		FROM clicks
+		WHERE %s`, cond),
+		args...).Scan(&stats.Clicks)
+	if err != nil {
+		return stats, err
+	}
+
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT date_trunc($5, clicked_at AT TIME ZONE 'UTC'), count(*)
+		FROM clicks
+		WHERE %s
+		GROUP BY 1
+		ORDER BY 1`, cond),
+		append(args, q.Interval)...)
+	if err != nil {
+		return stats, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var b models.StatsBucket
+		if err = rows.Scan(&b.Start, &b.Clicks); err != nil {
+			return stats, err
+		}
+		// the truncated time is without a time zone, it is in UTC
+		b.Start = time.Date(b.Start.Year(), b.Start.Month(), b.Start.Day(), b.Start.Hour(), 0, 0, 0, time.UTC)
+		stats.Buckets = append(stats.Buckets, b)
+	}
+	if err = rows.Err(); err != nil {
+		return stats, err
+	}
+
+	hours, err := bdk.loadSketches(ctx, args...)
+	if err != nil {
+		return stats, err
+	}
+	storage.EstimateUnique(q, &stats, hours)
+
+	if stats.Referrers, err = bdk.topClicks(ctx, "referrer", cond, q.TopLimit(), args...); err != nil {
+		return stats, err
+	}
+	if stats.Countries, err = bdk.topClicks(ctx, "country", cond, q.TopLimit(), args...); err != nil {
+		return stats, err
+	}
+	if stats.UserAgents, err = bdk.topClicks(ctx, "user_agent", cond, q.TopLimit(), args...); err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// loadSketches retrieves the sketches of the visitors of the URL of the short key and domain
+// by the start of their hours in the range, given in this order by the arguments.
+func (bdk *BDKeeper) loadSketches(ctx context.Context, args ...interface{}) (map[time.Time]*hll.Sketch, error) {
+	rows, err := bdk.conn.QueryContext(ctx,
+		`SELECT bucket_start, sketch
+		FROM click_sketches
+		WHERE short_url = $1
+			AND domain = $2
+			AND bucket_start >= $3
+			AND bucket_start < $4`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hours := make(map[time.Time]*hll.Sketch)
+	for rows.Next() {
+		var (
+			start time.Time
+			b     []byte
+		)
+		if err = rows.Scan(&start, &b); err != nil {
+			return nil, err
+		}
+
+		visitors, err := hll.Parse(b)
+		if err != nil {
+			bdk.log.Info("cannot parse sketch: ", zap.Error(err))
+			continue
+		}
+		hours[start.UTC()] = visitors
+	}
+
+	return hours, rows.Err()
+}
+
+// topClicks returns up to limit non-empty values of the column of the clicks matching the
+// condition with the most clicks, ties ordered by value.
+func (bdk *BDKeeper) topClicks(ctx context.Context, column string, cond string, limit int,
+	args ...interface{}) ([]models.StatsCount, error) {
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT %[1]s, count(*)
+		FROM clicks
+		WHERE %[2]s
+			AND %[1]s <> ''
+		GROUP BY %[1]s
+		ORDER BY 2 DESC, 1
+		LIMIT %[3]d`, column, cond, limit),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := make([]models.StatsCount, 0, limit)
+	for rows.Next() {
+		var c models.StatsCount
+		if err = rows.Scan(&c.Value, &c.Clicks); err != nil {
+			return nil, err
+		}
+		list = append(list, c)
+	}
+
+	return list, rows.Err()
+}
+
 // Save inserts or updates the specified URL data in the PostgreSQL database.
 // It returns the saved data along with any error encountered.
 func (bdk *BDKeeper) Save(key string, data models.DataURL) (models.DataURL, error) {
@@ -261,41 +741,397 @@ func (bdk *BDKeeper) Save(key string, data models.DataURL) (models.DataURL, erro
 			short_url,
 			original_url,
 			user_id,
-			is_deleted)
-		VALUES ($1, $2, $3, $4, $5) 
+			is_deleted,
+			scope_id,
+			expires_at,
+			max_clicks,
+			password_hash,
+			redirect_code,
+			query_policy,
+			utm_source,
+			utm_medium,
+			utm_campaign,
+			sticky,
+			created_at,
+			note,
+			domain)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) 
 		RETURNING original_url`,
-		id, data.ShortURL, data.OriginalURL, data.UserID, data.DeletedFlag)
+		id, data.ShortURL, data.OriginalURL, data.UserID, data.DeletedFlag, storage.Owner(bdk.scope, data.UserID),
+		data.ExpiresAt, data.MaxClicks, data.PasswordHash, data.RedirectCode,
+		data.QueryPolicy, data.UTMSource, data.UTMMedium, data.UTMCampaign, data.Sticky, data.CreatedAt, data.Note, data.Domain)
+
+	// the short URL is already bound to another original URL
+	var e *pgconn.PgError
+	if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation && e.ConstraintName == "uniq_short_url" {
+		bdk.log.Info("short key collision: ", zap.Error(err))
+
+		m, nerr := bdk.getURL(ctx, "d.short_url = $1", data.ShortURL)
+		if nerr != nil {
+			return data, nerr
+		}
+
+		return m, storage.ErrCollision
+	}
+
+	// save the targeting rules, the split variants and the tags of the inserted record
+	if err == nil {
+		data.UUID = id
+		if nerr := bdk.saveRules(ctx, data); nerr != nil {
+			return data, nerr
+		}
+		if nerr := bdk.saveVariants(ctx, data); nerr != nil {
+			return data, nerr
+		}
+		if nerr := bdk.saveTags(ctx, data); nerr != nil {
+			return data, nerr
+		}
+	}
+
+	// read the record owning the original URL on its domain in the scope
+	m, nerr := bdk.getURL(ctx, "d.scope_id = $1 AND d.original_url = $2 AND d.domain = $3",
+		storage.Owner(bdk.scope, data.UserID), data.OriginalURL, data.Domain)
+	if nerr != nil {
+		return data, nerr
+	}
+
+	if err != nil {
+		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
+			bdk.log.Info("unique field violation on column: ", zap.Error(err))
+
+			return m, storage.ErrConflict
+		}
+		return m, err
+	}
+
+	return m, nil
+}
+
+// SaveAlias inserts the specified URL data under a custom alias in the PostgreSQL database.
+// If the alias or the original URL is already taken, it returns the existing record and storage.ErrConflict.
+func (bdk *BDKeeper) SaveAlias(key string, data models.DataURL) (models.DataURL, error) {
+	ctx := context.Background()
+
+	if data.UUID == "" {
+		data.UUID = uuid.New().String()
+	}
+
+	_, err := bdk.conn.ExecContext(ctx,
+		`INSERT INTO dataurl (
+			correlation_id,
+			short_url,
+			original_url,
+			user_id,
+			is_deleted,
+			scope_id,
+			expires_at,
+			max_clicks,
+			password_hash,
+			redirect_code,
+			query_policy,
+			utm_source,
+			utm_medium,
+			utm_campaign,
+			sticky,
+			created_at,
+			note,
+			domain)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		data.UUID, data.ShortURL, data.OriginalURL, data.UserID, data.DeletedFlag,
+		storage.Owner(bdk.scope, data.UserID), data.ExpiresAt, data.MaxClicks, data.PasswordHash, data.RedirectCode,
+		data.QueryPolicy, data.UTMSource, data.UTMMedium, data.UTMCampaign, data.Sticky, data.CreatedAt, data.Note, data.Domain)
+	if err == nil {
+		if err = bdk.saveRules(ctx, data); err != nil {
+			return data, err
+		}
+		if err = bdk.saveVariants(ctx, data); err != nil {
+			return data, err
+		}
+		return data, bdk.saveTags(ctx, data)
+	}
+
+	var e *pgconn.PgError
+	if !errors.As(err, &e) || e.Code != pgerrcode.UniqueViolation {
+		return data, err
+	}
+	bdk.log.Info("unique field violation on column: ", zap.Error(err))
+
+	// find out which record occupies the alias or the original URL
+	var m models.DataURL
+	if e.ConstraintName == "uniq_short_url" {
+		m, err = bdk.getURL(ctx, "d.short_url = $1", data.ShortURL)
+	} else {
+		m, err = bdk.getURL(ctx, "d.scope_id = $1 AND d.original_url = $2 AND d.domain = $3",
+			storage.Owner(bdk.scope, data.UserID), data.OriginalURL, data.Domain)
+	}
+	if err != nil {
+		return data, err
+	}
+
+	return m, storage.ErrConflict
+}
+
+// SearchKeys implements storage.Searcher.
+// The terms are matched with ILIKE, which is served by the trigram indexes of the columns.
+func (bdk *BDKeeper) SearchKeys(userID string, terms []string, limit int) ([]string, error) {
+	ctx := context.Background()
+
+	// the key is the last segment of the short URL, stored under its domain
+	const key = `reverse(split_part(reverse(d.short_url), '/', 1))`
+
+	conds := make([]string, 0, len(terms))
+	args := []interface{}{userID, limit}
+	for _, term := range terms {
+		args = append(args, "%"+likeEscaper.Replace(term)+"%")
+		n := len(args)
+		conds = append(conds, fmt.Sprintf(`(%s ILIKE $%d OR d.domain ILIKE $%d OR d.original_url ILIKE $%d OR d.note ILIKE $%d
+			OR EXISTS (SELECT 1 FROM dataurl_tags t WHERE t.url_id = d.correlation_id AND t.tag ILIKE $%d))`,
+			key, n, n, n, n, n))
+	}
+
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(`
+	SELECT CASE WHEN d.domain = '' THEN %[1]s ELSE d.domain || '/' || %[1]s END
+	FROM dataurl d
+	WHERE
+		d.user_id = $1
+		AND %[2]s
+	ORDER BY d.created_at DESC NULLS LAST, 1 DESC
+	LIMIT $2`, key, strings.Join(conds, " AND ")),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err = rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// likeEscaper escapes the wildcards of the LIKE patterns.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// saveRules inserts the targeting rules of the record in the PostgreSQL database.
+// The rules are only saved if the record with the correlation id is bound to the
+// short URL, so that the rules of a record skipped on conflict are dropped.
+func (bdk *BDKeeper) saveRules(ctx context.Context, data models.DataURL) error {
+	for i, rule := range data.Rules {
+		_, err := bdk.conn.ExecContext(ctx,
+			`INSERT INTO url_rules (url_id, position, platform, language, referrer, country, url)
+			SELECT d.correlation_id, $3, $4, $5, $6, $7, $8
+			FROM dataurl d
+			WHERE d.correlation_id = $1
+				AND d.short_url = $2
+			ON CONFLICT (url_id, position) DO NOTHING`,
+			data.UUID, data.ShortURL, i, rule.Platform, rule.Language, rule.Referrer, rule.Country, rule.URL)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadRules retrieves the targeting rules matching the condition, ordered by
+// their position and grouped by the correlation id of their records.
+func (bdk *BDKeeper) loadRules(ctx context.Context, cond string, args ...interface{}) (map[string][]models.Rule, error) {
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(`
+	SELECT
+		r.url_id,
+		r.platform,
+		r.language,
+		r.referrer,
+		r.country,
+		r.url
+	FROM url_rules r
+	WHERE
+		%s
+	ORDER BY r.url_id, r.position`, cond),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := make(map[string][]models.Rule)
+	for rows.Next() {
+		var id string
+		var rule models.Rule
+		if err = rows.Scan(&id, &rule.Platform, &rule.Language, &rule.Referrer, &rule.Country, &rule.URL); err != nil {
+			return nil, err
+		}
+		rules[id] = append(rules[id], rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// saveVariants inserts the split variants of the record in the PostgreSQL database.
+// Like the rules, the variants are only saved if the record with the correlation id
+// is bound to the short URL.
+func (bdk *BDKeeper) saveVariants(ctx context.Context, data models.DataURL) error {
+	for i, variant := range data.Variants {
+		_, err := bdk.conn.ExecContext(ctx,
+			`INSERT INTO url_variants (url_id, position, url, weight)
+			SELECT d.correlation_id, $3, $4, $5
+			FROM dataurl d
+			WHERE d.correlation_id = $1
+				AND d.short_url = $2
+			ON CONFLICT (url_id, position) DO NOTHING`,
+			data.UUID, data.ShortURL, i, variant.URL, variant.Weight)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadVariants retrieves the split variants matching the condition, ordered by
+// their position and grouped by the correlation id of their records.
+func (bdk *BDKeeper) loadVariants(ctx context.Context, cond string, args ...interface{}) (map[string][]models.Variant, error) {
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(`
+	SELECT
+		v.url_id,
+		v.url,
+		v.weight,
+		v.clicks
+	FROM url_variants v
+	WHERE
+		%s
+	ORDER BY v.url_id, v.position`, cond),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	variants := make(map[string][]models.Variant)
+	for rows.Next() {
+		var id string
+		var variant models.Variant
+		if err = rows.Scan(&id, &variant.URL, &variant.Weight, &variant.Clicks); err != nil {
+			return nil, err
+		}
+		variants[id] = append(variants[id], variant)
+	}
+
+	return variants, rows.Err()
+}
+
+// saveTags inserts the tags of the record in the PostgreSQL database.
+// Like the rules, the tags are only saved if the record with the correlation id
+// is bound to the short URL.
+func (bdk *BDKeeper) saveTags(ctx context.Context, data models.DataURL) error {
+	for _, tag := range data.Tags {
+		_, err := bdk.conn.ExecContext(ctx,
+			`INSERT INTO dataurl_tags (url_id, tag)
+			SELECT d.correlation_id, $3
+			FROM dataurl d
+			WHERE d.correlation_id = $1
+				AND d.short_url = $2
+			ON CONFLICT (url_id, tag) DO NOTHING`,
+			data.UUID, data.ShortURL, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
-	row := bdk.conn.QueryRowContext(ctx, `
+// loadTags retrieves the tags matching the condition, ordered alphabetically and
+// grouped by the correlation id of their records.
+func (bdk *BDKeeper) loadTags(ctx context.Context, cond string, args ...interface{}) (map[string][]string, error) {
+	rows, err := bdk.conn.QueryContext(ctx, fmt.Sprintf(`
+	SELECT
+		t.url_id,
+		t.tag
+	FROM dataurl_tags t
+	WHERE
+		%s
+	ORDER BY t.url_id, t.tag`, cond),
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := make(map[string][]string)
+	for rows.Next() {
+		var id, tag string
+		if err = rows.Scan(&id, &tag); err != nil {
+			return nil, err
+		}
+		tags[id] = append(tags[id], tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// getURL retrieves a single URL record matching the condition.
+func (bdk *BDKeeper) getURL(ctx context.Context, cond string, args ...interface{}) (models.DataURL, error) {
+	row := bdk.conn.QueryRowContext(ctx, fmt.Sprintf(`
 	SELECT
 		d.correlation_id,
-		d.short_url  ,
+		d.short_url,
 		d.original_url,
 		d.user_id,
-		d.is_deleted	 
-	FROM dataurl d	 
+		d.is_deleted,
+		d.expires_at,
+		d.max_clicks,
+		d.clicks,
+		d.password_hash,
+		d.deleted_at,
+		d.redirect_code,
+		d.query_policy,
+		d.utm_source,
+		d.utm_medium,
+		d.utm_campaign,
+		d.sticky,
+		d.created_at,
+		d.note,
+		d.domain
+	FROM dataurl d
 	WHERE
-		d.original_url = $1`,
-		data.OriginalURL,
+		%s`, cond),
+		args...,
 	)
 
-	// read the values from the database record into the corresponding fields of the structure
 	var m models.DataURL
-	nerr := row.Scan(&m.UUID, &m.ShortURL, &m.OriginalURL, &m.UserID, &m.DeletedFlag)
-	if nerr != nil {
+	err := row.Scan(&m.UUID, &m.ShortURL, &m.OriginalURL, &m.UserID, &m.DeletedFlag, &m.ExpiresAt,
+		&m.MaxClicks, &m.Clicks, &m.PasswordHash, &m.DeletedAt,
+		&m.RedirectCode, &m.QueryPolicy, &m.UTMSource, &m.UTMMedium, &m.UTMCampaign, &m.Sticky,
+		&m.CreatedAt, &m.Note, &m.Domain)
+	if err != nil {
 		bdk.log.Info("row scan error: ", zap.Error(err))
-		return data, nerr
+		return m, err
 	}
 
+	rules, err := bdk.loadRules(ctx, "r.url_id = $1", m.UUID)
 	if err != nil {
-		var e *pgconn.PgError
-		if errors.As(err, &e) && e.Code == pgerrcode.UniqueViolation {
-			bdk.log.Info("unique field violation on column: ", zap.Error(err))
+		return m, err
+	}
+	m.Rules = rules[m.UUID]
 
-			return m, storage.ErrConflict
-		}
+	variants, err := bdk.loadVariants(ctx, "v.url_id = $1", m.UUID)
+	if err != nil {
 		return m, err
 	}
+	m.Variants = variants[m.UUID]
+
+	tags, err := bdk.loadTags(ctx, "t.url_id = $1", m.UUID)
+	if err != nil {
+		return m, err
+	}
+	m.Tags = tags[m.UUID]
 
 	return m, nil
 }
@@ -369,16 +1205,31 @@ func (bdk *BDKeeper) SaveBatch(data storage.StorageURL) error {
 	ctx := context.Background()
 
 	valueStrings := make([]string, 0, len(data))
-	valueArgs := make([]interface{}, 0, len(data)*5)
+	valueArgs := make([]interface{}, 0, len(data)*18)
 	i := 0
 	for _, u := range data {
-		valueStrings = append(valueStrings, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d)",
-			i*5+1, i*5+2, i*5+3, i*5+4, i*5+5))
+		valueStrings = append(valueStrings, fmt.Sprintf(
+			"($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			i*18+1, i*18+2, i*18+3, i*18+4, i*18+5, i*18+6, i*18+7, i*18+8, i*18+9, i*18+10,
+			i*18+11, i*18+12, i*18+13, i*18+14, i*18+15, i*18+16, i*18+17, i*18+18))
 		valueArgs = append(valueArgs, u.UUID)
 		valueArgs = append(valueArgs, u.ShortURL)
 		valueArgs = append(valueArgs, u.OriginalURL)
 		valueArgs = append(valueArgs, u.UserID)
 		valueArgs = append(valueArgs, u.DeletedFlag)
+		valueArgs = append(valueArgs, storage.Owner(bdk.scope, u.UserID))
+		valueArgs = append(valueArgs, u.ExpiresAt)
+		valueArgs = append(valueArgs, u.MaxClicks)
+		valueArgs = append(valueArgs, u.PasswordHash)
+		valueArgs = append(valueArgs, u.RedirectCode)
+		valueArgs = append(valueArgs, u.QueryPolicy)
+		valueArgs = append(valueArgs, u.UTMSource)
+		valueArgs = append(valueArgs, u.UTMMedium)
+		valueArgs = append(valueArgs, u.UTMCampaign)
+		valueArgs = append(valueArgs, u.Sticky)
+		valueArgs = append(valueArgs, u.CreatedAt)
+		valueArgs = append(valueArgs, u.Note)
+		valueArgs = append(valueArgs, u.Domain)
 		i++
 	}
 
@@ -388,8 +1239,21 @@ func (bdk *BDKeeper) SaveBatch(data storage.StorageURL) error {
 		short_url,
 		original_url,
 		user_id,
-		is_deleted)
-		VALUES %s ON CONFLICT (original_url) DO NOTHING`,
+		is_deleted,
+		scope_id,
+		expires_at,
+		max_clicks,
+		password_hash,
+		redirect_code,
+		query_policy,
+		utm_source,
+		utm_medium,
+		utm_campaign,
+		sticky,
+		created_at,
+		note,
+		domain)
+		VALUES %s ON CONFLICT (scope_id, domain, original_url) DO NOTHING`,
 		strings.Join(valueStrings, ","))
 	_, err := bdk.conn.ExecContext(ctx, stmt, valueArgs...)
 
@@ -397,6 +1261,19 @@ func (bdk *BDKeeper) SaveBatch(data storage.StorageURL) error {
 		return err
 	}
 
+	// save the targeting rules, the split variants and the tags of the inserted records
+	for _, u := range data {
+		if err = bdk.saveRules(ctx, u); err != nil {
+			return err
+		}
+		if err = bdk.saveVariants(ctx, u); err != nil {
+			return err
+		}
+		if err = bdk.saveTags(ctx, u); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
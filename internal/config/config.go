@@ -50,6 +50,7 @@ import (
 type Options struct {
 	flagRunAddr         string
 	flagShortURLAdress  string
+	flagDomains         string
 	flagLogLevel        string
 	flagFileStoragePath string
 	flagDataBaseDSN     string
@@ -59,6 +60,19 @@ type Options struct {
 	flagHTTPSCertFile   string
 	flagHTTPSKeyFile    string
 	flagTrustedSubnet   string
+	flagKeyStrategy     string
+	flagKeyLength       int
+	flagKeyAlphabet     string
+	flagKeySalt         string
+	flagNormalizeRules  string
+	flagAllowedSchemes  string
+	flagMaxURLLength    int
+	flagDenyPrivate     bool
+	flagLinkScope       string
+	flagPurgeGrace      string
+	flagRedirectCode    int
+	flagGeoIPDB         string
+	flagInterstitial    bool
 }
 
 // NewOptions creates a new instance of Options.
@@ -70,6 +84,7 @@ func NewOptions() *Options {
 func (o *Options) ParseFlags() {
 	regStringVar(&o.flagRunAddr, "a", ":8080", "address and port to run server")
 	regStringVar(&o.flagShortURLAdress, "b", "http://localhost:8080/", "server`s address for shor url")
+	regStringVar(&o.flagDomains, "domains", "", "comma-separated base URLs of the additional domains of the short URLs")
 	regStringVar(&o.flagLogLevel, "l", "info", "log level")
 	regStringVar(&o.flagFileStoragePath, "f", "test777", "")
 	regStringVar(&o.flagDataBaseDSN, "d", "", "")
@@ -79,6 +94,21 @@ func (o *Options) ParseFlags() {
 	regStringVar(&o.flagHTTPSCertFile, "r", "", "path to https cert file")
 	regStringVar(&o.flagHTTPSKeyFile, "k", "", "path to https key file")
 	regStringVar(&o.flagTrustedSubnet, "t", "", "trusted subnet")
+	regStringVar(&o.flagKeyStrategy, "key-strategy", "", "short key generation strategy: hash, random, counter or hashids")
+	regIntVar(&o.flagKeyLength, "key-length", 0, "short key length for the random, counter and hashids strategies")
+	regStringVar(&o.flagKeyAlphabet, "key-alphabet", "", "alphabet of the short keys")
+	regStringVar(&o.flagKeySalt, "key-salt", "", "salt of the hashids strategy")
+	regStringVar(&o.flagNormalizeRules, "normalize-rules", "",
+		"comma-separated URL normalization rules: lowercase, default-port, punycode, dot-segments, sort-query, strip-tracking or none")
+	regStringVar(&o.flagAllowedSchemes, "allowed-schemes", "", "comma-separated URL schemes allowed for shortening")
+	regIntVar(&o.flagMaxURLLength, "max-url-length", 0, "maximum length of the URLs to shorten")
+	regBoolVar(&o.flagDenyPrivate, "deny-private-hosts", false, "reject URLs pointing to private or loopback hosts")
+	regStringVar(&o.flagLinkScope, "link-scope", "", "ownership scope of the original URLs: global or user")
+	regIntVar(&o.flagRedirectCode, "redirect-code", 0, "default HTTP status code of the redirects: 301, 302, 307 or 308")
+	regStringVar(&o.flagPurgeGrace, "purge-grace", "", "period the deleted URLs can be restored in before they are purged, e.g. 720h")
+	regBoolVar(&o.flagInterstitial, "interstitial-external", false,
+		"show the preview page instead of redirecting to the destinations on external domains")
+	regStringVar(&o.flagGeoIPDB, "geoip-db", "", "path to the MaxMind GeoIP database file (.mmdb) of the country targeting rules")
 	// parse the arguments passed to the server into registered variables
 	flag.Parse()
 
@@ -91,6 +121,10 @@ func (o *Options) ParseFlags() {
 		o.flagShortURLAdress = envShortURLAdress
 	}
 
+	if envDomains := os.Getenv("DOMAINS"); envDomains != "" {
+		o.flagDomains = envDomains
+	}
+
 	if envLogLevel := os.Getenv("LOG_LEVEL"); envLogLevel != "" {
 		o.flagLogLevel = envLogLevel
 	}
@@ -119,6 +153,83 @@ func (o *Options) ParseFlags() {
 		o.flagTrustedSubnet = envTrustedSubnet
 	}
 
+	if envKeyStrategy := os.Getenv("KEY_STRATEGY"); envKeyStrategy != "" {
+		o.flagKeyStrategy = envKeyStrategy
+	}
+
+	if envKeyLength := os.Getenv("KEY_LENGTH"); envKeyLength != "" {
+		keyLength, err := strconv.Atoi(envKeyLength)
+		if err == nil {
+			o.flagKeyLength = keyLength
+		} else {
+			fmt.Println("Failed to parse KEY_LENGTH as an integer value:", err)
+		}
+	}
+
+	if envKeyAlphabet := os.Getenv("KEY_ALPHABET"); envKeyAlphabet != "" {
+		o.flagKeyAlphabet = envKeyAlphabet
+	}
+
+	if envKeySalt := os.Getenv("KEY_SALT"); envKeySalt != "" {
+		o.flagKeySalt = envKeySalt
+	}
+
+	if envNormalizeRules := os.Getenv("NORMALIZE_RULES"); envNormalizeRules != "" {
+		o.flagNormalizeRules = envNormalizeRules
+	}
+
+	if envAllowedSchemes := os.Getenv("ALLOWED_SCHEMES"); envAllowedSchemes != "" {
+		o.flagAllowedSchemes = envAllowedSchemes
+	}
+
+	if envMaxURLLength := os.Getenv("MAX_URL_LENGTH"); envMaxURLLength != "" {
+		maxURLLength, err := strconv.Atoi(envMaxURLLength)
+		if err == nil {
+			o.flagMaxURLLength = maxURLLength
+		} else {
+			fmt.Println("Failed to parse MAX_URL_LENGTH as an integer value:", err)
+		}
+	}
+
+	if envDenyPrivate := os.Getenv("DENY_PRIVATE_HOSTS"); envDenyPrivate != "" {
+		denyPrivate, err := strconv.ParseBool(envDenyPrivate)
+		if err == nil {
+			o.flagDenyPrivate = denyPrivate
+		} else {
+			fmt.Println("Failed to parse DENY_PRIVATE_HOSTS as a boolean value:", err)
+		}
+	}
+
+	if envInterstitial := os.Getenv("INTERSTITIAL_EXTERNAL"); envInterstitial != "" {
+		interstitial, err := strconv.ParseBool(envInterstitial)
+		if err == nil {
+			o.flagInterstitial = interstitial
+		} else {
+			fmt.Println("Failed to parse INTERSTITIAL_EXTERNAL as a boolean value:", err)
+		}
+	}
+
+	if envLinkScope := os.Getenv("LINK_SCOPE"); envLinkScope != "" {
+		o.flagLinkScope = envLinkScope
+	}
+
+	if envPurgeGrace := os.Getenv("PURGE_GRACE"); envPurgeGrace != "" {
+		o.flagPurgeGrace = envPurgeGrace
+	}
+
+	if envRedirectCode := os.Getenv("REDIRECT_CODE"); envRedirectCode != "" {
+		redirectCode, err := strconv.Atoi(envRedirectCode)
+		if err == nil {
+			o.flagRedirectCode = redirectCode
+		} else {
+			fmt.Println("Failed to parse REDIRECT_CODE as an integer value:", err)
+		}
+	}
+
+	if envGeoIPDB := os.Getenv("GEOIP_DB"); envGeoIPDB != "" {
+		o.flagGeoIPDB = envGeoIPDB
+	}
+
 	if envConfigFile := os.Getenv("CONFIG"); envConfigFile != "" {
 		o.flagConfigFile = envConfigFile
 	}
@@ -156,6 +267,11 @@ func (o *Options) ShortURLAdress() string {
 	return getStringFlag("b")
 }
 
+// Domains returns the configured comma-separated base URLs of the additional domains of the short URLs.
+func (o *Options) Domains() string {
+	return getStringFlag("domains")
+}
+
 // LogLevel returns the configured log level.
 func (o *Options) LogLevel() string {
 	return getStringFlag("l")
@@ -191,6 +307,72 @@ func (o *Options) TrustedSubnet() string {
 	return getStringFlag("t")
 }
 
+// KeyStrategy returns the configured short key generation strategy.
+func (o *Options) KeyStrategy() string {
+	return getStringFlag("key-strategy")
+}
+
+// KeyLength returns the configured short key length.
+func (o *Options) KeyLength() int {
+	return getIntFlag("key-length")
+}
+
+// KeyAlphabet returns the configured alphabet of the short keys.
+func (o *Options) KeyAlphabet() string {
+	return getStringFlag("key-alphabet")
+}
+
+// KeySalt returns the configured salt of the hashids strategy.
+func (o *Options) KeySalt() string {
+	return getStringFlag("key-salt")
+}
+
+// NormalizeRules returns the configured comma-separated URL normalization rules.
+func (o *Options) NormalizeRules() string {
+	return getStringFlag("normalize-rules")
+}
+
+// AllowedSchemes returns the configured comma-separated URL schemes allowed for shortening.
+func (o *Options) AllowedSchemes() string {
+	return getStringFlag("allowed-schemes")
+}
+
+// MaxURLLength returns the configured maximum length of the URLs to shorten.
+func (o *Options) MaxURLLength() int {
+	return getIntFlag("max-url-length")
+}
+
+// DenyPrivateHosts returns whether URLs pointing to private or loopback hosts are rejected.
+func (o *Options) DenyPrivateHosts() bool {
+	return getBoolFlag("deny-private-hosts")
+}
+
+// InterstitialExternal returns whether the preview page is shown instead of redirecting
+// to the destinations on external domains.
+func (o *Options) InterstitialExternal() bool {
+	return getBoolFlag("interstitial-external")
+}
+
+// LinkScope returns the configured ownership scope of the original URLs.
+func (o *Options) LinkScope() string {
+	return getStringFlag("link-scope")
+}
+
+// PurgeGrace returns the configured period the deleted URLs can be restored in before they are purged.
+func (o *Options) PurgeGrace() string {
+	return getStringFlag("purge-grace")
+}
+
+// RedirectCode returns the configured default HTTP status code of the redirects.
+func (o *Options) RedirectCode() int {
+	return getIntFlag("redirect-code")
+}
+
+// GeoIPDB returns the configured path to the GeoIP database file.
+func (o *Options) GeoIPDB() string {
+	return getStringFlag("geoip-db")
+}
+
 // EnableHTTPS returns whether HTTPS is enabled.
 func (o *Options) EnableHTTPS() bool {
 	return getBoolFlag("s")
@@ -210,6 +392,13 @@ func regBoolVar(p *bool, name string, value bool, usage string) {
 	}
 }
 
+// regIntVar registers an int flag with the specified name, default value, and usage string.
+func regIntVar(p *int, name string, value int, usage string) {
+	if flag.Lookup(name) == nil {
+		flag.IntVar(p, name, value, usage)
+	}
+}
+
 // getStringFlag retrieves the string value of the specified flag.
 func getStringFlag(name string) string {
 	return flag.Lookup(name).Value.(flag.Getter).Get().(string)
@@ -220,6 +409,11 @@ func getBoolFlag(name string) bool {
 	return flag.Lookup(name).Value.(flag.Getter).Get().(bool)
 }
 
+// getIntFlag retrieves the int value of the specified flag.
+func getIntFlag(name string) int {
+	return flag.Lookup(name).Value.(flag.Getter).Get().(int)
+}
+
 // GetAsString reads an environment variable or returns a default value.
 func GetAsString(key string, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
@@ -247,6 +441,7 @@ func (o *Options) LoadFromConfigFile(filePath string) error {
 	// Set values from config file
 	o.setIfNotEmpty(&o.flagRunAddr, config["server_address"])
 	o.setIfNotEmpty(&o.flagShortURLAdress, config["base_url"])
+	o.setIfNotEmpty(&o.flagDomains, config["domains"])
 	o.setIfNotEmpty(&o.flagLogLevel, config["log_level"])
 	o.setIfNotEmpty(&o.flagFileStoragePath, config["file_storage_path"])
 	o.setIfNotEmpty(&o.flagDataBaseDSN, config["database_dsn"])
@@ -254,12 +449,33 @@ func (o *Options) LoadFromConfigFile(filePath string) error {
 	o.setIfNotEmpty(&o.flagHTTPSCertFile, config["https_cert_file"])
 	o.setIfNotEmpty(&o.flagHTTPSKeyFile, config["https_key_file"])
 	o.setIfNotEmpty(&o.flagTrustedSubnet, config["trusted_subnet"])
+	o.setIfNotEmpty(&o.flagKeyStrategy, config["key_strategy"])
+	o.setIfNotEmpty(&o.flagKeyAlphabet, config["key_alphabet"])
+	o.setIfNotEmpty(&o.flagKeySalt, config["key_salt"])
+	o.setIntIfZero(&o.flagKeyLength, config["key_length"])
+	o.setIfNotEmpty(&o.flagNormalizeRules, config["normalize_rules"])
+	o.setIfNotEmpty(&o.flagAllowedSchemes, config["allowed_schemes"])
+	o.setIntIfZero(&o.flagMaxURLLength, config["max_url_length"])
+	o.setIfNotEmpty(&o.flagLinkScope, config["link_scope"])
+	o.setIfNotEmpty(&o.flagPurgeGrace, config["purge_grace"])
+	o.setIntIfZero(&o.flagRedirectCode, config["redirect_code"])
+	o.setIfNotEmpty(&o.flagGeoIPDB, config["geoip_db"])
 
 	// Handle boolean value for enable_https
 	if enableHTTPS, ok := config["enable_https"].(bool); ok {
 		o.flagEnableHTTPS = enableHTTPS
 	}
 
+	// Handle boolean value for deny_private_hosts
+	if denyPrivate, ok := config["deny_private_hosts"].(bool); ok {
+		o.flagDenyPrivate = denyPrivate
+	}
+
+	// Handle boolean value for interstitial_external
+	if interstitial, ok := config["interstitial_external"].(bool); ok {
+		o.flagInterstitial = interstitial
+	}
+
 	return nil
 }
 
@@ -273,3 +489,14 @@ func (o *Options) setIfNotEmpty(target *string, value interface{}) {
 		*target = strValue
 	}
 }
+
+// setIntIfZero sets the target variable if it is zero and the value is a number.
+func (o *Options) setIntIfZero(target *int, value interface{}) {
+	if *target != 0 {
+		// If the value is already set, return from the function
+		return
+	}
+	if numValue, ok := value.(float64); ok {
+		*target = int(numValue)
+	}
+}
@@ -33,6 +33,13 @@ func TestShorten(t *testing.T) {
 		{name: "test func LogLevel", testfunc: option.LogLevel, result: "info"},
 		{name: "test func FileStoragePath", testfunc: option.FileStoragePath, result: "test777"},
 		{name: "test func JWTSigningKey", testfunc: option.JWTSigningKey, result: "test_key"},
+		{name: "test func KeyStrategy", testfunc: option.KeyStrategy, result: ""},
+		{name: "test func KeyAlphabet", testfunc: option.KeyAlphabet, result: ""},
+		{name: "test func NormalizeRules", testfunc: option.NormalizeRules, result: ""},
+		{name: "test func AllowedSchemes", testfunc: option.AllowedSchemes, result: ""},
+		{name: "test func LinkScope", testfunc: option.LinkScope, result: ""},
+		{name: "test func PurgeGrace", testfunc: option.PurgeGrace, result: ""},
+		{name: "test func GeoIPDB", testfunc: option.GeoIPDB, result: ""},
 	}
 
 	for _, tc := range testCases {
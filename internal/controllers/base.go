@@ -7,18 +7,23 @@ package controllers
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"expvar"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/http/pprof"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi"
 	"github.com/google/uuid"
 	authz "github.com/wurt83ow/tinyurl/internal/authorization"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/domains"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
 	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
 	"github.com/wurt83ow/tinyurl/internal/storage"
 	"go.uber.org/zap"
@@ -32,10 +37,14 @@ type Storage interface {
 	// InsertURL inserts a URL entry into the storage.
 	InsertURL(k string, v models.DataURL) (models.DataURL, error)
 
+	// InsertAlias reserves a custom alias for a URL entry in the storage.
+	InsertAlias(k string, v models.DataURL) (models.DataURL, error)
+
 	// InsertUser inserts a user entry into the storage.
 	InsertUser(k string, v models.DataUser) (models.DataUser, error)
 
-	// InsertBatch inserts a batch of URL entries into the storage.
+	// InsertBatch inserts a batch of URL entries into the storage, the entries whose
+	// original URLs are already shortened are replaced by the stored ones.
 	InsertBatch(storageURL storage.StorageURL) error
 
 	// GetURL retrieves a URL entry from the storage.
@@ -44,8 +53,31 @@ type Storage interface {
 	// GetUser retrieves a user entry from the storage.
 	GetUser(k string) (models.DataUser, error)
 
-	// GetUserURLs retrieves URLs associated with a user from the storage.
-	GetUserURLs(userID string) []models.DataURLite
+	// GetUserByID retrieves a user entry by its user id from the storage.
+	GetUserByID(id string) (models.DataUser, error)
+
+	// UpdateURL changes the original URL of a user's URL entry in the storage.
+	UpdateURL(k string, userID string, originalURL string) (models.DataURL, error)
+
+	// UpdateTags replaces the tags of a user's URL entry in the storage.
+	UpdateTags(k string, userID string, tags []string) (models.DataURL, error)
+
+	// UseClick counts a redirect of a click-limited URL entry in the storage.
+	UseClick(k string) (models.DataURL, error)
+
+	// AddVariantClick counts a redirect to a variant of a split URL entry in the storage.
+	AddVariantClick(k string, variant int) error
+
+	// SearchUserURLs retrieves the URLs associated with a user matching the search query from the storage.
+	SearchUserURLs(userID string, query string, limit int) ([]models.DataURLite, error)
+
+	// GetUserURLs retrieves a page of the URLs associated with a user, selected and ordered
+	// by the query, and the token of the next page from the storage.
+	GetUserURLs(userID string, q storage.URLQuery) ([]models.DataURLite, string, error)
+
+	// GetURLStats retrieves the statistics of the clicks of a user's URL entry over the range of the query.
+	// It returns storage.ErrGone if the entry has been deleted.
+	GetURLStats(k string, userID string, q storage.StatsQuery) (models.URLStats, error)
 
 	// SaveURL saves a URL entry in the storage.
 	SaveURL(k string, v models.DataURL) (models.DataURL, error)
@@ -77,7 +109,17 @@ type Options interface {
 	// ShortURLAdress returns the short URL address.
 	ShortURLAdress() string
 
+	// Domains returns the comma-separated base URLs of the additional domains of the short URLs.
+	Domains() string
+
 	TrustedSubnet() string
+
+	// RedirectCode returns the default HTTP status code of the redirects, zero means 307.
+	RedirectCode() int
+
+	// InterstitialExternal returns whether the preview page is shown instead of redirecting
+	// to the destinations on external domains.
+	InterstitialExternal() bool
 }
 
 // Log represents an interface for logging functionality.
@@ -90,6 +132,39 @@ type Log interface {
 type Worker interface {
 	// Add adds a task to the worker.
 	Add(models.DeleteURL)
+	// Restore adds a restoration task to the worker.
+	Restore(models.DeleteURL)
+}
+
+// KeyGenerator represents an interface for short key generation.
+type KeyGenerator interface {
+	// Generate returns a short key for the original URL.
+	Generate(url string) (string, error)
+}
+
+// Normalizer represents an interface for the canonicalization of original URLs.
+type Normalizer interface {
+	// Normalize returns the canonical form of the URL.
+	Normalize(url string) string
+}
+
+// Validator represents an interface for the validation of original URLs.
+type Validator interface {
+	// Validate returns an error if the URL violates the URL policy.
+	Validate(url string) error
+}
+
+// GeoLocator represents an interface for the location of the client IP addresses.
+type GeoLocator interface {
+	// Country returns the ISO 3166-1 alpha-2 code of the country of the IP address,
+	// or an empty string if it is unknown.
+	Country(ip string) string
+}
+
+// ClickRecorder represents an interface for the recording of the click events of the redirects.
+type ClickRecorder interface {
+	// Record records the click event without blocking, the event may be dropped.
+	Record(models.ClickEvent)
 }
 
 // Authz represents an interface for user authorization functionality.
@@ -112,26 +187,48 @@ type Authz interface {
 // BaseController represents a basic controller for handling user requests.
 // It includes handler methods for various operations.
 type BaseController struct {
-	storage Storage
-	options Options
-	log     Log
-	worker  Worker
-	authz   Authz
+	storage   Storage
+	options   Options
+	log       Log
+	worker    Worker
+	authz     Authz
+	shortener *shortener
+	limiter   *linkpass.Limiter
+	locator   GeoLocator
+	domains   *domains.Set
+	clicks    ClickRecorder
 }
 
+// NewBaseController creates a new BaseController instance. The limiter of the failed
+// password attempts is shared with the gRPC server, a nil one is replaced by a limiter
+// of the default attempts and window.
+//
 // Example usage:
 //
-//	controller := NewBaseController(memoryStorage, option, nLogger, worker, authz)
+//	controller := NewBaseController(memoryStorage, option, nLogger, worker, authz, keygen, normalizer, validator, limiter, locator, clicks)
 //	r.Mount("/", controller.Route())
 //	flagRunAddr := option.RunAddr()
 //	http.ListenAndServe(flagRunAddr, r)
-func NewBaseController(storage Storage, options Options, log Log, worker Worker, authz Authz) *BaseController {
+func NewBaseController(storage Storage, options Options, log Log, worker Worker, authz Authz,
+	keygen KeyGenerator, normalizer Normalizer, validator Validator, limiter *linkpass.Limiter,
+	locator GeoLocator, clicks ClickRecorder) *BaseController {
+	if limiter == nil {
+		limiter = linkpass.NewLimiter(linkpass.DefaultMaxAttempts, linkpass.DefaultWindow)
+	}
+
+	set := newDomains(options, log)
 	instance := &BaseController{
 		storage: storage,
 		options: options,
 		log:     log,
 		worker:  worker,
 		authz:   authz,
+		shortener: &shortener{storage: storage, keygen: keygen, normalizer: normalizer, validator: validator,
+			domains: set},
+		limiter: limiter,
+		locator: locator,
+		domains: set,
+		clicks:  clicks,
 		// delChan: make(chan models.DeleteURL, 1024), // set the channel buffer to 1024 messages
 	}
 
@@ -140,6 +237,23 @@ func NewBaseController(storage Storage, options Options, log Log, worker Worker,
 	return instance
 }
 
+// newDomains returns the domains of the short URLs configured by the options. The malformed
+// base URLs are logged and skipped, the server refuses to start with them anyway.
+func newDomains(options Options, log Log) *domains.Set {
+	set, err := domains.New(options.ShortURLAdress(), options.Domains())
+	if err != nil {
+		log.Info("invalid domains: ", zap.Error(err))
+	}
+
+	return set
+}
+
+// storageKey returns the storage key of the short key requested on the domain served
+// for the Host header of the request.
+func (h *BaseController) storageKey(r *http.Request, key string) string {
+	return storage.DomainKey(h.domains.Serving(r.Host), key)
+}
+
 // Route returns a chi.Mux router with registered handlers for BaseController routes.
 // It creates a new chi router, registers the routes with the corresponding handler
 // methods, and returns the configured router.
@@ -155,6 +269,8 @@ func (h *BaseController) Route() *chi.Mux {
 	r.Post("/register", h.Register)
 	r.Post("/login", h.Login)
 	r.Get("/{name}", h.getFullURL)
+	r.Post("/{name}", h.unlockURL)
+	r.Get("/{name}"+qrSuffix, h.getQRCode)
 	r.Get("/api/internal/stats", h.getStatsHandler)
 	r.Get("/ping", h.getPing)
 
@@ -180,7 +296,11 @@ func (h *BaseController) Route() *chi.Mux {
 		r.Post("/api/shorten", h.shortenJSON)
 		r.Post("/api/shorten/batch", h.shortenBatch)
 		r.Get("/api/user/urls", h.getUserURLs)
+		r.Get("/api/user/urls/search", h.searchUserURLs)
 		r.Delete("/api/user/urls", h.deleteUserURLs)
+		r.Post("/api/user/urls/restore", h.restoreUserURLs)
+		r.Patch("/api/user/urls/{key}", h.updateUserURL)
+		r.Get("/api/user/urls/{key}/stats", h.getURLStats)
 	})
 
 	return r
@@ -257,10 +377,21 @@ func getClientIP(r *http.Request) string {
 	return remoteAddr
 }
 
+// clientCountry returns the country of the client IP address, or an empty string
+// if no GeoIP database is configured.
+func clientCountry(locator GeoLocator, ip string) string {
+	if locator == nil {
+		return ""
+	}
+
+	return locator.Country(ip)
+}
+
 // deleteUserURLs is a handler method for deleting user URLs.
 // It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
 // The function decodes the request JSON body containing URL IDs to be deleted, validates the user ID from the request context,
 // adds a task to the worker for asynchronous deletion, and responds with the appropriate HTTP status code.
+// The URLs on the additional domains are identified by their domain and key, e.g. "go.example.com/abc".
 //
 // Parameters:
 //   - h: A pointer to the BaseController instance.
@@ -298,6 +429,128 @@ func (h *BaseController) deleteUserURLs(w http.ResponseWriter, r *http.Request)
 	w.WriteHeader(http.StatusAccepted)
 }
 
+// restoreUserURLs is a handler method for restoring deleted user URLs.
+// It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
+// The function decodes the request JSON body containing URL IDs to be restored, validates the user ID from the request context,
+// adds a task to the worker for asynchronous restoration, and responds with the appropriate HTTP status code.
+// Like in deleteUserURLs, the URLs on the additional domains are identified by their domain and key.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) restoreUserURLs(w http.ResponseWriter, r *http.Request) {
+	// Initialize an empty slice to store URL IDs
+	ids := make([]string, 0)
+
+	// Decode the request JSON body into the ids slice
+	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
+		// Log the error and respond with a Bad Request status code
+		h.log.Info("cannot decode request JSON body: ", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// Retrieve the user ID from the request context
+	userID, ok := r.Context().Value(keyUserID).(string)
+	if !ok {
+		// Respond with an Unauthorized status code
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	// Add a task to the worker for asynchronous restoration
+	h.worker.Restore(models.DeleteURL{UserID: userID, ShortURLs: ids})
+
+	// Respond with an Accepted status code
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// updateUserURL is a handler method for changing the original URL or the tags of a user's short link.
+// It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
+// The function decodes the new original URL and tags from the request JSON body, checks that the authenticated
+// user owns the link, binds the new URL to the existing key, replaces the tags if they are set and responds
+// with the updated link. The domain query parameter selects the link of the key on an additional domain.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) updateUserURL(w http.ResponseWriter, r *http.Request) {
+	// Retrieve the user ID from the request context
+	userID, ok := r.Context().Value(keyUserID).(string)
+	if !ok {
+		// Respond with an Unauthorized status code if the user ID is not present
+		w.WriteHeader(http.StatusUnauthorized) // Code 401
+		return
+	}
+
+	// Decode the request JSON body into the UpdateRequest model
+	var req models.UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		// Log the error and respond with a Bad Request status code
+		h.log.Info("cannot decode request JSON body: ", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// The domain query parameter selects the link of the key on an additional domain
+	domain, err := h.domains.Resolve(r.URL.Query().Get("domain"))
+	if err != nil {
+		h.invalidQuery(w, &queryError{field: "domain", message: err.Error()})
+		return
+	}
+
+	// Change the original URL of the link, unless only the tags are changed
+	key := storage.DomainKey(domain, chi.URLParam(r, "key"))
+	var m models.DataURL
+	if req.URL != "" || req.Tags == nil {
+		m, err = h.shortener.updateURL(key, userID, req.URL)
+	}
+
+	// Replace the tags of the link
+	if err == nil && req.Tags != nil {
+		m, err = h.shortener.updateTags(key, userID, *req.Tags)
+	}
+
+	// Respond with a structured Bad Request if the URL or the tags are invalid
+	if h.invalidURL(w, "url", err) || h.invalidOptions(w, err) {
+		return
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, storage.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound) // Code 404
+		return
+	case errors.Is(err, storage.ErrNotOwner):
+		w.WriteHeader(http.StatusForbidden) // Code 403
+		return
+	case errors.Is(err, storage.ErrConflict):
+		// Respond with the link already shortening the URL
+		h.log.Info("original URL is already shortened: ", zap.String("short_url", m.ShortURL))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict) // Code 409
+		if err := json.NewEncoder(w).Encode(models.Response{Result: m.ShortURL}); err != nil {
+			h.log.Info("error encoding response: ", zap.Error(err))
+		}
+		return
+	default:
+		h.log.Info("cannot update URL: ", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError) // Code 500
+		return
+	}
+
+	// Respond with the updated link
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // Code 200
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.DataURLite{ShortURL: m.ShortURL, OriginalURL: m.OriginalURL,
+		LinkOptions: models.LinkOptions{Tags: m.Tags, Domain: m.Domain}}); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+}
+
 // Register is a method of the *BaseController structure.
 //
 // The method handles user registration by accepting the email and password
@@ -459,41 +712,39 @@ func (h *BaseController) shortenBatch(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the short URL address from the options
-	shortURLAdress := h.options.ShortURLAdress()
-
-	// Initialize a storageURL map to store data for batch insertion
-	dataURL := make(storage.StorageURL)
-
-	// Initialize a response slice to store the shortened URLs for the client response
-	resp := []models.DataURLite{}
-
 	// Retrieve the user ID from the request context
 	userID, _ := r.Context().Value(keyUserID).(string)
 
-	// Loop through each URL in the batch
-	for i := range batch {
-		s := batch[i]
-
-		// Shorten the original URL
-		key, shurl := shorturl.Shorten(s.OriginalURL, shortURLAdress)
-
-		// Save the full URL to storage with the key received earlier
-		data := models.DataURL{UUID: s.UUID, ShortURL: shurl, OriginalURL: s.OriginalURL, UserID: userID}
-		dataURL[key] = data
-
-		// Append the shortened URL to the response slice
-		resp = append(resp, models.DataURLite{UUID: s.UUID, ShortURL: shurl})
+	// Collect the full URLs of the batch with their link options
+	now := time.Now()
+	data := make([]models.DataURL, 0, len(batch))
+	for i, s := range batch {
+		d := models.DataURL{UUID: s.UUID, OriginalURL: s.OriginalURL, UserID: userID}
+		if err := applyLinkOptions(&d, s.LinkOptions, now); err != nil {
+			h.invalidOptions(w, &batchItemError{index: i, err: err})
+			return
+		}
+		data = append(data, d)
 	}
 
-	// Insert the batch of URLs into the storage
-	err := h.storage.InsertBatch(dataURL)
+	// Shorten the batch of URLs and insert it into the storage
+	data, err := h.shortener.insertBatch(data)
+	if h.invalidURL(w, "original_url", err) || h.invalidOptions(w, err) {
+		return
+	}
 	if err != nil {
 		// Respond with a Bad Request status code if there is an error
+		h.log.Info("cannot insert batch: ", zap.Error(err))
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
+	// Initialize a response slice to store the shortened URLs for the client response
+	resp := make([]models.DataURLite, 0, len(data))
+	for _, d := range data {
+		resp = append(resp, models.DataURLite{UUID: d.UUID, ShortURL: d.ShortURL})
+	}
+
 	// Set the response headers
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
@@ -545,23 +796,33 @@ func (h *BaseController) shortenJSON(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the short URL address from the options
-	shortURLAdress := h.options.ShortURLAdress()
-
-	// Shorten the original URL
-	key, shurl := shorturl.Shorten(string(req.URL), shortURLAdress)
-
 	// Retrieve the user ID from the request context
 	userID, _ := r.Context().Value(keyUserID).(string)
 
-	// Save the full URL to storage with the key received earlier
-	m, err := h.storage.InsertURL(key, models.DataURL{ShortURL: shurl, OriginalURL: string(req.URL), UserID: userID})
+	// Apply the expiration time and the click limit of the link
+	data := models.DataURL{OriginalURL: string(req.URL), UserID: userID}
+	if h.invalidOptions(w, applyLinkOptions(&data, req.LinkOptions, time.Now())) {
+		return
+	}
+
+	// Shorten the original URL or reserve the custom alias and save it to storage
+	m, err := h.shortener.insertURL(req.Alias, data)
+
+	// Respond with a structured Bad Request if the URL or the URL of a rule is invalid
+	if h.invalidURL(w, "url", err) || h.invalidOptions(w, err) {
+		return
+	}
 
 	// Check for conflicts or other errors during insertion
 	conflict := false
 	if err != nil {
 		if err == storage.ErrConflict {
 			conflict = true
+		} else if err == shorturl.ErrInvalidAlias {
+			// Respond with a Bad Request status code for an invalid alias
+			h.log.Info("invalid alias: ", zap.String("alias", req.Alias))
+			w.WriteHeader(http.StatusBadRequest)
+			return
 		} else {
 			// Respond with a Bad Request status code for other errors
 			w.WriteHeader(http.StatusBadRequest)
@@ -615,17 +876,16 @@ func (h *BaseController) shortenURL(w http.ResponseWriter, r *http.Request) {
 	// Set the correct content type header
 	w.Header().Set("Content-Type", "text/plain")
 
-	// Get the short URL address from the options
-	shortURLAdress := h.options.ShortURLAdress()
-
-	// Shorten the URL
-	key, shurl := shorturl.Shorten(string(body), shortURLAdress)
-
 	// Retrieve the user ID from the request context
 	userID, _ := r.Context().Value(keyUserID).(string)
 
-	// Save the full URL to storage with the key received earlier
-	m, err := h.storage.InsertURL(key, models.DataURL{ShortURL: shurl, OriginalURL: string(body), UserID: userID})
+	// Shorten the URL and save it to storage
+	m, err := h.shortener.insertURL("", models.DataURL{OriginalURL: string(body), UserID: userID})
+
+	// Respond with a structured Bad Request if the URL is invalid
+	if h.invalidURL(w, "", err) {
+		return
+	}
 
 	// Check for conflicts or other errors during insertion
 	conflict := false
@@ -658,9 +918,221 @@ func (h *BaseController) shortenURL(w http.ResponseWriter, r *http.Request) {
 	h.log.Info("sending HTTP 201 response")
 }
 
+// invalidURL responds with a structured Bad Request if err is a URL validation error.
+// The field names the request field holding the URL, batch items are prefixed with
+// their index. It reports whether the response has been written.
+func (h *BaseController) invalidURL(w http.ResponseWriter, field string, err error) bool {
+	var ve *shorturl.ValidationError
+	if !errors.As(err, &ve) {
+		return false
+	}
+
+	var be *batchItemError
+	if errors.As(err, &be) {
+		field = fmt.Sprintf("[%d].%s", be.index, field)
+	}
+
+	h.log.Info("invalid URL: ", zap.Error(err))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.ErrorResponse{
+		Error:   "invalid_url",
+		Reason:  ve.Reason,
+		Message: ve.Message,
+		Field:   field,
+	}); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+
+	return true
+}
+
+// invalidOptions responds with a structured Bad Request if err is an invalid link option error.
+// Batch items are prefixed with their index. It reports whether the response has been written.
+func (h *BaseController) invalidOptions(w http.ResponseWriter, err error) bool {
+	var oe *linkOptionError
+	if !errors.As(err, &oe) {
+		return false
+	}
+
+	field := oe.field
+	var be *batchItemError
+	if errors.As(err, &be) {
+		field = fmt.Sprintf("[%d].%s", be.index, field)
+	}
+
+	h.log.Info("invalid link options: ", zap.Error(err))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.ErrorResponse{
+		Error:   "invalid_options",
+		Message: oe.message,
+		Field:   field,
+	}); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+
+	return true
+}
+
+// searchUserURLs is a handler method for searching the URLs associated with the authenticated user.
+// It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
+// The function finds the URLs whose key, original URL, host, tags or note contain all of the words
+// of the q query parameter, regardless of case, and responds with up to limit of them, newest first.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) searchUserURLs(w http.ResponseWriter, r *http.Request) {
+	// Retrieve the user ID from the request context
+	userID, ok := r.Context().Value(keyUserID).(string)
+	if !ok {
+		// Respond with an Unauthorized status code if the user ID is not present
+		w.WriteHeader(http.StatusUnauthorized) // Code 401
+		return
+	}
+
+	// Parse the search query and the number of the URLs
+	query := r.URL.Query()
+	q := query.Get("q")
+	if strings.TrimSpace(q) == "" {
+		h.invalidQuery(w, &queryError{field: "q", message: "q is required"})
+		return
+	}
+	var limit int
+	if query.Get("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil || limit < 1 || limit > storage.MaxPageSize {
+			h.invalidQuery(w, &queryError{field: "limit",
+				message: fmt.Sprintf("limit must be between 1 and %d", storage.MaxPageSize)})
+			return
+		}
+	}
+
+	// Search the URLs associated with the user in storage
+	data, err := h.storage.SearchUserURLs(userID, q, limit)
+	if err != nil {
+		h.log.Info("cannot search user URLs: ", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError) // Code 500
+		return
+	}
+
+	// Respond with a No Content status code if no URLs are found
+	if len(data) == 0 {
+		w.WriteHeader(http.StatusNoContent) // Code 204
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // Code 200
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+}
+
+// getURLStats is a handler method for retrieving the click statistics of a user's short link.
+// It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
+// The function checks that the authenticated user owns the link and responds with the total and unique
+// clicks, the clicks of every hour or day of the range and the top referrers, countries and user agents.
+// The from and to query parameters (RFC 3339) give the range, the interval parameter (hour or day) the
+// buckets and the top parameter the number of the top values; the domain parameter selects the link
+// of the key on an additional domain. A deleted link responds with Gone.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) getURLStats(w http.ResponseWriter, r *http.Request) {
+	// Retrieve the user ID from the request context
+	userID, ok := r.Context().Value(keyUserID).(string)
+	if !ok {
+		// Respond with an Unauthorized status code if the user ID is not present
+		w.WriteHeader(http.StatusUnauthorized) // Code 401
+		return
+	}
+
+	query := r.URL.Query()
+	q, err := statsQueryParams{
+		from:     query.Get("from"),
+		to:       query.Get("to"),
+		interval: query.Get("interval"),
+		top:      query.Get("top"),
+	}.query(time.Now())
+	if h.invalidQuery(w, err) {
+		return
+	}
+
+	// The domain query parameter selects the link of the key on an additional domain
+	domain, err := h.domains.Resolve(query.Get("domain"))
+	if err != nil {
+		h.invalidQuery(w, &queryError{field: "domain", message: err.Error()})
+		return
+	}
+
+	stats, err := h.storage.GetURLStats(storage.DomainKey(domain, chi.URLParam(r, "key")), userID, q)
+	switch {
+	case err == nil:
+	case errors.Is(err, storage.ErrNotFound):
+		w.WriteHeader(http.StatusNotFound) // Code 404
+		return
+	case errors.Is(err, storage.ErrNotOwner):
+		w.WriteHeader(http.StatusForbidden) // Code 403
+		return
+	case errors.Is(err, storage.ErrGone):
+		w.WriteHeader(http.StatusGone) // Code 410
+		return
+	default:
+		h.log.Info("cannot get URL stats: ", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError) // Code 500
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK) // Code 200
+	if err := json.NewEncoder(w).Encode(stats); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+}
+
+// invalidQuery responds with a structured Bad Request if err is an invalid user URLs query error.
+// It reports whether the response has been written.
+func (h *BaseController) invalidQuery(w http.ResponseWriter, err error) bool {
+	var qe *queryError
+	if !errors.As(err, &qe) {
+		return false
+	}
+
+	h.log.Info("invalid user URLs query: ", zap.Error(err))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(models.ErrorResponse{
+		Error:   "invalid_query",
+		Message: qe.message,
+		Field:   qe.field,
+	}); err != nil {
+		h.log.Info("error encoding response: ", zap.Error(err))
+	}
+
+	return true
+}
+
 // getFullURL is a handler method for retrieving the original URL for a given shortened URL key.
 // It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
 // The function processes a custom GET request, retrieves the original URL from storage, and responds with the appropriate HTTP status code.
+// For a password-protected URL it serves the password form, which is handled by unlockURL.
+// With the + suffix or the preview query parameter, and for the external destinations if the
+// server is configured so, it serves the preview page of the URL instead of redirecting.
 //
 // Parameters:
 //   - h: A pointer to the BaseController instance.
@@ -678,8 +1150,11 @@ func (h *BaseController) getFullURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get the full URL from storage
-	data, err := h.storage.GetURL(key)
+	// The suffix or the query parameter asks for the preview page of the URL
+	key, preview := previewKey(r, key)
+
+	// Get the full URL from storage, the key is looked up on the domain of the request
+	data, err := h.storage.GetURL(h.storageKey(r, key))
 
 	// Respond with a Bad Request status code if the URL is not found or there is an error
 	if err != nil || len(data.OriginalURL) == 0 {
@@ -687,22 +1162,38 @@ func (h *BaseController) getFullURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Respond with a Gone status code if the URL has been marked as deleted
-	if data.DeletedFlag {
+	// Respond with a Gone status code if the URL has been marked as deleted or has expired
+	if data.DeletedFlag || data.Expired(time.Now()) {
 		w.WriteHeader(http.StatusGone) // Code 410
 		return
 	}
 
-	// Set the Location header for a temporary redirect
-	w.Header().Set("Location", data.OriginalURL)
-	w.WriteHeader(http.StatusTemporaryRedirect) // Code 307
-	h.log.Info("temporary redirect status 307")
+	// Serve the password form instead of redirecting if the URL is protected,
+	// the destination of a protected URL is not previewed
+	if data.Protected() {
+		h.passwordForm(w, r, key, http.StatusOK, "")
+		return
+	}
+
+	// Serve the preview page instead of redirecting if asked or required for the destination
+	if preview || h.interstitial(data) {
+		h.previewPage(w, r, key, data)
+		return
+	}
+
+	// Redirect to the original URL with the code of the link or the server default
+	h.redirect(w, r, key, data, redirectCode(data, h.options.RedirectCode()))
 }
 
 // getUserURLs is a handler method for retrieving URLs associated with the authenticated user.
 // It takes a pointer to the BaseController instance, an http.ResponseWriter, and an http.Request as parameters.
 // The function retrieves the user ID from the request context, retrieves associated URLs from storage,
 // and responds with the appropriate HTTP status code and serialized response.
+// Repeated tag query parameters, e.g. ?tag=marketing&tag=q3, keep the URLs tagged with all of them;
+// the search, deleted, created_from and created_to parameters filter the URLs further. The URLs are
+// sorted by the sort (created or key) and order (asc or desc) parameters and split into pages of
+// page_size URLs, the token of the next page is sent in the X-Next-Page-Token header and passed
+// back as the page_token parameter.
 //
 // Parameters:
 //   - h: A pointer to the BaseController instance.
@@ -717,8 +1208,34 @@ func (h *BaseController) getUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Retrieve URLs associated with the user from storage
-	data := h.storage.GetUserURLs(userID)
+	// Parse the filters, the sort order and the page of the query parameters
+	query := r.URL.Query()
+	q, err := urlQueryParams{
+		tags:        query["tag"],
+		search:      query.Get("search"),
+		deleted:     query.Get("deleted"),
+		createdFrom: query.Get("created_from"),
+		createdTo:   query.Get("created_to"),
+		sort:        query.Get("sort"),
+		order:       query.Get("order"),
+		pageSize:    query.Get("page_size"),
+		pageToken:   query.Get("page_token"),
+	}.query()
+	if h.invalidQuery(w, err) {
+		return
+	}
+
+	// Retrieve a page of the URLs associated with the user from storage
+	data, next, err := h.storage.GetUserURLs(userID, q)
+	if errors.Is(err, storage.ErrInvalidPageToken) {
+		h.invalidQuery(w, &queryError{field: "page_token", message: err.Error()})
+		return
+	}
+	if err != nil {
+		h.log.Info("cannot get user URLs: ", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError) // Code 500
+		return
+	}
 
 	// Respond with a No Content status code if no URLs are found for the user
 	if len(data) == 0 {
@@ -726,8 +1243,11 @@ func (h *BaseController) getUserURLs(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Set the Content-Type header
+	// Set the Content-Type header and the token of the next page
 	w.Header().Set("Content-Type", "application/json")
+	if next != "" {
+		w.Header().Set(nextPageHeader, next)
+	}
 
 	// Respond with an OK status code and serialize the response
 	w.WriteHeader(http.StatusOK) // Code 200
@@ -1,21 +1,36 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"image/png"
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"path"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	authz "github.com/wurt83ow/tinyurl/internal/authorization"
 	"github.com/wurt83ow/tinyurl/internal/config"
+	pb "github.com/wurt83ow/tinyurl/internal/controllers/proto"
 	"github.com/wurt83ow/tinyurl/internal/logger"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
 	"github.com/wurt83ow/tinyurl/internal/storage"
 	"github.com/wurt83ow/tinyurl/internal/worker"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 var controller *BaseController
@@ -31,6 +46,19 @@ func TestMain(m *testing.M) {
 	os.Exit(exitCode)
 }
 
+// createdAs returns a matcher of the data the shortener saves for want: the same data
+// stamped with the creation time.
+func createdAs(want models.DataURL) func(models.DataURL) bool {
+	return func(got models.DataURL) bool {
+		if got.CreatedAt == nil {
+			return false
+		}
+		got.CreatedAt = nil
+
+		return reflect.DeepEqual(got, want)
+	}
+}
+
 func setup() {
 	option := config.NewOptions()
 	option.ParseFlags()
@@ -39,75 +67,1537 @@ func setup() {
 	if err != nil {
 		log.Fatalf("Unable to setup logger: %s\n", err)
 	}
-	keeperMock := new(MockKeeper)
-	// Set up a mock for the Load method
-	keeperMock.On("Load").Return(storage.StorageURL{}, nil)
+	keeperMock := new(MockKeeper)
+	// Set up a mock for the Load method
+	keeperMock.On("Load").Return(storage.StorageURL{}, nil)
+
+	// Set up a mock for the LoadUsers method
+	keeperMock.On("LoadUsers").Return(storage.StorageUser{}, nil)
+
+	// Set up a wait for the GetUser method to return an error indicating
+	//that the user already exists
+	keeperMock.On("GetUser", "test@example.com").Return(storage.ErrConflict)
+
+	data := storage.StorageURL{
+		"1": {UUID: "", ShortURL: "", OriginalURL: "https://practicum.yandex.ru/"},
+		"2": {UUID: "", ShortURL: "", OriginalURL: "https://www.google.ru/"},		
+	}
+
+	keeperMock.On("SaveBatch", mock.MatchedBy(func(got storage.StorageURL) bool {
+		if len(got) != len(data) {
+			return false
+		}
+		for k, v := range got {
+			if !createdAs(data[k])(v) {
+				return false
+			}
+		}
+		return true
+	})).Return(nil)
+	// Set up expectations for methods that will be called inside the Register function
+	keeperMock.On("GetUser", "test@example.com").Return(nil) // Example: GetUser method returns an error that the user does not exist
+	keeperMock.On("InsertUser", "test@example.com", mock.AnythingOfType("models.DataUser")).Return(nil)
+
+	keeperMock.On("Ping").Return(true)
+
+	// Generate a unique key for the test
+	key := "nOykhckC3Od"
+
+	// Generate data for the Save method
+	dataURL := models.DataURL{
+		UUID:        "",
+		OriginalURL: "https://practicum.yandex.ru/",
+		ShortURL:    "http://localhost:8080/" + key,
+	}
+
+	// Set up a mock for the Save method
+	keeperMock.On("Save", key, mock.MatchedBy(createdAs(dataURL))).Return(dataURL, nil)
+
+	// The plain text endpoint shortens another URL, a URL shortened again is a conflict
+	textURL := models.DataURL{
+		OriginalURL: "https://practicum.yandex.ru/learn/",
+		ShortURL:    "http://localhost:8080/7jSdiujrTAg",
+	}
+	keeperMock.On("Save", "7jSdiujrTAg", mock.MatchedBy(createdAs(textURL))).Return(textURL, nil)
+
+	// Set up a mock for the SaveAlias method
+	aliasURL := models.DataURL{
+		OriginalURL: "https://practicum.yandex.ru/sale",
+		ShortURL:    "http://localhost:8080/spring-sale",
+	}
+	keeperMock.On("SaveAlias", "spring-sale", mock.MatchedBy(createdAs(aliasURL))).Return(aliasURL, nil)
+
+	memoryStorage := storage.NewMemoryStorage(keeperMock, nLogger, storage.ScopeGlobal)
+
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	keygen, err := shorturl.NewKeyGenerator(option.KeyStrategy(), option.KeyLength(),
+		option.KeyAlphabet(), option.KeySalt(), nil)
+	if err != nil {
+		log.Fatalf("Unable to setup key generator: %s\n", err)
+	}
+
+	normalizer, err := shorturl.NewNormalizer(option.NormalizeRules())
+	if err != nil {
+		log.Fatalf("Unable to setup URL normalizer: %s\n", err)
+	}
+
+	validator := shorturl.NewValidator(option.AllowedSchemes(), option.MaxURLLength(), option.DenyPrivateHosts())
+
+	controller = NewBaseController(memoryStorage, option, nLogger, worker, authz, keygen, normalizer, validator, nil, nil, nil)
+	if controller == nil {
+		log.Fatalf("Unable to initialize baseController\n")
+	}
+}
+
+func TestShortenJSON(t *testing.T) {
+
+	// describe the body being transmitted
+	userReq := strings.NewReader(`{         
+        "url": "https://practicum.yandex.ru/"
+    }`)
+
+	// describe the expected response body for a successful request
+	successBody := `{"result":"http://localhost:8080/nOykhckC3Od"}`
+
+	testPostReq(t, userReq, successBody, "shortenJSON")
+}
+
+func TestShortenJSONAlias(t *testing.T) {
+	testCases := []struct {
+		name         string
+		body         string
+		expectedBody string
+		expectedCode int
+	}{
+		{
+			name:         "free alias",
+			body:         `{"url": "https://practicum.yandex.ru/sale", "alias": "spring-sale"}`,
+			expectedBody: `{"result":"http://localhost:8080/spring-sale"}`,
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:         "taken alias",
+			body:         `{"url": "https://practicum.yandex.ru/other", "alias": "spring-sale"}`,
+			expectedBody: `{"result":"http://localhost:8080/spring-sale"}`,
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "invalid alias",
+			body:         `{"url": "https://practicum.yandex.ru/sale", "alias": "spring/sale"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			controller.shortenJSON(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "The response code does not match what is expected")
+			if tc.expectedBody != "" {
+				assert.Equal(t, tc.expectedBody, strings.TrimSpace(w.Body.String()), "The response body does not match what is expected")
+			}
+		})
+	}
+}
+
+func TestShortenURL(t *testing.T) {
+	// describe the body being transmitted
+	url := "https://practicum.yandex.ru/learn/"
+	userReq := strings.NewReader(url)
+
+	// describe the expected response body for a successful request
+	successBody := "http://localhost:8080/7jSdiujrTAg"
+
+	testPostReq(t, userReq, successBody, "shortenURL")
+}
+
+func TestShortenURLCollision(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	urls := []string{"https://practicum.yandex.ru/first", "https://practicum.yandex.ru/second"}
+
+	// Force both URLs to hash to the same key
+	keygen := shorturl.NewHashGeneratorFunc("abcdefghijklmnopqrstuvwxyz", func(s string) uint64 {
+		if s == urls[0] || s == urls[1] {
+			return 42
+		}
+		h := fnv.New64a()
+		h.Write([]byte(s))
+		return h.Sum64()
+	})
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz, keygen, &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	t.Run("single", func(t *testing.T) {
+		shortURLs := make([]string, 0, len(urls))
+		for _, url := range urls {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url))
+			w := httptest.NewRecorder()
+
+			contr.shortenURL(w, r)
+
+			assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+			shortURLs = append(shortURLs, w.Body.String())
+		}
+
+		assert.NotEqual(t, shortURLs[0], shortURLs[1], "Colliding URLs must get different keys")
+
+		for i, shurl := range shortURLs {
+			r := httptest.NewRequest(http.MethodGet, "/"+path.Base(shurl), nil)
+			w := httptest.NewRecorder()
+
+			contr.getFullURL(w, r)
+
+			assert.Equal(t, urls[i], w.Header().Get("Location"), "The Location header is not what you expect")
+		}
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+		contr := NewBaseController(memoryStorage, option, nLogger, worker, authz, keygen, &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+		body := `[{"correlation_id": "1", "original_url": "` + urls[0] + `"},
+			{"correlation_id": "2", "original_url": "` + urls[1] + `"}]`
+		r := httptest.NewRequest(http.MethodPost, "/api/shorten/batch", strings.NewReader(body))
+		w := httptest.NewRecorder()
+
+		contr.shortenBatch(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+		var resp []models.DataURLite
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Len(t, resp, 2)
+		assert.NotEqual(t, resp[0].ShortURL, resp[1].ShortURL, "Colliding URLs must get different keys")
+	})
+}
+
+func TestShortenURLNormalized(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	normalizer, err := shorturl.NewNormalizer("lowercase,default-port,sort-query,strip-tracking")
+	if err != nil {
+		log.Fatalf("Unable to setup URL normalizer: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), normalizer, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// Equivalent URLs must be shortened to the same key, the second one is already shortened
+	shortURLs := make([]string, 0, 2)
+	for i, url := range []string{"https://Example.com:443/a?b=1&a=2", "https://example.com/a?a=2&b=1&utm_source=mail"} {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url))
+		w := httptest.NewRecorder()
+
+		contr.shortenURL(w, r)
+
+		assert.Equal(t, []int{http.StatusCreated, http.StatusConflict}[i], w.Code, "The response code does not match what is expected")
+		shortURLs = append(shortURLs, w.Body.String())
+	}
+
+	assert.Equal(t, shortURLs[0], shortURLs[1], "Equivalent URLs must get the same key")
+
+	stored, err := memoryStorage.GetURL(path.Base(shortURLs[0]))
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/a?a=2&b=1", stored.OriginalURL, "The stored URL is not normalized")
+}
+
+func TestShortenInvalidURL(t *testing.T) {
+	testCases := []struct {
+		name     string
+		funcName string
+		body     string
+		reason   string
+		field    string
+	}{
+		{name: "javascript", funcName: "shortenURL", body: "javascript:alert(1)", reason: shorturl.ReasonNotAbsolute},
+		{name: "random text", funcName: "shortenURL", body: "just some text", reason: shorturl.ReasonMalformed},
+		{name: "whitespace only", funcName: "shortenJSON", body: `{"url": "   "}`, reason: shorturl.ReasonEmpty, field: "url"},
+		{name: "scheme", funcName: "shortenJSON", body: `{"url": "ftp://example.com/file"}`,
+			reason: shorturl.ReasonScheme, field: "url"},
+		{name: "batch", funcName: "shortenBatch",
+			body: `[{"correlation_id": "1", "original_url": "https://example.com"},
+				{"correlation_id": "2", "original_url": "data:text/html,hi"}]`,
+			reason: shorturl.ReasonNotAbsolute, field: "[1].original_url"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			switch tc.funcName {
+			case "shortenJSON":
+				controller.shortenJSON(w, r)
+			case "shortenBatch":
+				controller.shortenBatch(w, r)
+			case "shortenURL":
+				controller.shortenURL(w, r)
+			}
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+
+			var resp models.ErrorResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, "invalid_url", resp.Error)
+			assert.Equal(t, tc.reason, resp.Reason)
+			assert.Equal(t, tc.field, resp.Field)
+		})
+	}
+}
+
+func TestShortenURLUserScope(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeUser)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// Both users shorten the same URL and must own their own copies
+	url := "https://practicum.yandex.ru/shared"
+	users := []string{"user-a", "user-b"}
+	shortURLs := make([]string, 0, len(users))
+	for _, userID := range users {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(url))
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, userID))
+		w := httptest.NewRecorder()
+
+		contr.shortenURL(w, r)
+
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+		shortURLs = append(shortURLs, w.Body.String())
+	}
+
+	assert.NotEqual(t, shortURLs[0], shortURLs[1], "Every user must get their own key")
+
+	for i, userID := range users {
+		urls, _, err := memoryStorage.GetUserURLs(userID, storage.URLQuery{})
+		assert.NoError(t, err)
+		if assert.Len(t, urls, 1) {
+			assert.Equal(t, shortURLs[i], urls[0].ShortURL)
+		}
+	}
+}
+
+func TestShortenJSONExpiring(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The link is shortened with a time to live
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/ttl", "ttl": "1h"}`))
+	w := httptest.NewRecorder()
+	before := time.Now()
+
+	contr.shortenJSON(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	key := path.Base(resp.Result)
+	data, err := memoryStorage.GetURL(key)
+	if assert.NoError(t, err) && assert.NotNil(t, data.ExpiresAt) {
+		assert.WithinDuration(t, before.Add(time.Hour), *data.ExpiresAt, time.Minute)
+	}
+
+	// The link works until it expires
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+
+	// The link is gone once it has expired
+	past := time.Now().Add(-time.Second)
+	_, err = memoryStorage.InsertURL("expired", models.DataURL{ShortURL: "http://localhost:8080/expired",
+		OriginalURL: "https://practicum.yandex.ru/expired", ExpiresAt: &past})
+	assert.NoError(t, err)
+
+	r = httptest.NewRequest(http.MethodGet, "/expired", nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusGone, w.Code, "The response code does not match what is expected")
+}
+
+func TestShortenJSONRedirectCode(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	testCases := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{name: "default", body: `{"url": "https://practicum.yandex.ru/default"}`,
+			expectedCode: http.StatusTemporaryRedirect},
+		{name: "permanent", body: `{"url": "https://practicum.yandex.ru/permanent", "redirect_code": 301}`,
+			expectedCode: http.StatusMovedPermanently},
+		{name: "found", body: `{"url": "https://practicum.yandex.ru/found", "redirect_code": 302}`,
+			expectedCode: http.StatusFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			contr.shortenJSON(w, r)
+			assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+			var resp models.Response
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+			// The link redirects with its own code or the server default
+			r = httptest.NewRequest(http.MethodGet, "/"+path.Base(resp.Result), nil)
+			w = httptest.NewRecorder()
+			contr.getFullURL(w, r)
+			assert.Equal(t, tc.expectedCode, w.Code, "The response code does not match what is expected")
+		})
+	}
+}
+
+func TestShortenJSONQueryPolicy(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	testCases := []struct {
+		name     string
+		body     string
+		location string
+	}{
+		{name: "drop", body: `{"url": "https://practicum.yandex.ru/drop?id=7"}`,
+			location: "https://practicum.yandex.ru/drop?id=7"},
+		{name: "merge", body: `{"url": "https://practicum.yandex.ru/merge?id=7", "query_policy": "merge"}`,
+			location: "https://practicum.yandex.ru/merge?id=7&ref=newsletter"},
+		{name: "utm", body: `{"url": "https://practicum.yandex.ru/utm", "query_policy": "utm",
+			"utm_source": "newsletter", "utm_medium": "email"}`,
+			location: "https://practicum.yandex.ru/utm?utm_medium=email&utm_source=newsletter"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+			contr.shortenJSON(w, r)
+			assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+			var resp models.Response
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+			// The query string of the short URL is handled by the policy of the link
+			r = httptest.NewRequest(http.MethodGet, "/"+path.Base(resp.Result)+"?ref=newsletter", nil)
+			w = httptest.NewRecorder()
+			contr.getFullURL(w, r)
+			assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+			assert.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestShortenJSONRules(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The link sends the mobile users to the app stores
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/app",
+		"rules": [{"platform": "ios", "url": "https://apps.apple.com/app/id1"},
+			{"platform": "android", "url": "https://play.google.com/store/apps/details?id=app"}]}`))
+	w := httptest.NewRecorder()
+	contr.shortenJSON(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+
+	testCases := []struct {
+		name      string
+		userAgent string
+		location  string
+	}{
+		{name: "ios", userAgent: "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)",
+			location: "https://apps.apple.com/app/id1"},
+		{name: "android", userAgent: "Mozilla/5.0 (Linux; Android 14; Pixel 8)",
+			location: "https://play.google.com/store/apps/details?id=app"},
+		{name: "web", userAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64)",
+			location: "https://practicum.yandex.ru/app"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+key, nil)
+			r.Header.Set("User-Agent", tc.userAgent)
+			w := httptest.NewRecorder()
+			contr.getFullURL(w, r)
+			assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+			assert.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+// countries is a GeoLocator resolving the IP addresses by a fixed table.
+type countries map[string]string
+
+func (c countries) Country(ip string) string {
+	return c[ip]
+}
+
+func TestShortenJSONCountryRules(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false),
+		nil, countries{"192.0.2.1": "DE", "198.51.100.1": "FR"}, nil)
+
+	// The link sends the users from Germany to the local store
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/store",
+		"rules": [{"country": "de", "url": "https://practicum.yandex.ru/store/de"}]}`))
+	w := httptest.NewRecorder()
+	contr.shortenJSON(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+
+	testCases := []struct {
+		name       string
+		remoteAddr string
+		realIP     string
+		location   string
+	}{
+		{name: "remote address", remoteAddr: "192.0.2.1:1234", location: "https://practicum.yandex.ru/store/de"},
+		{name: "real ip", remoteAddr: "10.0.0.1:1234", realIP: "192.0.2.1",
+			location: "https://practicum.yandex.ru/store/de"},
+		{name: "other country", remoteAddr: "198.51.100.1:1234", location: "https://practicum.yandex.ru/store"},
+		{name: "unknown", remoteAddr: "203.0.113.1:1234", location: "https://practicum.yandex.ru/store"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/"+key, nil)
+			r.RemoteAddr = tc.remoteAddr
+			if tc.realIP != "" {
+				r.Header.Set("X-Real-IP", tc.realIP)
+			}
+			w := httptest.NewRecorder()
+			contr.getFullURL(w, r)
+			assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+			assert.Equal(t, tc.location, w.Header().Get("Location"))
+		})
+	}
+}
+
+func TestShortenJSONVariants(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The link splits the traffic 70/30 and keeps the visitors on their variant
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/landing",
+		"variants": [{"url": "https://practicum.yandex.ru/landing/a", "weight": 70},
+			{"url": "https://practicum.yandex.ru/landing/b", "weight": 30}], "sticky": true}`))
+	w := httptest.NewRecorder()
+	contr.shortenJSON(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+	variants := []string{"https://practicum.yandex.ru/landing/a", "https://practicum.yandex.ru/landing/b"}
+
+	// A new visitor is sent to a variant and gets the cookie of the variant
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, variants, w.Header().Get("Location"))
+
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, "variant", cookies[0].Name)
+		assert.Equal(t, "/"+key, cookies[0].Path)
+	}
+
+	// A returning visitor sticks to the variant of the cookie
+	for i := 0; i < 3; i++ {
+		r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+		r.AddCookie(&http.Cookie{Name: "variant", Value: "2"})
+		w = httptest.NewRecorder()
+		contr.getFullURL(w, r)
+		assert.Equal(t, "https://practicum.yandex.ru/landing/b", w.Header().Get("Location"))
+	}
+
+	// The redirects are counted per variant
+	data, err := memoryStorage.GetURL(key)
+	assert.NoError(t, err)
+	if assert.Len(t, data.Variants, 2) {
+		assert.Equal(t, 4, data.Variants[0].Clicks+data.Variants[1].Clicks)
+		assert.GreaterOrEqual(t, data.Variants[1].Clicks, 3)
+	}
+}
+
+func TestShortenJSONSingleUse(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The link is shortened for a single redirect
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/once", "max_clicks": 1}`))
+	w := httptest.NewRecorder()
+
+	contr.shortenJSON(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+
+	// The first redirect succeeds, the link is gone afterwards
+	for _, expectedCode := range []int{http.StatusTemporaryRedirect, http.StatusGone, http.StatusGone} {
+		r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+		w = httptest.NewRecorder()
+		contr.getFullURL(w, r)
+		assert.Equal(t, expectedCode, w.Code, "The response code does not match what is expected")
+	}
+}
+
+func TestProtectedURL(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	limiter := linkpass.NewLimiter(linkpass.DefaultMaxAttempts, linkpass.DefaultWindow)
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), limiter, nil, nil)
+
+	// The link is shortened with a password
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/private", "password": "s3cret"}`))
+	w := httptest.NewRecorder()
+
+	contr.shortenJSON(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+
+	// The link serves the password form instead of redirecting
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `name="password"`)
+	assert.Empty(t, w.Header().Get("Location"))
+
+	// The destination of a protected link is not previewed
+	r = httptest.NewRequest(http.MethodGet, "/"+key+"+", nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, w.Body.String(), `name="password"`)
+	assert.NotContains(t, w.Body.String(), "practicum.yandex.ru/private")
+
+	unlock := func(password string) *httptest.ResponseRecorder {
+		form := url.Values{"password": {password}}
+		r := httptest.NewRequest(http.MethodPost, "/"+key, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		w := httptest.NewRecorder()
+		contr.unlockURL(w, r)
+		return w
+	}
+
+	// A wrong password is refused
+	w = unlock("wrong")
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "The response code does not match what is expected")
+
+	// The right password redirects to the original URL
+	w = unlock("s3cret")
+	assert.Equal(t, http.StatusSeeOther, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "https://practicum.yandex.ru/private", w.Header().Get("Location"))
+
+	// Too many failed attempts block the link, even for the right password
+	for i := 0; i < linkpass.DefaultMaxAttempts; i++ {
+		unlock("wrong")
+	}
+	w = unlock("s3cret")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code, "The response code does not match what is expected")
+
+	// The block is shared with the gRPC server of the same limiter
+	server := NewUsersServer(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), limiter, nil, nil)
+	_, err = server.GetFullURL(context.Background(), &pb.GetURLRequest{Key: key, Password: "s3cret"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+// interstitialOptions are the options of a server showing the preview page for the
+// external destinations.
+type interstitialOptions struct {
+	*config.Options
+}
+
+func (o interstitialOptions) InterstitialExternal() bool {
+	return true
+}
+
+func TestPreviewURL(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	_, err = memoryStorage.InsertUser("ann@example.com", models.DataUser{UUID: "owner", Name: "Ann"})
+	assert.NoError(t, err)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The link is shortened by a registered user
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "https://practicum.yandex.ru/course?a=1&b=2"}`))
+	r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+	w := httptest.NewRecorder()
+	contr.shortenJSON(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	var resp models.Response
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	key := path.Base(resp.Result)
+
+	testCases := []struct {
+		name   string
+		target string
+		action string
+	}{
+		{name: "suffix", target: "/" + key + "+", action: `action="/` + key + `"`},
+		{name: "query parameter", target: "/" + key + "?preview=1&ref=x", action: `action="/` + key + `?ref=x"`},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			w := httptest.NewRecorder()
+			contr.getFullURL(w, r)
+
+			assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+			assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+			assert.Empty(t, w.Header().Get("Location"))
+			assert.Contains(t, w.Body.String(), "https://practicum.yandex.ru/course?a=1&amp;b=2")
+			assert.Contains(t, w.Body.String(), "Created on "+time.Now().UTC().Format("2 January 2006")+" by Ann.")
+			assert.Contains(t, w.Body.String(), tc.action)
+		})
+	}
+
+	// Without the suffix the link redirects
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+
+	// The continue button follows the link
+	r = httptest.NewRequest(http.MethodPost, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.unlockURL(w, r)
+	assert.Equal(t, http.StatusSeeOther, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "https://practicum.yandex.ru/course?a=1&b=2", w.Header().Get("Location"))
+
+	// The server configured so previews the links to the external domains only
+	contr = NewBaseController(memoryStorage, interstitialOptions{option}, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, w.Body.String(), "Continue")
+
+	r = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"url": "http://localhost:8080/docs"}`))
+	w = httptest.NewRecorder()
+	contr.shortenJSON(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+
+	r = httptest.NewRequest(http.MethodGet, "/"+path.Base(resp.Result), nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "http://localhost:8080/docs", w.Header().Get("Location"))
+}
+
+func TestPreviewDestinations(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
 
-	// Set up a mock for the LoadUsers method
-	keeperMock.On("LoadUsers").Return(storage.StorageUser{}, nil)
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
 
-	// Set up a wait for the GetUser method to return an error indicating
-	//that the user already exists
-	keeperMock.On("GetUser", "test@example.com").Return(storage.ErrConflict)
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
 
-	data := storage.StorageURL{
-		"1": {UUID: "", ShortURL: "", OriginalURL: "https://practicum.yandex.ru/"},
-		"2": {UUID: "", ShortURL: "", OriginalURL: "https://www.google.ru/"},		
+	_, err = memoryStorage.InsertURL("routed", models.DataURL{ShortURL: "http://localhost:8080/routed",
+		OriginalURL: "https://example.com/", Sticky: true,
+		Rules: []models.Rule{{Language: "de", URL: "https://example.de/"}},
+		Variants: []models.Variant{{URL: "https://example.com/a", Weight: 50}, {URL: "https://example.com/b", Weight: 50},
+			{URL: "https://example.com/off", Weight: 0}}})
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		language string
+		cookie   string
+		want     []string
+		notWant  []string
+	}{
+		{name: "matching rule", language: "de", want: []string{"https://example.de/"},
+			notWant: []string{"https://example.com/a", "https://example.com/b"}},
+		{name: "sticky variant", cookie: "2", want: []string{"https://example.com/b"},
+			notWant: []string{"https://example.com/a", "https://example.de/"}},
+		{name: "split", want: []string{"one of", "https://example.com/a", "https://example.com/b"},
+			notWant: []string{"https://example.com/off", "https://example.de/"}},
 	}
 
-	keeperMock.On("SaveBatch", data).Return(nil)
-	// Set up expectations for methods that will be called inside the Register function
-	keeperMock.On("GetUser", "test@example.com").Return(nil) // Example: GetUser method returns an error that the user does not exist
-	keeperMock.On("InsertUser", "test@example.com", mock.AnythingOfType("models.DataUser")).Return(nil)
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/routed+", nil)
+			if tc.language != "" {
+				r.Header.Set("Accept-Language", tc.language)
+			}
+			if tc.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: variantCookie, Value: tc.cookie})
+			}
+			w := httptest.NewRecorder()
+			contr.getFullURL(w, r)
 
-	keeperMock.On("Ping").Return(true)
+			assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+			for _, s := range tc.want {
+				assert.Contains(t, w.Body.String(), s)
+			}
+			for _, s := range tc.notWant {
+				assert.NotContains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
 
-	// Generate a unique key for the test
-	key := "nOykhckC3Od"
+func TestGetQRCode(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
 
-	// Generate data for the Save method
-	dataURL := models.DataURL{
-		UUID:        "",
-		OriginalURL: "https://practicum.yandex.ru/",
-		ShortURL:    "http://localhost:8080/" + key,
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
 	}
 
-	// Set up a mock for the Save method
-	keeperMock.On("Save", key, dataURL).Return(dataURL, nil)
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	_, err = memoryStorage.InsertURL("qrcode", models.DataURL{ShortURL: "http://localhost:8080/qrcode",
+		OriginalURL: "https://practicum.yandex.ru/qr"})
+	assert.NoError(t, err)
+	_, err = memoryStorage.InsertURL("qrgone", models.DataURL{ShortURL: "http://localhost:8080/qrgone",
+		OriginalURL: "https://practicum.yandex.ru/gone", DeletedFlag: true})
+	assert.NoError(t, err)
+
+	// The QR code is a PNG image by default, served with an entity tag
+	r := httptest.NewRequest(http.MethodGet, "/qrcode/qr", nil)
+	w := httptest.NewRecorder()
+	contr.getQRCode(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	etag := w.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+	_, err = png.Decode(w.Body)
+	assert.NoError(t, err)
+
+	// The client revalidates the image it has
+	r = httptest.NewRequest(http.MethodGet, "/qrcode/qr", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	contr.getQRCode(w, r)
+
+	assert.Equal(t, http.StatusNotModified, w.Code, "The response code does not match what is expected")
+	assert.Empty(t, w.Body.String())
+
+	// Another format is another image
+	r = httptest.NewRequest(http.MethodGet, "/qrcode/qr?size=128&level=H&format=svg", nil)
+	r.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	contr.getQRCode(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "<svg"))
+
+	// An unsupported option is rejected
+	r = httptest.NewRequest(http.MethodGet, "/qrcode/qr?size=10000", nil)
+	w = httptest.NewRecorder()
+	contr.getQRCode(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+	var errResp models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, "invalid_options", errResp.Error)
+	assert.Equal(t, "size", errResp.Field)
+
+	// Unknown and deleted links are answered like redirects
+	for key, expectedCode := range map[string]int{"unknown": http.StatusNotFound, "qrgone": http.StatusGone} {
+		r = httptest.NewRequest(http.MethodGet, "/"+key+"/qr", nil)
+		w = httptest.NewRecorder()
+		contr.getQRCode(w, r)
+
+		assert.Equal(t, expectedCode, w.Code, "The response code does not match what is expected")
+	}
+}
+
+func TestUpdateUserURL(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
 
-	memoryStorage := storage.NewMemoryStorage(keeperMock, nLogger)
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
 
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
 	worker := worker.NewWorker(nLogger, memoryStorage)
 	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
 
-	controller = NewBaseController(memoryStorage, option, nLogger, worker, authz)
-	if controller == nil {
-		log.Fatalf("Unable to initialize baseController\n")
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The owner shortens a URL with a typo
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("https://practicum.yandex.ru/typo"))
+	r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+	w := httptest.NewRecorder()
+	contr.shortenURL(w, r)
+	assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+	shortURL := w.Body.String()
+	key := path.Base(shortURL)
+
+	testCases := []struct {
+		name         string
+		userID       string
+		key          string
+		body         string
+		expectedCode int
+	}{
+		{name: "not owner", userID: "stranger", key: key, body: `{"url": "https://evil.example.com"}`,
+			expectedCode: http.StatusForbidden},
+		{name: "not found", userID: "owner", key: "missing", body: `{"url": "https://practicum.yandex.ru/"}`,
+			expectedCode: http.StatusNotFound},
+		{name: "invalid url", userID: "owner", key: key, body: `{"url": "javascript:alert(1)"}`,
+			expectedCode: http.StatusBadRequest},
+		{name: "bad body", userID: "owner", key: key, body: `{`, expectedCode: http.StatusBadRequest},
+		{name: "fixed", userID: "owner", key: key, body: `{"url": "https://practicum.yandex.ru/fixed"}`,
+			expectedCode: http.StatusOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPatch, "/api/user/urls/"+tc.key, strings.NewReader(tc.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("key", tc.key)
+			ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+			r = r.WithContext(context.WithValue(ctx, keyUserID, tc.userID))
+			w := httptest.NewRecorder()
+
+			contr.updateUserURL(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "The response code does not match what is expected")
+		})
 	}
+
+	// The key now redirects to the fixed URL
+	r = httptest.NewRequest(http.MethodGet, "/"+key, nil)
+	w = httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+	assert.Equal(t, "https://practicum.yandex.ru/fixed", w.Header().Get("Location"))
 }
 
-func TestShortenJSON(t *testing.T) {
+func TestUserURLTags(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
 
-	// describe the body being transmitted
-	userReq := strings.NewReader(`{         
-        "url": "https://practicum.yandex.ru/"
-    }`)
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
 
-	// describe the expected response body for a successful request
-	successBody := `{"result":"http://localhost:8080/nOykhckC3Od"}`
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
 
-	testPostReq(t, userReq, successBody, "shortenJSON")
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	// The links are tagged on creation, the tags are normalized
+	keys := make(map[string]string)
+	for name, body := range map[string]string{
+		"campaign": `{"url": "https://practicum.yandex.ru/campaign", "tags": ["Marketing", "q3", "marketing"]}`,
+		"report":   `{"url": "https://practicum.yandex.ru/report", "tags": ["q3"]}`,
+		"untagged": `{"url": "https://practicum.yandex.ru/untagged"}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.shortenJSON(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+		var resp models.Response
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		keys[name] = path.Base(resp.Result)
+	}
+
+	data, err := memoryStorage.GetURL(keys["campaign"])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"marketing", "q3"}, data.Tags)
+
+	userURLs := func(query string) []models.DataURLite {
+		r := httptest.NewRequest(http.MethodGet, "/api/user/urls"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.getUserURLs(w, r)
+
+		var resp []models.DataURLite
+		if w.Code == http.StatusOK {
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		}
+		return resp
+	}
+
+	// The links are filtered by all of the tags
+	assert.Len(t, userURLs(""), 3)
+	assert.Len(t, userURLs("?tag=q3"), 2)
+	filtered := userURLs("?tag=Q3&tag=marketing")
+	if assert.Len(t, filtered, 1) {
+		assert.Equal(t, "https://practicum.yandex.ru/campaign", filtered[0].OriginalURL)
+		assert.Equal(t, []string{"marketing", "q3"}, filtered[0].Tags)
+	}
+	assert.Empty(t, userURLs("?tag=sales"))
+
+	// The tags are edited later, the original URL is kept
+	patch := func(key string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPatch, "/api/user/urls/"+key, strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("key", key)
+		ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+		r = r.WithContext(context.WithValue(ctx, keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.updateUserURL(w, r)
+		return w
+	}
+
+	w := patch(keys["untagged"], `{"tags": ["sales", "q3"]}`)
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	var updated models.DataURLite
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&updated))
+	assert.Equal(t, "https://practicum.yandex.ru/untagged", updated.OriginalURL)
+	assert.Equal(t, []string{"sales", "q3"}, updated.Tags)
+	assert.Len(t, userURLs("?tag=q3"), 3)
+
+	w = patch(keys["report"], `{"tags": []}`)
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Len(t, userURLs("?tag=q3"), 2)
+
+	w = patch(keys["report"], `{"tags": ["no/slashes"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+	var errResp models.ErrorResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+	assert.Equal(t, "tags[0]", errResp.Field)
 }
 
-func TestShortenURL(t *testing.T) {
-	// describe the body being transmitted
-	url := "https://practicum.yandex.ru/"
-	userReq := strings.NewReader(url)
+func TestGetUserURLsPages(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
 
-	// describe the expected response body for a successful request
-	successBody := "http://localhost:8080/nOykhckC3Od"
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
 
-	testPostReq(t, userReq, successBody, "shortenURL")
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	for _, alias := range []string{"pages-c", "pages-a", "pages-b"} {
+		body := fmt.Sprintf(`{"url": "https://practicum.yandex.ru/%s", "alias": %q}`, alias, alias)
+		r := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.shortenJSON(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+	}
+
+	get := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/user/urls"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.getUserURLs(w, r)
+		return w
+	}
+
+	// The links are listed page by page in the order of their keys
+	var keys []string
+	query := "?sort=key&page_size=2"
+	for page := 0; page < 3; page++ {
+		w := get(query)
+		assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+
+		var resp []models.DataURLite
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		for _, d := range resp {
+			keys = append(keys, path.Base(d.ShortURL))
+		}
+
+		next := w.Header().Get("X-Next-Page-Token")
+		if next == "" {
+			break
+		}
+		query = "?sort=key&page_size=2&page_token=" + url.QueryEscape(next)
+	}
+	assert.Equal(t, []string{"pages-a", "pages-b", "pages-c"}, keys)
+
+	// The links are filtered by a substring
+	w := get("?search=PAGES-B")
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	var resp []models.DataURLite
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	if assert.Len(t, resp, 1) {
+		assert.NotNil(t, resp[0].CreatedAt)
+	}
+	assert.Equal(t, http.StatusNoContent, get("?deleted=true").Code)
+
+	// Invalid parameters are rejected with the name of the parameter
+	for query, field := range map[string]string{
+		"?sort=size":                 "sort",
+		"?order=up":                  "order",
+		"?page_size=0":               "page_size",
+		"?deleted=maybe":             "deleted",
+		"?created_from=2026-10-01":   "created_from",
+		"?page_token=not-a-token":    "page_token",
+		"?sort=created&page_token=e": "page_token",
+	} {
+		w := get(query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+
+		var errResp models.ErrorResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&errResp))
+		assert.Equal(t, "invalid_query", errResp.Error)
+		assert.Equal(t, field, errResp.Field, query)
+	}
+}
+
+func TestSearchUserURLs(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	for userID, body := range map[string]string{
+		"owner":    `{"url": "https://shop.example.com/sale", "tags": ["autumn"], "note": "Landing of the big sale"}`,
+		"stranger": `{"url": "https://shop.example.org/sale", "tags": ["autumn"]}`,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, userID))
+		w := httptest.NewRecorder()
+		contr.shortenJSON(w, r)
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+	}
+
+	search := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/user/urls/search"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.searchUserURLs(w, r)
+		return w
+	}
+
+	// The links of the user are found by their host, tags and note
+	for _, query := range []string{"?q=shop.example", "?q=AUTUMN+big", "?q=landing&limit=1"} {
+		w := search(query)
+		assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+
+		var resp []models.DataURLite
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		if assert.Len(t, resp, 1, query) {
+			assert.Equal(t, "https://shop.example.com/sale", resp[0].OriginalURL)
+			assert.Equal(t, "Landing of the big sale", resp[0].Note)
+		}
+	}
+
+	assert.Equal(t, http.StatusNoContent, search("?q=example.org").Code)
+	assert.Equal(t, http.StatusBadRequest, search("?q=").Code)
+	assert.Equal(t, http.StatusBadRequest, search("?q=sale&limit=0").Code)
+}
+
+// domainOptions are the options of a server with an additional branded domain.
+type domainOptions struct {
+	*config.Options
+}
+
+func (o domainOptions) Domains() string {
+	return "https://go.example.com/"
+}
+
+func TestDomains(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, domainOptions{option}, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	shorten := func(body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(body))
+		r = r.WithContext(context.WithValue(r.Context(), keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.shortenJSON(w, r)
+		return w
+	}
+
+	// The same alias is bound on both domains
+	testCases := []struct {
+		body     string
+		shortURL string
+	}{
+		{body: `{"url": "https://example.com/default", "alias": "promo"}`, shortURL: "http://localhost:8080/promo"},
+		{body: `{"url": "https://example.com/branded", "alias": "promo", "domain": "Go.Example.com"}`,
+			shortURL: "https://go.example.com/promo"},
+	}
+	for _, tc := range testCases {
+		w := shorten(tc.body)
+		assert.Equal(t, http.StatusCreated, w.Code, "The response code does not match what is expected")
+
+		var resp models.Response
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+		assert.Equal(t, tc.shortURL, resp.Result)
+	}
+
+	w := shorten(`{"url": "https://example.com/", "domain": "example.org"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, w.Body.String(), `"field":"domain"`)
+
+	// The key is resolved on the domain of the Host header, the other hosts are served as the default domain
+	for host, location := range map[string]string{
+		"localhost:8080":     "https://example.com/default",
+		"go.example.com":     "https://example.com/branded",
+		"GO.EXAMPLE.COM:443": "https://example.com/branded",
+		"example.net":        "https://example.com/default",
+	} {
+		r := httptest.NewRequest(http.MethodGet, "http://"+host+"/promo", nil)
+		w := httptest.NewRecorder()
+		contr.getFullURL(w, r)
+		assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+		assert.Equal(t, location, w.Header().Get("Location"), host)
+	}
+
+	// The domain query parameter selects the link to update
+	update := func(domain string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPatch, "/api/user/urls/promo?domain="+domain,
+			strings.NewReader(`{"url": "https://example.com/updated"}`))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("key", "promo")
+		ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+		r = r.WithContext(context.WithValue(ctx, keyUserID, "owner"))
+		w := httptest.NewRecorder()
+		contr.updateUserURL(w, r)
+		return w
+	}
+	assert.Equal(t, http.StatusBadRequest, update("example.org").Code)
+	w = update("go.example.com")
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.Contains(t, w.Body.String(), `"domain":"go.example.com"`)
+
+	data, err := memoryStorage.GetURL("go.example.com/promo")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/updated", data.OriginalURL)
+
+	data, err = memoryStorage.GetURL("promo")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/default", data.OriginalURL)
+}
+
+func TestRestoreUserURLs(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	testCases := []struct {
+		name         string
+		userID       string
+		body         string
+		expectedCode int
+	}{
+		{name: "accepted", userID: "owner", body: `["abc", "def"]`, expectedCode: http.StatusAccepted},
+		{name: "bad body", userID: "owner", body: `{`, expectedCode: http.StatusBadRequest},
+		{name: "no user", body: `["abc"]`, expectedCode: http.StatusUnauthorized},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", strings.NewReader(tc.body))
+			if tc.userID != "" {
+				r = r.WithContext(context.WithValue(r.Context(), keyUserID, tc.userID))
+			}
+			w := httptest.NewRecorder()
+
+			contr.restoreUserURLs(w, r)
+
+			assert.Equal(t, tc.expectedCode, w.Code, "The response code does not match what is expected")
+		})
+	}
+}
+
+func TestShortenInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name     string
+		funcName string
+		body     string
+		field    string
+	}{
+		{name: "bad ttl", funcName: "shortenJSON", body: `{"url": "https://example.com", "ttl": "soon"}`, field: "ttl"},
+		{name: "negative ttl", funcName: "shortenJSON", body: `{"url": "https://example.com", "ttl": "-1h"}`, field: "ttl"},
+		{name: "past", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "expires_at": "2000-01-01T00:00:00Z"}`, field: "expires_at"},
+		{name: "negative max clicks", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "max_clicks": -1}`, field: "max_clicks"},
+		{name: "redirect code", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "redirect_code": 200}`, field: "redirect_code"},
+		{name: "query policy", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "query_policy": "keep"}`, field: "query_policy"},
+		{name: "utm without policy", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "utm_source": "newsletter"}`, field: "query_policy"},
+		{name: "rule platform", funcName: "shortenJSON",
+			body:  `{"url": "https://example.com", "rules": [{"platform": "symbian", "url": "https://example.org"}]}`,
+			field: "rules[0].platform"},
+		{name: "rule condition", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "rules": [{"url": "https://example.org"}]}`, field: "rules[0]"},
+		{name: "single variant", funcName: "shortenJSON",
+			body:  `{"url": "https://example.com", "variants": [{"url": "https://example.org", "weight": 1}]}`,
+			field: "variants"},
+		{name: "variant weight", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "variants": [{"url": "https://example.org", "weight": 1},
+				{"url": "https://example.net"}]}`, field: "variants[1].weight"},
+		{name: "variant url", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "variants": [{"url": "ftp://example.org", "weight": 1},
+				{"url": "https://example.net", "weight": 1}]}`, field: "variants[0].url"},
+		{name: "sticky without variants", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "sticky": true}`, field: "sticky"},
+		{name: "note", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "note": "` + strings.Repeat("n", 1001) + `"}`, field: "note"},
+		{name: "tag", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "tags": ["q3", "two words"]}`, field: "tags[1]"},
+		{name: "rule country", funcName: "shortenJSON",
+			body:  `{"url": "https://example.com", "rules": [{"country": "Germany", "url": "https://example.org"}]}`,
+			field: "rules[0].country"},
+		{name: "rule url", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "rules": [{"platform": "ios", "url": "https://example.org"},
+				{"platform": "android", "url": "javascript:alert(1)"}]}`, field: "rules[1].url"},
+		{name: "both", funcName: "shortenJSON",
+			body: `{"url": "https://example.com", "ttl": "1h", "expires_at": "2999-01-01T00:00:00Z"}`, field: "ttl"},
+		{name: "batch", funcName: "shortenBatch",
+			body: `[{"correlation_id": "1", "original_url": "https://example.com"},
+				{"correlation_id": "2", "original_url": "https://example.org", "ttl": "0s"}]`,
+			field: "[1].ttl"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			w := httptest.NewRecorder()
+
+			switch tc.funcName {
+			case "shortenJSON":
+				controller.shortenJSON(w, r)
+			case "shortenBatch":
+				controller.shortenBatch(w, r)
+			}
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, "The response code does not match what is expected")
+
+			var resp models.ErrorResponse
+			assert.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+			assert.Equal(t, "invalid_options", resp.Error)
+			assert.Equal(t, tc.field, resp.Field)
+		})
+	}
 }
 
 func testPostReq(t *testing.T, userReq *strings.Reader, successBody string, funcName string) {
@@ -220,11 +1710,11 @@ func TestGetPing(t *testing.T) {
 		log.Fatalf("Unable to setup logger: %s\n", err)
 	}
 
-	memoryStorage := storage.NewMemoryStorage(nil, nLogger)
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
 	worker := worker.NewWorker(nLogger, memoryStorage)
 	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
 
-	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz)
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz, shorturl.NewHashGenerator("abc"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
 
 	// Create a GET request
 	req, err = http.NewRequest("GET", "/ping", nil)
@@ -276,12 +1766,12 @@ func TestGetPingNoKeeper(t *testing.T) {
 		log.Fatalf("Unable to setup logger: %s\n", err)
 	}
 
-	memoryStorage := storage.NewMemoryStorage(nil, nLogger) // pass nil instead of mock
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal) // pass nil instead of mock
 
 	worker := worker.NewWorker(nLogger, memoryStorage)
 	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
 
-	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz)
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz, shorturl.NewHashGenerator("abc"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
 
 	// Create a GET request
 	req, err := http.NewRequest("GET", "/ping", nil)
@@ -296,3 +1786,144 @@ func TestGetPingNoKeeper(t *testing.T) {
 	// Check that the status code matches the expected one
 	assert.Equal(t, http.StatusInternalServerError, rr.Code, "expected status code 500")
 }
+
+// clickRecorder records the click events in memory.
+type clickRecorder struct {
+	events []models.ClickEvent
+}
+
+func (c *clickRecorder) Record(e models.ClickEvent) {
+	c.events = append(c.events, e)
+}
+
+func TestRecordClick(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+	clicks := new(clickRecorder)
+
+	contr := NewBaseController(memoryStorage, domainOptions{option}, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, clicks)
+
+	memoryStorage.InsertURL("promo", models.DataURL{OriginalURL: "https://example.com/", ShortURL: "http://localhost:8080/promo"})
+	memoryStorage.InsertURL("go.example.com/promo", models.DataURL{OriginalURL: "https://example.com/branded",
+		ShortURL: "https://go.example.com/promo", Domain: "go.example.com"})
+
+	r := httptest.NewRequest(http.MethodGet, "http://go.example.com/promo", nil)
+	r.Header.Set("Referer", "https://news.example.org/")
+	r.Header.Set("User-Agent", "test-agent")
+	r.Header.Set("X-Real-IP", "203.0.113.77")
+	r.AddCookie(&http.Cookie{Name: "jwt-token", Value: authz.CreateJWTTokenForUser("visitor")})
+	w := httptest.NewRecorder()
+	contr.getFullURL(w, r)
+	assert.Equal(t, http.StatusTemporaryRedirect, w.Code, "The response code does not match what is expected")
+
+	// The missing links are not clicked
+	r = httptest.NewRequest(http.MethodGet, "http://localhost:8080/missing", nil)
+	contr.getFullURL(httptest.NewRecorder(), r)
+
+	if assert.Len(t, clicks.events, 1) {
+		e := clicks.events[0]
+		assert.Equal(t, "go.example.com/promo", e.Key)
+		assert.Equal(t, "https://news.example.org/", e.Referrer)
+		assert.Equal(t, "test-agent", e.UserAgent)
+		assert.Equal(t, "203.0.113.0", e.IP)
+		assert.Equal(t, "visitor", e.UserID)
+		assert.False(t, e.Time.IsZero())
+	}
+}
+
+func TestTruncateIP(t *testing.T) {
+	for ip, want := range map[string]string{
+		"203.0.113.77":           "203.0.113.0",
+		"198.51.100.7, 10.0.0.1": "198.51.100.0",
+		"2001:db8:abcd:12::1":    "2001:db8:abcd::",
+		"::ffff:192.0.2.128":     "192.0.2.0",
+		"not an address":         "",
+		"":                       "",
+	} {
+		if got := truncateIP(ip); got != want {
+			t.Errorf("truncateIP(%q) = %q; want %q", ip, got, want)
+		}
+	}
+}
+
+func TestGetURLStats(t *testing.T) {
+	option := config.NewOptions()
+	option.ParseFlags()
+
+	nLogger, err := logger.NewLogger(option.LogLevel())
+	if err != nil {
+		log.Fatalf("Unable to setup logger: %s\n", err)
+	}
+
+	memoryStorage := storage.NewMemoryStorage(nil, nLogger, storage.ScopeGlobal)
+	worker := worker.NewWorker(nLogger, memoryStorage)
+	authz := authz.NewJWTAuthz(option.JWTSigningKey(), nLogger)
+
+	contr := NewBaseController(memoryStorage, option, nLogger, worker, authz,
+		shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"), &shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	memoryStorage.InsertURL("promo", models.DataURL{OriginalURL: "https://example.com/", ShortURL: "http://localhost:8080/promo",
+		UserID: "owner"})
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, memoryStorage.SaveClicks(
+		models.ClickEvent{Time: day.Add(9 * time.Hour), Key: "promo", Referrer: "https://news.example.org/", UserID: "u1", Country: "DE"},
+		models.ClickEvent{Time: day.Add(10 * time.Hour), Key: "promo", Referrer: "https://news.example.org/", UserID: "u1"},
+		models.ClickEvent{Time: day.Add(30 * time.Hour), Key: "promo", IP: "203.0.113.0", UserAgent: "test-agent"},
+	))
+
+	getStats := func(userID string, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/api/user/urls/promo/stats?"+query, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("key", "promo")
+		ctx := context.WithValue(r.Context(), chi.RouteCtxKey, rctx)
+		r = r.WithContext(context.WithValue(ctx, keyUserID, userID))
+		w := httptest.NewRecorder()
+		contr.getURLStats(w, r)
+		return w
+	}
+
+	w := getStats("owner", "from=2026-10-16T00:00:00Z&to=2026-10-18T00:00:00Z")
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+
+	var stats models.URLStats
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&stats))
+	assert.Equal(t, 3, stats.Clicks)
+	assert.Equal(t, 2, stats.Unique)
+	assert.Equal(t, []models.StatsBucket{{Start: day, Clicks: 2, Unique: 1}, {Start: day.Add(24 * time.Hour), Clicks: 1, Unique: 1}},
+		stats.Buckets)
+	assert.Equal(t, []models.StatsCount{{Value: "https://news.example.org/", Clicks: 2}}, stats.Referrers)
+	assert.Equal(t, []models.StatsCount{{Value: "DE", Clicks: 1}}, stats.Countries)
+
+	// The hourly buckets of the range
+	w = getStats("owner", "from=2026-10-16T09:00:00Z&to=2026-10-16T11:00:00Z&interval=hour")
+	assert.Equal(t, http.StatusOK, w.Code, "The response code does not match what is expected")
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&stats))
+	assert.Equal(t, 2, stats.Clicks)
+	assert.Len(t, stats.Buckets, 2)
+
+	// Only the owner gets the stats of the link
+	assert.Equal(t, http.StatusForbidden, getStats("intruder", "").Code)
+
+	// A deleted link is gone
+	assert.NoError(t, memoryStorage.DeleteURLs(models.DeleteURL{UserID: "owner", ShortURLs: []string{"promo"}}))
+	assert.Equal(t, http.StatusGone, getStats("owner", "").Code)
+	assert.NoError(t, memoryStorage.RestoreURLs(models.DeleteURL{UserID: "owner", ShortURLs: []string{"promo"}}))
+
+	for _, query := range []string{"interval=week", "from=yesterday", "top=0",
+		"from=2026-10-17T00:00:00Z&to=2026-10-16T00:00:00Z", "from=2020-01-01T00:00:00Z&interval=hour"} {
+		w = getStats("owner", query)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.Contains(t, w.Body.String(), "invalid_query", query)
+	}
+}
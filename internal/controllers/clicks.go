@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	pb "github.com/wurt83ow/tinyurl/internal/controllers/proto"
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"google.golang.org/grpc/metadata"
+)
+
+// Prefix lengths the client IP addresses of the click events are truncated to.
+const (
+	clickIPv4Bits = 24
+	clickIPv6Bits = 48
+)
+
+// truncateIP returns the network of the IP address, the /24 of an IPv4 address or the /48
+// of an IPv6 one, so that the click events do not identify the clients. For a list of
+// forwarded addresses the first one, the client's, is truncated. It returns an empty string
+// if the address is malformed.
+func truncateIP(ip string) string {
+	ip, _, _ = strings.Cut(ip, ",")
+	addr := net.ParseIP(strings.TrimSpace(ip))
+	if addr == nil {
+		return ""
+	}
+
+	if v4 := addr.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(clickIPv4Bits, 32)).String()
+	}
+
+	return addr.Mask(net.CIDRMask(clickIPv6Bits, 128)).String()
+}
+
+// recordClick records the click event of the redirect of the request by the URL stored under
+// the key. The user is the one of the authorization cookie of the request, if it is valid,
+// the country is located by the full IP address of the client.
+// The event is recorded without waiting for the storage.
+func (h *BaseController) recordClick(r *http.Request, key string) {
+	if h.clicks == nil {
+		return
+	}
+
+	ip := getClientIP(r)
+	e := models.ClickEvent{Time: time.Now().UTC(), Key: key, Referrer: r.Referer(),
+		UserAgent: r.UserAgent(), IP: truncateIP(ip), Country: clientCountry(h.locator, ip)}
+	if c, err := r.Cookie("jwt-token"); err == nil {
+		e.UserID, _ = h.authz.DecodeJWTToUser(c.Value)
+	}
+
+	h.clicks.Record(e)
+}
+
+// recordClick records the click event of the resolution of the request by the URL stored
+// under the key, like the redirects do. The User-Agent of the request is used, or the one of
+// the metadata if it is empty, and the user is the one of the authorization metadata, if it
+// is valid. The country is located by the full IP address of the client.
+func (s *UsersServer) recordClick(ctx context.Context, req *pb.GetURLRequest, key, ip string) {
+	if s.clicks == nil {
+		return
+	}
+
+	e := models.ClickEvent{Time: time.Now().UTC(), Key: key, Referrer: req.GetReferrer(),
+		UserAgent: req.GetUserAgent(), IP: truncateIP(ip), Country: clientCountry(s.locator, ip)}
+	if e.UserAgent == "" {
+		if ua := metadata.ValueFromIncomingContext(ctx, "user-agent"); len(ua) != 0 {
+			e.UserAgent = ua[0]
+		}
+	}
+	e.UserID, _ = s.authenticate(ctx)
+
+	s.clicks.Record(e)
+}
@@ -6,38 +6,68 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	pb "github.com/wurt83ow/tinyurl/internal/controllers/proto"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/domains"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/qr"
 	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
+	"github.com/wurt83ow/tinyurl/internal/services/targeting"
 	"github.com/wurt83ow/tinyurl/internal/storage"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
 // UsersServer supports all necessary server methods.
 type UsersServer struct {
-	storage Storage
-	options Options
-	log     Log
-	worker  Worker
-	authz   Authz
+	storage   Storage
+	options   Options
+	log       Log
+	worker    Worker
+	authz     Authz
+	shortener *shortener
+	limiter   *linkpass.Limiter
+	locator   GeoLocator
+	domains   *domains.Set
+	clicks    ClickRecorder
 	// need to embed type pb.Unimplemented<TypeName>
 	// for compatibility with future versions
 	pb.UnimplementedURLServiceServer
 }
 
-// NewUsersServer creates a new UsersServer instance.
-func NewUsersServer(storage Storage, options Options, log Log, worker Worker, authz Authz) *UsersServer {
+// NewUsersServer creates a new UsersServer instance. Like NewBaseController, it replaces
+// a nil limiter of the failed password attempts by a limiter of the defaults.
+func NewUsersServer(storage Storage, options Options, log Log, worker Worker, authz Authz,
+	keygen KeyGenerator, normalizer Normalizer, validator Validator, limiter *linkpass.Limiter,
+	locator GeoLocator, clicks ClickRecorder) *UsersServer {
 
+	if limiter == nil {
+		limiter = linkpass.NewLimiter(linkpass.DefaultMaxAttempts, linkpass.DefaultWindow)
+	}
+
+	set := newDomains(options, log)
 	instance := &UsersServer{
 		storage: storage,
 		options: options,
 		log:     log,
 		worker:  worker,
 		authz:   authz,
+		shortener: &shortener{storage: storage, keygen: keygen, normalizer: normalizer, validator: validator,
+			domains: set},
+		limiter: limiter,
+		locator: locator,
+		domains: set,
+		clicks:  clicks,
 		// need to embed type pb.Unimplemented<TypeName>
 		// for compatibility with future versions
 		UnimplementedURLServiceServer: pb.UnimplementedURLServiceServer{},
@@ -54,37 +84,38 @@ func (s *UsersServer) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequ
 		return nil, err
 	}
 
-	// Get the short URL address from the options
-	shortURLAdress := s.options.ShortURLAdress()
+	// Collect the full URLs of the batch with their link options
+	now := time.Now()
+	data := make([]models.DataURL, 0, len(req.Urls))
+	for i, url := range req.Urls {
+		d := models.DataURL{UUID: url.Uuid, OriginalURL: url.OriginalUrl, UserID: userID}
+		if err := applyProtoLinkOptions(&d, url.GetOptions(), now); err != nil {
+			return nil, invalidOptionsStatus(&batchItemError{index: i, err: err})
+		}
+		data = append(data, d)
+	}
 
-	// Initialize a map to store data about shortened URLs
-	dataURL := make(storage.StorageURL)
+	// Shorten the batch of URLs and insert it into storage
+	data, err = s.shortener.insertBatch(data)
+	if st := invalidURLStatus("original_url", err); st != nil {
+		return nil, st
+	}
+	if isInvalidOptions(err) {
+		return nil, invalidOptionsStatus(err)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Error inserting batch into storage")
+	}
 
 	// Initialize the response for the client
 	resp := &pb.ShortenBatchResponse{}
-
-	// Iterate through each URL in the batch
-	for _, url := range req.Urls {
-		// Shorten the original URL
-		key, shurl := shorturl.Shorten(url.OriginalUrl, shortURLAdress)
-
-		// Save the full URL to storage with the key received earlier
-		data := models.DataURL{UUID: url.Uuid, ShortURL: shurl, OriginalURL: url.OriginalUrl, UserID: userID}
-		dataURL[key] = data
-
-		// Add the shortened URL to the response
+	for _, d := range data {
 		resp.Urls = append(resp.Urls, &pb.ShortenedURL{
-			Uuid:     url.Uuid,
-			ShortUrl: shurl,
+			Uuid:     d.UUID,
+			ShortUrl: d.ShortURL,
 		})
 	}
 
-	// Insert the batch of URLs into storage
-	err = s.storage.InsertBatch(dataURL)
-	if err != nil {
-		return nil, status.Error(codes.Internal, "Error inserting batch into storage")
-	}
-
 	// Return a successful response
 	return resp, nil
 }
@@ -94,7 +125,8 @@ func (s *UsersServer) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequ
 func (s *UsersServer) ShortenJSON(ctx context.Context, req *pb.ShortenJSONRequest) (*pb.ShortenJSONResponse, error) {
 	// Convert request model from protobuf to internal model
 	internalReq := &models.Request{
-		URL: req.GetUrl(),
+		URL:   req.GetUrl(),
+		Alias: req.GetAlias(),
 	}
 
 	// Deserialize the request into the model structure
@@ -107,12 +139,6 @@ func (s *UsersServer) ShortenJSON(ctx context.Context, req *pb.ShortenJSONReques
 		return nil, status.Error(codes.InvalidArgument, "request JSON body is empty")
 	}
 
-	// Get the short URL address from the options
-	shortURLAdress := s.options.ShortURLAdress()
-
-	// Shorten the original URL
-	key, shurl := shorturl.Shorten(internalReq.URL, shortURLAdress)
-
 	// Retrieve the user ID from the request context
 	userID, err := s.authenticate(ctx)
 	if err != nil {
@@ -121,12 +147,27 @@ func (s *UsersServer) ShortenJSON(ctx context.Context, req *pb.ShortenJSONReques
 		return nil, err
 	}
 
-	// Save the full URL to storage with the key received earlier
-	m, err := s.storage.InsertURL(key, models.DataURL{ShortURL: shurl, OriginalURL: internalReq.URL, UserID: userID})
+	// Apply the expiration time and the click limit of the link
+	data := models.DataURL{OriginalURL: internalReq.URL, UserID: userID}
+	if err := applyProtoLinkOptions(&data, req.GetOptions(), time.Now()); err != nil {
+		return nil, invalidOptionsStatus(err)
+	}
+
+	// Shorten the original URL or reserve the custom alias and save it to storage
+	m, err := s.shortener.insertURL(internalReq.Alias, data)
+	if st := invalidURLStatus("url", err); st != nil {
+		return nil, st
+	}
+	if isInvalidOptions(err) {
+		return nil, invalidOptionsStatus(err)
+	}
 	if err != nil {
 		if err == storage.ErrConflict {
 			// Respond with a Conflict status code for conflicts
 			return nil, status.Error(codes.AlreadyExists, "URL conflict")
+		} else if err == shorturl.ErrInvalidAlias {
+			// Respond with an Invalid Argument status code for an invalid alias
+			return nil, status.Error(codes.InvalidArgument, "invalid alias")
 		} else {
 			// Respond with a Bad Request status code for other errors
 			return nil, status.Error(codes.Internal, "error inserting URL into storage")
@@ -161,25 +202,30 @@ func (s *UsersServer) ShortenURL(ctx context.Context, req *pb.AddURLRequest) (*p
 	// Describe the logic for processing a URL shortening request
 	fullURL := req.GetFullurl()
 
-	// Get the address for the short link from the settings
-	shortURLAddress := s.options.ShortURLAdress()
-
-	// Shorten the URL
-	_, shortenedURL := shorturl.Shorten(fullURL, shortURLAddress)
-
 	// Write the data to the database
 	dataURL := models.DataURL{
-		ShortURL:    shortenedURL,
 		OriginalURL: fullURL,
 		UserID:      userID,
 	}
 
-	// Use the InsertURL method from the repository
-	_, err = s.storage.InsertURL(shortenedURL, dataURL)
+	// Apply the expiration time and the click limit of the link
+	if err := applyProtoLinkOptions(&dataURL, req.GetOptions(), time.Now()); err != nil {
+		return nil, invalidOptionsStatus(err)
+	}
+
+	// Shorten the URL and save it to storage
+	m, err := s.shortener.insertURL("", dataURL)
+	if st := invalidURLStatus("fullurl", err); st != nil {
+		return nil, st
+	}
+	if isInvalidOptions(err) {
+		return nil, invalidOptionsStatus(err)
+	}
 	if err != nil {
 		// Return an error to the client with an error code and an error message
 		return nil, status.Errorf(codes.Internal, "failed to save URL to storage: %v", err)
 	}
+	shortenedURL := m.ShortURL
 
 	// Return the response
 	response := &pb.AddURLResponse{
@@ -203,7 +249,8 @@ func (s *UsersServer) GetFullURL(ctx context.Context, req *pb.GetURLRequest) (*p
 		return nil, status.Error(codes.InvalidArgument, "empty key")
 	}
 
-	// Get the full URL from the storage
+	// Get the full URL from the storage, the key is looked up on the domain of the host
+	key = storage.DomainKey(s.domains.Serving(req.GetHost()), key)
 	data, err := s.storage.GetURL(key)
 
 	// Return a NotFound error code if the URL was not found or an error occurred
@@ -211,14 +258,119 @@ func (s *UsersServer) GetFullURL(ctx context.Context, req *pb.GetURLRequest) (*p
 		return nil, status.Error(codes.NotFound, "URL not found")
 	}
 
+	// Return a FailedPrecondition error code if the URL has been deleted or has expired
+	if data.DeletedFlag || data.Expired(time.Now()) {
+		return nil, status.Error(codes.FailedPrecondition, "URL is gone")
+	}
+
+	// Check the password of a protected URL, failed attempts are rate limited per link
+	if data.Protected() {
+		if req.GetPassword() == "" {
+			return nil, status.Error(codes.Unauthenticated, "password required")
+		}
+		if s.limiter.Blocked(key) {
+			return nil, status.Error(codes.ResourceExhausted, "too many password attempts")
+		}
+		if !linkpass.Check(data.PasswordHash, req.GetPassword()) {
+			s.limiter.Fail(key)
+			return nil, status.Error(codes.PermissionDenied, "wrong password")
+		}
+		s.limiter.Reset(key)
+	}
+
+	// Count the redirect of a click-limited URL, the link is gone once all clicks are used
+	if data.MaxClicks > 0 {
+		data, err = s.storage.UseClick(key)
+		if errors.Is(err, storage.ErrClicksExhausted) {
+			return nil, status.Error(codes.FailedPrecondition, "URL is gone")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Internal, "cannot count click")
+		}
+	}
+
+	// Choose the destination of the client
+	ip := s.clientIP(ctx, req.GetClientIp())
+	client := targeting.Client{UserAgent: req.GetUserAgent(), AcceptLanguage: req.GetAcceptLanguage(),
+		Referrer: req.GetReferrer(), Country: clientCountry(s.locator, ip)}
+	target, variant := route(data, client, int(req.GetVariant()))
+
+	// Count the redirect to a variant, the answer is given even if it is not counted
+	if variant != 0 {
+		if err = s.storage.AddVariantClick(key, variant); err != nil {
+			s.log.Info("cannot count variant click: ", zap.Error(err))
+		}
+	}
+
 	// Return the answer
 	response := &pb.GetURLResponse{
-		OriginalUrl: data.OriginalURL,
+		OriginalUrl:  data.OriginalURL,
+		RedirectCode: int32(redirectCode(data, s.options.RedirectCode())),
+		Location:     destination(data, target, req.GetQuery()),
+		Variant:      int32(variant),
 	}
 
+	// Record the click event of the resolution
+	s.recordClick(ctx, req, key, ip)
+
 	return response, nil
 }
 
+// GetQRCode implements a method for getting the QR code of a short URL from the Users protobuf service.
+// The image encodes the short URL and is sized, error-corrected and formatted by the request.
+// Like GetFullURL, it returns NotFound for an unknown URL and FailedPrecondition for a deleted,
+// expired or used up one.
+func (s *UsersServer) GetQRCode(ctx context.Context, req *pb.GetQRCodeRequest) (*pb.GetQRCodeResponse, error) {
+	// Extract the key from the request
+	key := req.GetKey()
+
+	// Return the InvalidArgument error code if the key is empty
+	if key == "" {
+		return nil, status.Error(codes.InvalidArgument, "empty key")
+	}
+
+	// Parse the options of the image, a zero size stands for the default one
+	var size string
+	if req.GetSize() != 0 {
+		size = strconv.Itoa(int(req.GetSize()))
+	}
+	opts, err := qr.ParseOptions(size, req.GetLevel(), req.GetFormat())
+	if err != nil {
+		st := status.New(codes.InvalidArgument, err.Error())
+		ds, derr := st.WithDetails(&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: qrOptionField(err), Description: err.Error()},
+			},
+		})
+		if derr != nil {
+			return nil, st.Err()
+		}
+		return nil, ds.Err()
+	}
+
+	// Get the URL from the storage, the key is looked up on the domain of the host
+	data, err := s.storage.GetURL(storage.DomainKey(s.domains.Serving(req.GetHost()), key))
+	if err != nil || data.OriginalURL == "" {
+		return nil, status.Error(codes.NotFound, "URL not found")
+	}
+
+	// Return a FailedPrecondition error code if the URL no longer redirects
+	if data.DeletedFlag || data.Expired(time.Now()) || data.ClicksExhausted() {
+		return nil, status.Error(codes.FailedPrecondition, "URL is gone")
+	}
+
+	image, err := qr.Render(data.ShortURL, opts)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "cannot render QR code")
+	}
+
+	return &pb.GetQRCodeResponse{
+		Image:       image,
+		ContentType: opts.ContentType(),
+		Etag:        qr.ETag(data.ShortURL, opts),
+	}, nil
+}
+
 // DeleteUserURLs implements a method for deleting user URLs from the Users protobuf service.
 func (s *UsersServer) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLsRequest) (*pb.DeleteUserURLsResponse, error) {
 	// Get a list of URL IDs from the request
@@ -241,6 +393,44 @@ func (s *UsersServer) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLs
 	return response, nil
 }
 
+// RestoreUserURLs implements a method for restoring deleted user URLs from the Users protobuf service.
+func (s *UsersServer) RestoreUserURLs(ctx context.Context, req *pb.RestoreUserURLsRequest) (*pb.RestoreUserURLsResponse, error) {
+	// Get the user ID from the context
+	userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Add tasks to worker for asynchronous restoration
+	s.worker.Restore(models.DeleteURL{UserID: userID, ShortURLs: req.GetUrls()})
+
+	// Return the answer
+	response := &pb.RestoreUserURLsResponse{
+		Key: userID,
+	}
+	return response, nil
+}
+
+// clientIP returns the IP address of the client: the address given in the request,
+// or the address of the peer.
+func (s *UsersServer) clientIP(ctx context.Context, ip string) string {
+	if ip != "" {
+		return ip
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return ""
+	}
+
+	return host
+}
+
 func (s *UsersServer) authenticate(ctx context.Context) (string, error) {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -269,27 +459,222 @@ func (s *UsersServer) GetUserURLs(ctx context.Context, req *pb.GetUserURLsReques
 		return nil, err
 	}
 
-	// Get URLs associated with the user from the storage
-	data := s.storage.GetUserURLs(userID)
+	// Parse the filters, the sort order and the page of the request
+	params := urlQueryParams{
+		tags:        req.GetTags(),
+		search:      req.GetSearch(),
+		createdFrom: req.GetCreatedFrom(),
+		createdTo:   req.GetCreatedTo(),
+		sort:        req.GetSort(),
+		order:       req.GetOrder(),
+		pageToken:   req.GetPageToken(),
+	}
+	if req.Deleted != nil {
+		params.deleted = strconv.FormatBool(req.GetDeleted())
+	}
+	if req.GetPageSize() != 0 {
+		params.pageSize = strconv.Itoa(int(req.GetPageSize()))
+	}
+	q, err := params.query()
+	if err != nil {
+		return nil, invalidQueryStatus(err)
+	}
+
+	// Get a page of the URLs associated with the user from the storage
+	data, next, err := s.storage.GetUserURLs(userID, q)
+	if errors.Is(err, storage.ErrInvalidPageToken) {
+		return nil, invalidQueryStatus(&queryError{field: "page_token", message: err.Error()})
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "error getting URLs from storage")
+	}
 
 	// Convert data to the format expected by the client
 	var userURLs []*pb.UserURL
 	for _, url := range data {
-		userURL := &pb.UserURL{
-			OriginalUrl: url.OriginalURL,
-			ShortUrl:    url.ShortURL,
-		}
-		userURLs = append(userURLs, userURL)
+		userURLs = append(userURLs, userURL(url))
 	}
 
 	// Formulate the response
 	response := &pb.GetUserURLsResponse{
-		Urls: userURLs,
+		Urls:          userURLs,
+		NextPageToken: next,
 	}
 
 	return response, nil
 }
 
+// SearchUserURLs implements the SearchUserURLs method from the URLService protobuf service.
+// It finds the URLs of the user whose key, original URL, host, tags or note contain all of
+// the words of the query, newest first.
+func (s *UsersServer) SearchUserURLs(ctx context.Context, req *pb.SearchUserURLsRequest) (*pb.SearchUserURLsResponse, error) {
+	// Get the user ID from the context
+	userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(req.GetQuery()) == "" {
+		return nil, invalidQueryStatus(&queryError{field: "query", message: "query is required"})
+	}
+	if req.GetLimit() < 0 || req.GetLimit() > storage.MaxPageSize {
+		return nil, invalidQueryStatus(&queryError{field: "limit",
+			message: fmt.Sprintf("limit must be between 1 and %d", storage.MaxPageSize)})
+	}
+
+	data, err := s.storage.SearchUserURLs(userID, req.GetQuery(), int(req.GetLimit()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "error searching URLs in storage")
+	}
+
+	response := &pb.SearchUserURLsResponse{}
+	for _, url := range data {
+		response.Urls = append(response.Urls, userURL(url))
+	}
+
+	return response, nil
+}
+
+// userURL converts a URL of a user to its protobuf message.
+func userURL(url models.DataURLite) *pb.UserURL {
+	res := &pb.UserURL{
+		OriginalUrl: url.OriginalURL,
+		ShortUrl:    url.ShortURL,
+		Tags:        url.Tags,
+		IsDeleted:   url.Deleted,
+		Note:        url.Note,
+		Domain:      url.Domain,
+	}
+	if url.CreatedAt != nil {
+		res.CreatedAt = url.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	for _, v := range url.Variants {
+		res.Variants = append(res.Variants, &pb.Variant{Url: v.URL, Weight: int32(v.Weight),
+			Clicks: int64(v.Clicks)})
+	}
+
+	return res
+}
+
+// UpdateURL implements the UpdateURL method from the URLService protobuf service.
+// It lets the owner of a short link change its original URL while keeping the key,
+// and replace its tags.
+func (s *UsersServer) UpdateURL(ctx context.Context, req *pb.UpdateURLRequest) (*pb.UpdateURLResponse, error) {
+	// Get the user ID from the context
+	userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The domain selects the link of the key on an additional domain
+	domain, err := s.domains.Resolve(req.GetDomain())
+	if err != nil {
+		return nil, invalidQueryStatus(&queryError{field: "domain", message: err.Error()})
+	}
+	key := storage.DomainKey(domain, req.GetKey())
+
+	// Change the original URL of the link, unless only the tags are changed
+	var m models.DataURL
+	if req.GetOriginalUrl() != "" || req.GetTags() == nil {
+		m, err = s.shortener.updateURL(key, userID, req.GetOriginalUrl())
+	}
+	if st := invalidURLStatus("original_url", err); st != nil {
+		return nil, st
+	}
+
+	// Replace the tags of the link
+	if err == nil && req.GetTags() != nil {
+		m, err = s.shortener.updateTags(key, userID, req.GetTags().GetTags())
+	}
+	if isInvalidOptions(err) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	switch {
+	case err == nil:
+	case errors.Is(err, storage.ErrNotFound):
+		return nil, status.Error(codes.NotFound, "URL not found")
+	case errors.Is(err, storage.ErrNotOwner):
+		return nil, status.Error(codes.PermissionDenied, "URL belongs to another user")
+	case errors.Is(err, storage.ErrConflict):
+		return nil, status.Errorf(codes.AlreadyExists, "URL is already shortened as %s", m.ShortURL)
+	default:
+		return nil, status.Error(codes.Internal, "error updating URL in storage")
+	}
+
+	// Return the updated link
+	return &pb.UpdateURLResponse{
+		Url: &pb.UserURL{OriginalUrl: m.OriginalURL, ShortUrl: m.ShortURL, Tags: m.Tags, Domain: m.Domain},
+	}, nil
+}
+
+// GetURLStats implements the GetURLStats method from the URLService protobuf service.
+// It returns the click statistics of the user's link over the requested range,
+// and FailedPrecondition for a deleted link.
+func (s *UsersServer) GetURLStats(ctx context.Context, req *pb.GetURLStatsRequest) (*pb.GetURLStatsResponse, error) {
+	// Get the user ID from the context
+	userID, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params := statsQueryParams{from: req.GetFrom(), to: req.GetTo(), interval: req.GetInterval()}
+	if req.GetTop() != 0 {
+		params.top = strconv.Itoa(int(req.GetTop()))
+	}
+	q, err := params.query(time.Now())
+	if err != nil {
+		return nil, invalidQueryStatus(err)
+	}
+
+	// The domain selects the link of the key on an additional domain
+	domain, err := s.domains.Resolve(req.GetDomain())
+	if err != nil {
+		return nil, invalidQueryStatus(&queryError{field: "domain", message: err.Error()})
+	}
+
+	stats, err := s.storage.GetURLStats(storage.DomainKey(domain, req.GetKey()), userID, q)
+	switch {
+	case err == nil:
+	case errors.Is(err, storage.ErrNotFound):
+		return nil, status.Error(codes.NotFound, "URL not found")
+	case errors.Is(err, storage.ErrNotOwner):
+		return nil, status.Error(codes.PermissionDenied, "URL belongs to another user")
+	case errors.Is(err, storage.ErrGone):
+		return nil, status.Error(codes.FailedPrecondition, "URL is gone")
+	default:
+		return nil, status.Error(codes.Internal, "error getting URL stats from storage")
+	}
+
+	response := &pb.GetURLStatsResponse{
+		From:          stats.From.Format(time.RFC3339),
+		To:            stats.To.Format(time.RFC3339),
+		Interval:      stats.Interval,
+		Clicks:        int64(stats.Clicks),
+		Unique:        int64(stats.Unique),
+		UniqueError:   stats.UniqueError,
+		TopReferrers:  statsCounts(stats.Referrers),
+		TopCountries:  statsCounts(stats.Countries),
+		TopUserAgents: statsCounts(stats.UserAgents),
+	}
+	for _, b := range stats.Buckets {
+		response.Buckets = append(response.Buckets, &pb.StatsBucket{Start: b.Start.Format(time.RFC3339),
+			Clicks: int64(b.Clicks), Unique: int64(b.Unique)})
+	}
+
+	return response, nil
+}
+
+// statsCounts converts the top values of the URL stats to their protobuf messages.
+func statsCounts(counts []models.StatsCount) []*pb.StatsCount {
+	res := make([]*pb.StatsCount, 0, len(counts))
+	for _, c := range counts {
+		res = append(res, &pb.StatsCount{Value: c.Value, Clicks: int64(c.Clicks)})
+	}
+
+	return res
+}
+
 // HealthCheck checks storage availability and returns the appropriate status.
 func (s *UsersServer) HealthCheck(ctx context.Context, req *pb.HealthCheckRequest) (*pb.HealthCheckResponse, error) {
 	if s.storage.GetBaseConnection() {
@@ -368,3 +753,102 @@ func (s *UsersServer) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 	// If the password is incorrect, return an authentication error
 	return nil, status.Errorf(codes.Unauthenticated, "Incorrect email/password")
 }
+
+// invalidURLStatus converts a URL validation error to an InvalidArgument status with
+// the bad request and error info details. The field names the request field holding
+// the URL, batch items are prefixed with their index. It returns nil if err is not
+// a URL validation error.
+func invalidURLStatus(field string, err error) error {
+	var ve *shorturl.ValidationError
+	if !errors.As(err, &ve) {
+		return nil
+	}
+
+	var be *batchItemError
+	if errors.As(err, &be) {
+		field = fmt.Sprintf("urls[%d].%s", be.index, field)
+	}
+
+	st := status.New(codes.InvalidArgument, ve.Error())
+	ds, derr := st.WithDetails(
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: ve.Message},
+			},
+		},
+		&errdetails.ErrorInfo{Reason: strings.ToUpper(ve.Reason), Domain: "tinyurl"},
+	)
+	if derr != nil {
+		return st.Err()
+	}
+
+	return ds.Err()
+}
+
+// applyProtoLinkOptions sets the link options described by the protobuf options on data.
+// It returns a *linkOptionError if the options are invalid.
+func applyProtoLinkOptions(data *models.DataURL, opts *pb.LinkOptions, now time.Time) error {
+	lo, err := linkOptions(opts)
+	if err != nil {
+		return err
+	}
+
+	return applyLinkOptions(data, lo, now)
+}
+
+// isInvalidOptions reports whether err is an invalid link option error.
+func isInvalidOptions(err error) bool {
+	var oe *linkOptionError
+	return errors.As(err, &oe)
+}
+
+// invalidQueryStatus converts an invalid user URLs query error to an InvalidArgument status
+// with the bad request details.
+func invalidQueryStatus(err error) error {
+	var qe *queryError
+	if !errors.As(err, &qe) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	st := status.New(codes.InvalidArgument, qe.Error())
+	ds, derr := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: qe.field, Description: qe.message},
+		},
+	})
+	if derr != nil {
+		return st.Err()
+	}
+
+	return ds.Err()
+}
+
+// invalidOptionsStatus converts an invalid link option error to an InvalidArgument status
+// with the bad request and error info details. Batch items are prefixed with their index.
+func invalidOptionsStatus(err error) error {
+	var oe *linkOptionError
+	if !errors.As(err, &oe) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	field := "options." + oe.field
+	var be *batchItemError
+	if errors.As(err, &be) {
+		field = fmt.Sprintf("urls[%d].%s", be.index, field)
+	}
+
+	st := status.New(codes.InvalidArgument, oe.Error())
+	ds, derr := st.WithDetails(
+		&errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: oe.message},
+			},
+		},
+		&errdetails.ErrorInfo{Reason: "INVALID_OPTIONS", Domain: "tinyurl"},
+	)
+	if derr != nil {
+		return st.Err()
+	}
+
+	return ds.Err()
+}
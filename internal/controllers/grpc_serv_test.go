@@ -3,16 +3,26 @@ package controllers_test
 import (
 	"context"
 	"errors"
+	"net"
 	"net/http"
+	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	authz "github.com/wurt83ow/tinyurl/internal/authorization"
 	"github.com/wurt83ow/tinyurl/internal/controllers"
 	pb "github.com/wurt83ow/tinyurl/internal/controllers/proto"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
+	"github.com/wurt83ow/tinyurl/internal/storage"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 )
 
 type mockOptions struct {
@@ -20,6 +30,9 @@ type mockOptions struct {
 	runAddrFunc         func() string
 	trustedSubnetFunc   func() string
 	shortURLAddressFunc func() string
+	domainsFunc         func() string
+	redirectCodeFunc    func() int
+	interstitialFunc    func() bool
 }
 
 func (m *mockOptions) ParseFlags() {
@@ -38,6 +51,27 @@ func (m *mockOptions) ShortURLAdress() string {
 	return m.shortURLAddressFunc()
 }
 
+func (m *mockOptions) Domains() string {
+	if m.domainsFunc == nil {
+		return ""
+	}
+	return m.domainsFunc()
+}
+
+func (m *mockOptions) RedirectCode() int {
+	if m.redirectCodeFunc == nil {
+		return 0
+	}
+	return m.redirectCodeFunc()
+}
+
+func (m *mockOptions) InterstitialExternal() bool {
+	if m.interstitialFunc == nil {
+		return false
+	}
+	return m.interstitialFunc()
+}
+
 type mockLog struct {
 	infoFunc func(msg string, fields ...zapcore.Field)
 }
@@ -47,7 +81,8 @@ func (m *mockLog) Info(msg string, fields ...zapcore.Field) {
 }
 
 type mockWorker struct {
-	addFunc func(task models.DeleteURL)
+	addFunc     func(task models.DeleteURL)
+	restoreFunc func(task models.DeleteURL)
 }
 
 func (m *mockWorker) Add(task models.DeleteURL) {
@@ -56,6 +91,12 @@ func (m *mockWorker) Add(task models.DeleteURL) {
 	}
 }
 
+func (m *mockWorker) Restore(task models.DeleteURL) {
+	if m.restoreFunc != nil {
+		m.restoreFunc(task)
+	}
+}
+
 type mockAuthz struct {
 	authCookieFunc         func(jwtToken, userID string) *http.Cookie
 	createJWTTokenFunc     func(userID string) string
@@ -86,13 +127,21 @@ func (m *mockAuthz) JWTAuthzMiddleware(storage authz.Storage, log authz.Log) fun
 
 type mockStorage struct {
 	insertURLFunc         func(string, models.DataURL) (models.DataURL, error)
+	insertAliasFunc       func(string, models.DataURL) (models.DataURL, error)
 	insertBatchFunc       func(map[string]models.DataURL) error
 	getURLFunc            func(string) (models.DataURL, error)
-	getUserURLsFunc       func(string) []models.DataURLite
+	useClickFunc          func(string) (models.DataURL, error)
+	addVariantClickFunc   func(string, int) error
+	updateURLFunc         func(string, string, string) (models.DataURL, error)
+	updateTagsFunc        func(string, string, []string) (models.DataURL, error)
+	getUserURLsFunc       func(string, storage.URLQuery) ([]models.DataURLite, string, error)
+	searchUserURLsFunc    func(string, string, int) ([]models.DataURLite, error)
+	getURLStatsFunc       func(string, string, storage.StatsQuery) (models.URLStats, error)
 	deleteUserURLsFunc    func(string, []string)
 	deleteURLsFunc        func(...models.DeleteURL) error
 	getBaseConnectionFunc func() bool
 	getUserFunc           func(string) (models.DataUser, error)
+	getUserByIDFunc       func(string) (models.DataUser, error)
 	getUsersCountFunc     func() (int, error)
 	getURLsCountFunc      func() (int, error)
 	insertUserFunc        func(string, models.DataUser) (models.DataUser, error)
@@ -105,6 +154,10 @@ func (m *mockStorage) InsertURL(key string, data models.DataURL) (models.DataURL
 	return m.insertURLFunc(key, data)
 }
 
+func (m *mockStorage) InsertAlias(key string, data models.DataURL) (models.DataURL, error) {
+	return m.insertAliasFunc(key, data)
+}
+
 func (m *mockStorage) InsertBatch(data map[string]models.DataURL) error {
 	return m.insertBatchFunc(data)
 }
@@ -113,8 +166,32 @@ func (m *mockStorage) GetURL(key string) (models.DataURL, error) {
 	return m.getURLFunc(key)
 }
 
-func (m *mockStorage) GetUserURLs(userID string) []models.DataURLite {
-	return m.getUserURLsFunc(userID)
+func (m *mockStorage) UpdateURL(key, userID, originalURL string) (models.DataURL, error) {
+	return m.updateURLFunc(key, userID, originalURL)
+}
+
+func (m *mockStorage) UseClick(key string) (models.DataURL, error) {
+	return m.useClickFunc(key)
+}
+
+func (m *mockStorage) AddVariantClick(key string, variant int) error {
+	return m.addVariantClickFunc(key, variant)
+}
+
+func (m *mockStorage) UpdateTags(key, userID string, tags []string) (models.DataURL, error) {
+	return m.updateTagsFunc(key, userID, tags)
+}
+
+func (m *mockStorage) GetUserURLs(userID string, q storage.URLQuery) ([]models.DataURLite, string, error) {
+	return m.getUserURLsFunc(userID, q)
+}
+
+func (m *mockStorage) SearchUserURLs(userID string, query string, limit int) ([]models.DataURLite, error) {
+	return m.searchUserURLsFunc(userID, query, limit)
+}
+
+func (m *mockStorage) GetURLStats(k string, userID string, q storage.StatsQuery) (models.URLStats, error) {
+	return m.getURLStatsFunc(k, userID, q)
 }
 
 func (m *mockStorage) DeleteUserURLs(userID string, shortURLs []string) {
@@ -133,6 +210,10 @@ func (m *mockStorage) GetUser(email string) (models.DataUser, error) {
 	return m.getUserFunc(email)
 }
 
+func (m *mockStorage) GetUserByID(id string) (models.DataUser, error) {
+	return m.getUserByIDFunc(id)
+}
+
 func (m *mockStorage) GetUsersCount() (int, error) {
 	return m.getUsersCountFunc()
 }
@@ -173,6 +254,12 @@ func NewTestContext(t *testing.T) *TestContext {
 		insertURLFunc: func(key string, data models.DataURL) (models.DataURL, error) {
 			return data, nil
 		},
+		insertAliasFunc: func(key string, data models.DataURL) (models.DataURL, error) {
+			if key == "taken-alias" {
+				return data, storage.ErrConflict
+			}
+			return data, nil
+		},
 		deleteURLsFunc: func(urls ...models.DeleteURL) error {
 			// Code for checking and/or emulating method behavior DeleteURLs
 			return nil
@@ -255,7 +342,12 @@ func NewTestContext(t *testing.T) *TestContext {
 		},
 	}
 
-	server := controllers.NewUsersServer(mockStorage, mockOptions, mockLog, mockWorker, mockAuthz)
+	keygen := shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz")
+
+	validator := shorturl.NewValidator("", 0, false)
+
+	server := controllers.NewUsersServer(mockStorage, mockOptions, mockLog, mockWorker, mockAuthz,
+		keygen, &shorturl.Normalizer{}, validator, nil, nil, nil)
 
 	return &TestContext{
 		t:           t,
@@ -281,6 +373,53 @@ func TestShortenJSON(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "SuccessfulShortenJSONWithAlias",
+			request: &pb.ShortenJSONRequest{
+				Url:   "http://example.com/sale",
+				Alias: "spring-sale",
+			},
+			expectErr: false,
+		},
+		{
+			name: "InvalidAlias",
+			request: &pb.ShortenJSONRequest{
+				Url:   "http://example.com/sale",
+				Alias: "a/b",
+			},
+			expectErr: true,
+		},
+		{
+			name: "InvalidURL",
+			request: &pb.ShortenJSONRequest{
+				Url: "not a url",
+			},
+			expectErr: true,
+		},
+		{
+			name: "SuccessfulShortenJSONWithTTL",
+			request: &pb.ShortenJSONRequest{
+				Url:     "http://example.com/ttl",
+				Options: &pb.LinkOptions{Ttl: "24h"},
+			},
+			expectErr: false,
+		},
+		{
+			name: "InvalidExpiresAt",
+			request: &pb.ShortenJSONRequest{
+				Url:     "http://example.com/ttl",
+				Options: &pb.LinkOptions{ExpiresAt: "tomorrow"},
+			},
+			expectErr: true,
+		},
+		{
+			name: "TakenAlias",
+			request: &pb.ShortenJSONRequest{
+				Url:   "http://example.com/sale",
+				Alias: "taken-alias",
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -317,6 +456,13 @@ func TestShortenURL(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			name: "JavascriptURL",
+			request: &pb.AddURLRequest{
+				Fullurl: "javascript:alert(1)",
+			},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -337,6 +483,64 @@ func TestShortenURL(t *testing.T) {
 	}
 }
 
+func TestShortenBatchInvalidURL(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := testContext.Server.ShortenBatch(ctx, &pb.ShortenBatchRequest{
+		Urls: []*pb.UrlToShorten{
+			{Uuid: "1", OriginalUrl: "http://example.com"},
+			{Uuid: "2", OriginalUrl: "ftp://example.org"},
+		},
+	})
+	assert.Nil(t, resp)
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	// The details must point to the rejected batch item
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "urls[1].original_url", violations[0].GetField())
+	}
+}
+
+func TestShortenBatchInvalidOptions(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := testContext.Server.ShortenBatch(ctx, &pb.ShortenBatchRequest{
+		Urls: []*pb.UrlToShorten{
+			{Uuid: "1", OriginalUrl: "http://example.com"},
+			{Uuid: "2", OriginalUrl: "http://example.org", Options: &pb.LinkOptions{Ttl: "-1h"}},
+		},
+	})
+	assert.Nil(t, resp)
+
+	st, ok := status.FromError(err)
+	assert.True(t, ok)
+	assert.Equal(t, codes.InvalidArgument, st.Code())
+
+	// The details must point to the rejected batch item
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, d := range st.Details() {
+		if br, ok := d.(*errdetails.BadRequest); ok {
+			violations = br.GetFieldViolations()
+		}
+	}
+	if assert.Len(t, violations, 1) {
+		assert.Equal(t, "urls[1].options.ttl", violations[0].GetField())
+	}
+}
+
 func TestGetUserURLs(t *testing.T) {
 	testContext := NewTestContext(t)
 
@@ -348,9 +552,10 @@ func TestGetUserURLs(t *testing.T) {
 	}
 
 	// Set up the mockStorage behavior
-	testContext.MockStorage.getUserURLsFunc = func(userID string) []models.DataURLite {
+	testContext.MockStorage.getUserURLsFunc = func(userID string, q storage.URLQuery) ([]models.DataURLite, string, error) {
 		assert.Equal(t, mockUserID, userID)
-		return mockUserURLs
+		assert.Equal(t, storage.URLQuery{}, q)
+		return mockUserURLs, "", nil
 	}
 
 	// Perform the test
@@ -361,7 +566,119 @@ func TestGetUserURLs(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, len(mockUserURLs), len(resp.Urls))
+	assert.Empty(t, resp.GetNextPageToken())
 	// Additional assertions based on your specific response structure
+
+	// The request is converted to a query of the storage, the tags are normalized
+	created := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	deleted := false
+	testContext.MockStorage.getUserURLsFunc = func(userID string, q storage.URLQuery) ([]models.DataURLite, string, error) {
+		from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, storage.URLQuery{Tags: []string{"marketing", "q3"}, Search: "example", Deleted: &deleted,
+			CreatedFrom: &from, Sort: storage.SortKey, Desc: true, PageSize: 10, PageToken: "token"}, q)
+		return []models.DataURLite{{ShortURL: "mockShortURL1", OriginalURL: "http://example1.com", CreatedAt: &created,
+			LinkOptions: models.LinkOptions{Tags: []string{"marketing", "q3"}}}}, "next", nil
+	}
+
+	resp, err = testContext.Server.GetUserURLs(ctx, &pb.GetUserURLsRequest{Tags: []string{"Marketing", " q3"},
+		Search: "example", Deleted: &deleted, CreatedFrom: "2026-10-01T00:00:00Z", Sort: "key", Order: "desc",
+		PageSize: 10, PageToken: "token"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, resp.GetUrls(), 1) {
+		assert.Equal(t, []string{"marketing", "q3"}, resp.GetUrls()[0].GetTags())
+		assert.Equal(t, "2026-10-01T12:00:00Z", resp.GetUrls()[0].GetCreatedAt())
+	}
+	assert.Equal(t, "next", resp.GetNextPageToken())
+
+	// Invalid parameters and page tokens are rejected
+	testContext.MockStorage.getUserURLsFunc = func(userID string, q storage.URLQuery) ([]models.DataURLite, string, error) {
+		return nil, "", storage.ErrInvalidPageToken
+	}
+
+	for _, req := range []*pb.GetUserURLsRequest{{Sort: "size"}, {PageSize: -1}, {CreatedTo: "yesterday"}, {PageToken: "bad"}} {
+		_, err = testContext.Server.GetUserURLs(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
+}
+
+func TestSearchUserURLs(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.searchUserURLsFunc = func(userID string, query string, limit int) ([]models.DataURLite, error) {
+		assert.Equal(t, "mockUserID", userID)
+		assert.Equal(t, "autumn sale", query)
+		assert.Equal(t, 5, limit)
+		return []models.DataURLite{{ShortURL: "mockShortURL1", OriginalURL: "http://example1.com/sale",
+			LinkOptions: models.LinkOptions{Note: "Autumn campaign"}}}, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := testContext.Server.SearchUserURLs(ctx, &pb.SearchUserURLsRequest{Query: "autumn sale", Limit: 5})
+
+	assert.NoError(t, err)
+	if assert.Len(t, resp.GetUrls(), 1) {
+		assert.Equal(t, "Autumn campaign", resp.GetUrls()[0].GetNote())
+	}
+
+	// The query is required and the limit is bounded
+	for _, req := range []*pb.SearchUserURLsRequest{{Query: " "}, {Query: "sale", Limit: 5000}} {
+		_, err = testContext.Server.SearchUserURLs(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
+}
+
+func TestGetURLStats(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	testContext.MockStorage.getURLStatsFunc = func(k string, userID string, q storage.StatsQuery) (models.URLStats, error) {
+		if k == "deletedKey" {
+			return models.URLStats{}, storage.ErrGone
+		}
+		if k != "ownKey" {
+			return models.URLStats{}, storage.ErrNotOwner
+		}
+		assert.Equal(t, "mockUserID", userID)
+		assert.Equal(t, storage.IntervalDay, q.Interval)
+		assert.Equal(t, day, q.From)
+		assert.Equal(t, day.Add(48*time.Hour), q.To)
+		return models.URLStats{From: q.From, To: q.To, Interval: q.Interval, Clicks: 3, Unique: 2,
+			Buckets:   []models.StatsBucket{{Start: day, Clicks: 3, Unique: 2}, {Start: day.Add(24 * time.Hour)}},
+			Referrers: []models.StatsCount{{Value: "https://news.example.org/", Clicks: 2}}}, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := testContext.Server.GetURLStats(ctx, &pb.GetURLStatsRequest{Key: "ownKey",
+		From: "2026-10-16T09:30:00Z", To: "2026-10-17T12:00:00Z"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), resp.GetClicks())
+	assert.Equal(t, int64(2), resp.GetUnique())
+	assert.Equal(t, "2026-10-16T00:00:00Z", resp.GetFrom())
+	if assert.Len(t, resp.GetBuckets(), 2) {
+		assert.Equal(t, "2026-10-17T00:00:00Z", resp.GetBuckets()[1].GetStart())
+	}
+	if assert.Len(t, resp.GetTopReferrers(), 1) {
+		assert.Equal(t, int64(2), resp.GetTopReferrers()[0].GetClicks())
+	}
+
+	// Only the owner gets the stats of the link
+	_, err = testContext.Server.GetURLStats(ctx, &pb.GetURLStatsRequest{Key: "otherKey"})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// A deleted link is gone
+	_, err = testContext.Server.GetURLStats(ctx, &pb.GetURLStatsRequest{Key: "deletedKey"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	// Invalid parameters are rejected
+	for _, req := range []*pb.GetURLStatsRequest{{Key: "ownKey", Interval: "week"}, {Key: "ownKey", Top: 500},
+		{Key: "ownKey", From: "2026-10-17T00:00:00Z", To: "2026-10-16T00:00:00Z"}, {Key: "ownKey", Domain: "example.org"}} {
+		_, err = testContext.Server.GetURLStats(ctx, req)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "request %v", req)
+	}
 }
 
 func TestShortenBatch(t *testing.T) {
@@ -458,17 +775,22 @@ func TestHealthCheck(t *testing.T) {
 
 func TestGetFullURL(t *testing.T) {
 	testContext := NewTestContext(t)
+	hash, err := linkpass.Hash("s3cret")
+	assert.NoError(t, err)
+
 	testCases := []struct {
-		name        string
-		request     *pb.GetURLRequest
-		expectErr   bool
-		expectedURL string
+		name         string
+		request      *pb.GetURLRequest
+		expectErr    bool
+		expectedURL  string
+		expectedCode int32
 	}{
 		{
-			name:        "ValidKey",
-			request:     &pb.GetURLRequest{Key: "validKey"},
-			expectErr:   false,
-			expectedURL: "http://example.com",
+			name:         "ValidKey",
+			request:      &pb.GetURLRequest{Key: "validKey"},
+			expectErr:    false,
+			expectedURL:  "http://example.com",
+			expectedCode: http.StatusTemporaryRedirect,
 		},
 		{
 			name:      "EmptyKey",
@@ -480,6 +802,47 @@ func TestGetFullURL(t *testing.T) {
 			request:   &pb.GetURLRequest{Key: "nonExistentKey"},
 			expectErr: true,
 		},
+		{
+			name:      "ExpiredKey",
+			request:   &pb.GetURLRequest{Key: "expiredKey"},
+			expectErr: true,
+		},
+		{
+			name:      "ProtectedKeyWithoutPassword",
+			request:   &pb.GetURLRequest{Key: "protectedKey"},
+			expectErr: true,
+		},
+		{
+			name:      "ProtectedKeyWrongPassword",
+			request:   &pb.GetURLRequest{Key: "protectedKey", Password: "wrong"},
+			expectErr: true,
+		},
+		{
+			name:         "ProtectedKey",
+			request:      &pb.GetURLRequest{Key: "protectedKey", Password: "s3cret"},
+			expectErr:    false,
+			expectedURL:  "http://example.com",
+			expectedCode: http.StatusTemporaryRedirect,
+		},
+		{
+			name:         "LimitedKey",
+			request:      &pb.GetURLRequest{Key: "limitedKey"},
+			expectErr:    false,
+			expectedURL:  "http://example.com",
+			expectedCode: http.StatusTemporaryRedirect,
+		},
+		{
+			name:         "PermanentKey",
+			request:      &pb.GetURLRequest{Key: "permanentKey"},
+			expectErr:    false,
+			expectedURL:  "http://example.com",
+			expectedCode: http.StatusPermanentRedirect,
+		},
+		{
+			name:      "ExhaustedKey",
+			request:   &pb.GetURLRequest{Key: "exhaustedKey"},
+			expectErr: true,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -489,10 +852,32 @@ func TestGetFullURL(t *testing.T) {
 				if key == "validKey" {
 					return models.DataURL{OriginalURL: "http://example.com"}, nil
 				}
+				if key == "expiredKey" {
+					past := time.Now().Add(-time.Minute)
+					return models.DataURL{OriginalURL: "http://example.com", ExpiresAt: &past}, nil
+				}
+				if key == "protectedKey" {
+					return models.DataURL{OriginalURL: "http://example.com", PasswordHash: hash}, nil
+				}
+				if key == "permanentKey" {
+					return models.DataURL{OriginalURL: "http://example.com", RedirectCode: http.StatusPermanentRedirect}, nil
+				}
+				if key == "limitedKey" || key == "exhaustedKey" {
+					return models.DataURL{OriginalURL: "http://example.com", MaxClicks: 1}, nil
+				}
 				// Return an error if the key does not exist or other scenarios
 				return models.DataURL{}, errors.New("URL not found")
 			}
 
+			// Set up a function to emulate the behavior of the method UseClick
+			testContext.MockStorage.useClickFunc = func(key string) (models.DataURL, error) {
+				data := models.DataURL{OriginalURL: "http://example.com", MaxClicks: 1, Clicks: 1}
+				if key == "exhaustedKey" {
+					return data, storage.ErrClicksExhausted
+				}
+				return data, nil
+			}
+
 			// Pass the token to the context for authentication
 			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
 			resp, err := testContext.Server.GetFullURL(ctx, tc.request)
@@ -505,11 +890,304 @@ func TestGetFullURL(t *testing.T) {
 				assert.NotNil(t, resp)
 				// Check the expected URL in the response
 				assert.Equal(t, tc.expectedURL, resp.OriginalUrl)
+				// Check the redirect code that applies to the URL
+				assert.Equal(t, tc.expectedCode, resp.RedirectCode)
 			}
 		})
 	}
 }
 
+func TestGetFullURLLocation(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		return models.DataURL{OriginalURL: "http://example.com/a?id=7", QueryPolicy: shorturl.QueryMerge}, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+	resp, err := testContext.Server.GetFullURL(ctx, &pb.GetURLRequest{Key: "mergeKey", Query: "ref=newsletter"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/a?id=7", resp.GetOriginalUrl())
+	assert.Equal(t, "http://example.com/a?id=7&ref=newsletter", resp.GetLocation())
+
+	// The targeting rules of the link are matched against the client
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		return models.DataURL{OriginalURL: "http://example.com/",
+			Rules: []models.Rule{{Language: "de", URL: "http://example.com/de"}}}, nil
+	}
+
+	resp, err = testContext.Server.GetFullURL(ctx, &pb.GetURLRequest{Key: "ruleKey", AcceptLanguage: "de-DE,en;q=0.5"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/de", resp.GetLocation())
+}
+
+func TestGetFullURLVariant(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		return models.DataURL{OriginalURL: "http://example.com/", Sticky: true,
+			Variants: []models.Variant{{URL: "http://example.com/a", Weight: 1}, {URL: "http://example.com/b", Weight: 1}}}, nil
+	}
+
+	var counted []int
+	testContext.MockStorage.addVariantClickFunc = func(key string, variant int) error {
+		counted = append(counted, variant)
+		return nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	// The client sticks to the variant it was redirected to before
+	resp, err := testContext.Server.GetFullURL(ctx, &pb.GetURLRequest{Key: "splitKey", Variant: 2})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://example.com/b", resp.GetLocation())
+	assert.Equal(t, int32(2), resp.GetVariant())
+
+	// A new client is redirected to one of the variants
+	resp, err = testContext.Server.GetFullURL(ctx, &pb.GetURLRequest{Key: "splitKey"})
+
+	assert.NoError(t, err)
+	assert.Contains(t, []int32{1, 2}, resp.GetVariant())
+	assert.Equal(t, []int{2, int(resp.GetVariant())}, counted)
+}
+
+// clickRecorder records the click events in memory.
+type clickRecorder struct {
+	events []models.ClickEvent
+}
+
+func (c *clickRecorder) Record(e models.ClickEvent) {
+	c.events = append(c.events, e)
+}
+
+func TestGetFullURLRecordClick(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		return models.DataURL{OriginalURL: "http://example.com/"}, nil
+	}
+
+	clicks := &clickRecorder{}
+	server := controllers.NewUsersServer(testContext.MockStorage, testContext.MockOptions, &mockLog{
+		infoFunc: func(msg string, fields ...zapcore.Field) {},
+	}, testContext.MockWorker, testContext.MockAuthz, shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"),
+		&shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, clicks)
+
+	// The client is the peer, the User-Agent and the user are the ones of the metadata
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("203.0.113.7"), Port: 50051}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", testContext.FakeToken, "user-agent", "grpc-go/1.0"))
+
+	_, err := server.GetFullURL(ctx, &pb.GetURLRequest{Key: "someKey", Referrer: "https://news.example.com/"})
+	assert.NoError(t, err)
+
+	// The User-Agent of the request is used over the one of the metadata
+	_, err = server.GetFullURL(ctx, &pb.GetURLRequest{Key: "someKey", UserAgent: "Mozilla/5.0"})
+	assert.NoError(t, err)
+
+	// An anonymous client with no peer is recorded too
+	_, err = server.GetFullURL(context.Background(), &pb.GetURLRequest{Key: "someKey"})
+	assert.NoError(t, err)
+
+	if assert.Len(t, clicks.events, 3) {
+		e := clicks.events[0]
+		assert.Equal(t, "someKey", e.Key)
+		assert.Equal(t, "203.0.113.0", e.IP)
+		assert.Equal(t, "grpc-go/1.0", e.UserAgent)
+		assert.Equal(t, "https://news.example.com/", e.Referrer)
+		assert.Equal(t, "mockUserID", e.UserID)
+		assert.False(t, e.Time.IsZero())
+
+		assert.Equal(t, "Mozilla/5.0", clicks.events[1].UserAgent)
+
+		assert.Equal(t, models.ClickEvent{Time: clicks.events[2].Time, Key: "someKey"}, clicks.events[2])
+	}
+}
+
+func TestGetFullURLDomains(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	// The server has an additional branded domain
+	testContext.MockOptions.shortURLAddressFunc = func() string {
+		return "http://localhost:8080/"
+	}
+	testContext.MockOptions.domainsFunc = func() string {
+		return "https://go.example.com/"
+	}
+	server := controllers.NewUsersServer(testContext.MockStorage, testContext.MockOptions, &mockLog{
+		infoFunc: func(msg string, fields ...zapcore.Field) {},
+	}, testContext.MockWorker, testContext.MockAuthz, shorturl.NewHashGenerator("abcdefghijklmnopqrstuvwxyz"),
+		&shorturl.Normalizer{}, shorturl.NewValidator("", 0, false), nil, nil, nil)
+
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		return models.DataURL{OriginalURL: "http://example.com/" + key}, nil
+	}
+
+	// The key is looked up on the domain of the host
+	for host, want := range map[string]string{
+		"":                   "http://example.com/promo",
+		"localhost:8080":     "http://example.com/promo",
+		"go.example.com:443": "http://example.com/go.example.com/promo",
+	} {
+		resp, err := server.GetFullURL(context.Background(), &pb.GetURLRequest{Key: "promo", Host: host})
+
+		assert.NoError(t, err)
+		assert.Equal(t, want, resp.GetOriginalUrl(), host)
+	}
+
+	// The links are shortened and updated on the configured domains only
+	var inserted string
+	testContext.MockStorage.insertURLFunc = func(key string, data models.DataURL) (models.DataURL, error) {
+		inserted = key
+		return data, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := server.ShortenURL(ctx, &pb.AddURLRequest{Fullurl: "http://example.com/",
+		Options: &pb.LinkOptions{Domain: "go.example.com"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "https://go.example.com/"+path.Base(inserted), resp.GetShurl())
+	assert.Equal(t, "go.example.com/"+path.Base(inserted), inserted)
+
+	_, err = server.ShortenURL(ctx, &pb.AddURLRequest{Fullurl: "http://example.com/",
+		Options: &pb.LinkOptions{Domain: "example.org"}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.UpdateURL(ctx, &pb.UpdateURLRequest{Key: "promo", OriginalUrl: "http://example.com/",
+		Domain: "example.org"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetQRCode(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.getURLFunc = func(key string) (models.DataURL, error) {
+		switch key {
+		case "liveKey":
+			return models.DataURL{ShortURL: "http://localhost:8080/liveKey", OriginalURL: "http://example.com/"}, nil
+		case "deletedKey":
+			return models.DataURL{ShortURL: "http://localhost:8080/deletedKey", OriginalURL: "http://example.com/",
+				DeletedFlag: true}, nil
+		}
+		return models.DataURL{}, storage.ErrNotFound
+	}
+
+	testCases := []struct {
+		name     string
+		request  *pb.GetQRCodeRequest
+		wantCode codes.Code
+		wantType string
+	}{
+		{name: "PNG", request: &pb.GetQRCodeRequest{Key: "liveKey"}, wantCode: codes.OK, wantType: "image/png"},
+		{name: "SVG", request: &pb.GetQRCodeRequest{Key: "liveKey", Size: 128, Level: "H", Format: "svg"},
+			wantCode: codes.OK, wantType: "image/svg+xml"},
+		{name: "EmptyKey", request: &pb.GetQRCodeRequest{}, wantCode: codes.InvalidArgument},
+		{name: "InvalidSize", request: &pb.GetQRCodeRequest{Key: "liveKey", Size: 1}, wantCode: codes.InvalidArgument},
+		{name: "NotFound", request: &pb.GetQRCodeRequest{Key: "missingKey"}, wantCode: codes.NotFound},
+		{name: "Gone", request: &pb.GetQRCodeRequest{Key: "deletedKey"}, wantCode: codes.FailedPrecondition},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := testContext.Server.GetQRCode(context.Background(), tc.request)
+
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if tc.wantCode == codes.OK {
+				assert.Equal(t, tc.wantType, resp.GetContentType())
+				assert.NotEmpty(t, resp.GetImage())
+				assert.NotEmpty(t, resp.GetEtag())
+			}
+		})
+	}
+}
+
+func TestUpdateURL(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	// Set up a function to emulate the behavior of the method UpdateURL
+	testContext.MockStorage.updateURLFunc = func(key, userID, originalURL string) (models.DataURL, error) {
+		switch {
+		case key == "missingKey":
+			return models.DataURL{}, storage.ErrNotFound
+		case key == "foreignKey" || userID != "mockUserID":
+			return models.DataURL{}, storage.ErrNotOwner
+		case originalURL == "http://example.com/taken":
+			return models.DataURL{ShortURL: "http://localhost:8080/taken"}, storage.ErrConflict
+		}
+		return models.DataURL{ShortURL: "http://localhost:8080/" + key, OriginalURL: originalURL, UserID: userID}, nil
+	}
+
+	testCases := []struct {
+		name     string
+		request  *pb.UpdateURLRequest
+		wantCode codes.Code
+	}{
+		{name: "Updated", request: &pb.UpdateURLRequest{Key: "ownKey", OriginalUrl: "http://example.com/fixed"},
+			wantCode: codes.OK},
+		{name: "InvalidURL", request: &pb.UpdateURLRequest{Key: "ownKey", OriginalUrl: "javascript:alert(1)"},
+			wantCode: codes.InvalidArgument},
+		{name: "NotFound", request: &pb.UpdateURLRequest{Key: "missingKey", OriginalUrl: "http://example.com"},
+			wantCode: codes.NotFound},
+		{name: "NotOwner", request: &pb.UpdateURLRequest{Key: "foreignKey", OriginalUrl: "http://example.com"},
+			wantCode: codes.PermissionDenied},
+		{name: "Conflict", request: &pb.UpdateURLRequest{Key: "ownKey", OriginalUrl: "http://example.com/taken"},
+			wantCode: codes.AlreadyExists},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+			resp, err := testContext.Server.UpdateURL(ctx, tc.request)
+
+			assert.Equal(t, tc.wantCode, status.Code(err))
+			if tc.wantCode == codes.OK {
+				assert.Equal(t, "http://localhost:8080/ownKey", resp.GetUrl().GetShortUrl())
+				assert.Equal(t, "http://example.com/fixed", resp.GetUrl().GetOriginalUrl())
+			}
+		})
+	}
+}
+
+func TestUpdateURLTags(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	testContext.MockStorage.updateURLFunc = func(key, userID, originalURL string) (models.DataURL, error) {
+		return models.DataURL{ShortURL: "http://localhost:8080/" + key, OriginalURL: originalURL, UserID: userID}, nil
+	}
+
+	var updated [][]string
+	testContext.MockStorage.updateTagsFunc = func(key, userID string, tags []string) (models.DataURL, error) {
+		updated = append(updated, tags)
+		return models.DataURL{ShortURL: "http://localhost:8080/" + key, OriginalURL: "http://example.com/",
+			UserID: userID, Tags: tags}, nil
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	// Only the tags are replaced, normalized and without repetitions
+	resp, err := testContext.Server.UpdateURL(ctx, &pb.UpdateURLRequest{Key: "ownKey",
+		Tags: &pb.Tags{Tags: []string{"Sales", "q3", "sales"}}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"sales", "q3"}, resp.GetUrl().GetTags())
+
+	// An empty list removes the tags
+	_, err = testContext.Server.UpdateURL(ctx, &pb.UpdateURLRequest{Key: "ownKey", Tags: &pb.Tags{}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"sales", "q3"}, nil}, updated)
+
+	// A malformed tag is rejected
+	_, err = testContext.Server.UpdateURL(ctx, &pb.UpdateURLRequest{Key: "ownKey",
+		Tags: &pb.Tags{Tags: []string{"two words"}}})
+
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Len(t, updated, 2)
+}
+
 // TestDeleteUserURLs tests the DeleteUserURLs method of the UsersServer.
 func TestDeleteUserURLs(t *testing.T) {
 	// Create a new test context
@@ -567,6 +1245,30 @@ func TestDeleteUserURLs(t *testing.T) {
 	}
 }
 
+// TestRestoreUserURLs tests the RestoreUserURLs method of the UsersServer.
+func TestRestoreUserURLs(t *testing.T) {
+	testContext := NewTestContext(t)
+
+	request := &pb.RestoreUserURLsRequest{Urls: []string{"url1", "url2"}}
+
+	var restored models.DeleteURL
+	testContext.MockWorker.restoreFunc = func(task models.DeleteURL) {
+		restored = task
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", testContext.FakeToken))
+
+	resp, err := testContext.Server.RestoreUserURLs(ctx, request)
+	assert.NoError(t, err)
+	assert.Equal(t, "mockUserID", resp.GetKey())
+	assert.Equal(t, "mockUserID", restored.UserID)
+	assert.ElementsMatch(t, request.Urls, restored.ShortURLs)
+
+	// A request without a token is rejected
+	_, err = testContext.Server.RestoreUserURLs(context.Background(), request)
+	assert.Error(t, err)
+}
+
 // TestRegisterUser tests the RegisterUser method of the UsersServer.
 func TestRegisterUser(t *testing.T) {
 	// Create a new test context
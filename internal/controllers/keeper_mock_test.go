@@ -35,12 +35,36 @@ func (m *MockKeeper) GetURLsCount() (int, error) {
 	return args.Int(0), args.Error(1)
 }
 
+// Update - mock method for changing the original URL of an entry
+func (m *MockKeeper) Update(k string, v models.DataURL) (models.DataURL, error) {
+	args := m.Called(k, v)
+	return args.Get(0).(models.DataURL), args.Error(1)
+}
+
+// AddClick - mock method for counting a redirect of a click-limited URL
+func (m *MockKeeper) AddClick(k string, v models.DataURL) (models.DataURL, error) {
+	args := m.Called(k, v)
+	return args.Get(0).(models.DataURL), args.Error(1)
+}
+
+// AddVariantClick - mock method for counting a redirect to a variant of a split URL
+func (m *MockKeeper) AddVariantClick(k string, v models.DataURL, variant int) (models.DataURL, error) {
+	args := m.Called(k, v, variant)
+	return args.Get(0).(models.DataURL), args.Error(1)
+}
+
 // Save - mock method for saving data
 func (m *MockKeeper) Save(k string, v models.DataURL) (models.DataURL, error) {
 	args := m.Called(k, v)
 	return args.Get(0).(models.DataURL), args.Error(1)
 }
 
+// SaveAlias - mock method for saving data with a custom alias
+func (m *MockKeeper) SaveAlias(k string, v models.DataURL) (models.DataURL, error) {
+	args := m.Called(k, v)
+	return args.Get(0).(models.DataURL), args.Error(1)
+}
+
 // SaveBatch - mock method for saving a data batch
 func (m *MockKeeper) SaveUser(k string, v models.DataUser) (models.DataUser, error) {
 	args := m.Called(k, v)
@@ -59,6 +83,18 @@ func (m *MockKeeper) UpdateBatch(deleteURLs ...models.DeleteURL) error {
 	return args.Error(0)
 }
 
+// RestoreBatch - mock method for restoring a data batch
+func (m *MockKeeper) RestoreBatch(restoreURLs ...models.DeleteURL) error {
+	args := m.Called(restoreURLs)
+	return args.Error(0)
+}
+
+// Purge - mock method for removing deleted data
+func (m *MockKeeper) Purge(shortURLs ...string) error {
+	args := m.Called(shortURLs)
+	return args.Error(0)
+}
+
 // Ping - mock method for checking the connection
 func (m *MockKeeper) Ping() bool {
 	args := m.Called()
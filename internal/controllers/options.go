@@ -0,0 +1,324 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+	"unicode/utf8"
+
+	pb "github.com/wurt83ow/tinyurl/internal/controllers/proto"
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
+	"github.com/wurt83ow/tinyurl/internal/services/split"
+	"github.com/wurt83ow/tinyurl/internal/services/tags"
+	"github.com/wurt83ow/tinyurl/internal/services/targeting"
+)
+
+// maxNoteLength is the length of the longest note of a link in characters.
+const maxNoteLength = 1000
+
+// ErrRedirectCode is returned if a redirect status code is not supported.
+var ErrRedirectCode = errors.New("redirect code must be one of 301, 302, 307 or 308")
+
+// redirectCodes are the HTTP status codes a link may redirect with.
+var redirectCodes = map[int]bool{
+	http.StatusMovedPermanently:  true, // Code 301
+	http.StatusFound:             true, // Code 302
+	http.StatusTemporaryRedirect: true, // Code 307
+	http.StatusPermanentRedirect: true, // Code 308
+}
+
+// CheckRedirectCode returns ErrRedirectCode if code is neither zero, meaning
+// the default, nor one of the supported redirect status codes.
+func CheckRedirectCode(code int) error {
+	if code != 0 && !redirectCodes[code] {
+		return ErrRedirectCode
+	}
+
+	return nil
+}
+
+// redirectCode returns the HTTP status code the link redirects with: its own code if set,
+// otherwise the server default def if set, otherwise 307 Temporary Redirect.
+func redirectCode(data models.DataURL, def int) int {
+	if data.RedirectCode != 0 {
+		return data.RedirectCode
+	}
+
+	if def != 0 {
+		return def
+	}
+
+	return http.StatusTemporaryRedirect
+}
+
+// route returns the URL the link sends the client to, and the number of the split variant
+// the client is sent to or zero: the URL of the first targeting rule the client matches,
+// otherwise the variant the client sticks to, otherwise a variant chosen by the weights,
+// otherwise the original URL. The client sticks to the variant numbered sticky only if
+// the link is sticky.
+func route(data models.DataURL, client targeting.Client, sticky int) (string, int) {
+	if target, ok := targeting.Match(data.Rules, client); ok {
+		return target, 0
+	}
+
+	variant := sticky
+	if !data.Sticky || !split.Valid(data.Variants, variant) {
+		variant = split.Choose(data.Variants)
+	}
+	if variant == 0 {
+		return data.OriginalURL, 0
+	}
+
+	return data.Variants[variant-1].URL, variant
+}
+
+// destination returns the URL the link redirects to the target when it is opened with
+// the raw query string, with the query policy of the link applied.
+func destination(data models.DataURL, target string, rawQuery string) string {
+	return shorturl.Destination(target, data.QueryPolicy, rawQuery, utm(data.UTMSource,
+		data.UTMMedium, data.UTMCampaign))
+}
+
+// utm returns the UTM parameters of a link.
+func utm(source, medium, campaign string) shorturl.UTM {
+	return shorturl.UTM{Source: source, Medium: medium, Campaign: campaign}
+}
+
+// linkOptionError reports an invalid setting of a link.
+type linkOptionError struct {
+	field   string
+	message string
+}
+
+// Error implements the error interface.
+func (e *linkOptionError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.field, e.message)
+}
+
+// applyLinkOptions sets the expiration time, the click limit, the password hash,
+// the redirect status code, the query policy, the targeting rules, the split variants,
+// the tags and the note described by opts on data. TTL is counted from the time now. It returns
+// a *linkOptionError if the options are invalid.
+func applyLinkOptions(data *models.DataURL, opts models.LinkOptions, now time.Time) error {
+	exp, err := expiresAt(opts, now)
+	if err != nil {
+		return err
+	}
+
+	if opts.MaxClicks < 0 {
+		return &linkOptionError{field: "max_clicks", message: "max_clicks must not be negative"}
+	}
+
+	if err = CheckRedirectCode(opts.RedirectCode); err != nil {
+		return &linkOptionError{field: "redirect_code", message: err.Error()}
+	}
+
+	if err = checkQueryPolicy(opts); err != nil {
+		return err
+	}
+
+	if err = checkRules(opts.Rules); err != nil {
+		return err
+	}
+
+	if err = checkVariants(opts); err != nil {
+		return err
+	}
+
+	tags, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return err
+	}
+
+	if utf8.RuneCountInString(opts.Note) > maxNoteLength {
+		return &linkOptionError{field: "note", message: fmt.Sprintf("note must be at most %d characters", maxNoteLength)}
+	}
+
+	var hash []byte
+	if opts.Password != "" {
+		hash, err = linkpass.Hash(opts.Password)
+		if err != nil {
+			return &linkOptionError{field: "password", message: err.Error()}
+		}
+	}
+
+	data.ExpiresAt = exp
+	data.MaxClicks = opts.MaxClicks
+	data.PasswordHash = hash
+	data.RedirectCode = opts.RedirectCode
+	data.QueryPolicy = opts.QueryPolicy
+	data.UTMSource = opts.UTMSource
+	data.UTMMedium = opts.UTMMedium
+	data.UTMCampaign = opts.UTMCampaign
+	data.Rules = opts.Rules
+	data.Sticky = opts.Sticky
+	data.Tags = tags
+	data.Note = opts.Note
+	data.Domain = opts.Domain
+
+	// the variants start without clicks
+	data.Variants = nil
+	for _, v := range opts.Variants {
+		data.Variants = append(data.Variants, models.Variant{URL: v.URL, Weight: v.Weight})
+	}
+
+	return nil
+}
+
+// checkQueryPolicy returns a *linkOptionError if the query policy described by opts
+// is not supported or does not match the UTM parameters: they are required by the utm
+// policy and useless with the others.
+func checkQueryPolicy(opts models.LinkOptions) error {
+	if err := shorturl.ValidateQueryPolicy(opts.QueryPolicy); err != nil {
+		return &linkOptionError{field: "query_policy", message: err.Error()}
+	}
+
+	params := utm(opts.UTMSource, opts.UTMMedium, opts.UTMCampaign)
+	if opts.QueryPolicy == shorturl.QueryUTM && params.IsZero() {
+		return &linkOptionError{field: "query_policy", message: "the utm query policy requires utm parameters"}
+	}
+	if opts.QueryPolicy != shorturl.QueryUTM && !params.IsZero() {
+		return &linkOptionError{field: "query_policy", message: "utm parameters require the utm query policy"}
+	}
+
+	return nil
+}
+
+// checkRules returns a *linkOptionError if there are too many targeting rules or
+// a rule has no destination, no condition, an unknown platform or a malformed country. The destinations
+// are checked against the URL policy when the link is shortened.
+func checkRules(rules []models.Rule) error {
+	if len(rules) > targeting.MaxRules {
+		return &linkOptionError{field: "rules",
+			message: fmt.Sprintf("a link may have at most %d rules", targeting.MaxRules)}
+	}
+
+	for i, rule := range rules {
+		switch {
+		case rule.URL == "":
+			return &linkOptionError{field: fmt.Sprintf("rules[%d].url", i), message: "url is required"}
+		case rule.Platform == "" && rule.Language == "" && rule.Referrer == "" && rule.Country == "":
+			return &linkOptionError{field: fmt.Sprintf("rules[%d]", i),
+				message: "a rule needs a platform, language, referrer or country condition"}
+		case rule.Platform != "" && !targeting.ValidPlatform(rule.Platform):
+			return &linkOptionError{field: fmt.Sprintf("rules[%d].platform", i),
+				message: "platform must be one of ios, android, windows, macos or linux"}
+		case rule.Country != "" && !targeting.ValidCountry(rule.Country):
+			return &linkOptionError{field: fmt.Sprintf("rules[%d].country", i),
+				message: "country must be an ISO 3166-1 alpha-2 code"}
+		}
+	}
+
+	return nil
+}
+
+// checkVariants returns a *linkOptionError if the split variants described by opts are
+// too few or too many, a variant has no destination or a weight out of range, or the link
+// is sticky without variants. The destinations are checked against the URL policy when
+// the link is shortened.
+func checkVariants(opts models.LinkOptions) error {
+	switch {
+	case opts.Sticky && len(opts.Variants) == 0:
+		return &linkOptionError{field: "sticky", message: "sticky requires variants"}
+	case len(opts.Variants) == 1:
+		return &linkOptionError{field: "variants", message: "a split needs at least two variants"}
+	case len(opts.Variants) > split.MaxVariants:
+		return &linkOptionError{field: "variants",
+			message: fmt.Sprintf("a link may have at most %d variants", split.MaxVariants)}
+	}
+
+	for i, v := range opts.Variants {
+		switch {
+		case v.URL == "":
+			return &linkOptionError{field: fmt.Sprintf("variants[%d].url", i), message: "url is required"}
+		case v.Weight < 1 || v.Weight > split.MaxWeight:
+			return &linkOptionError{field: fmt.Sprintf("variants[%d].weight", i),
+				message: fmt.Sprintf("weight must be between 1 and %d", split.MaxWeight)}
+		}
+	}
+
+	return nil
+}
+
+// normalizeTags returns the tags normalized and without repetitions. It returns
+// a *linkOptionError if there are too many tags or a tag is malformed.
+func normalizeTags(list []string) ([]string, error) {
+	var res []string
+	for i, tag := range list {
+		tag = tags.Normalize(tag)
+		if !tags.Valid(tag) {
+			return nil, &linkOptionError{field: fmt.Sprintf("tags[%d]", i),
+				message: fmt.Sprintf("a tag is up to %d letters, digits, dashes, underscores or dots", tags.MaxLength)}
+		}
+		res = append(res, tag)
+	}
+
+	res = tags.Unique(res)
+	if len(res) > tags.MaxTags {
+		return nil, &linkOptionError{field: "tags", message: fmt.Sprintf("a link may have at most %d tags", tags.MaxTags)}
+	}
+
+	return res, nil
+}
+
+// expiresAt returns the time the link described by opts stops working at,
+// or nil if the link never expires. TTL is counted from the time now.
+// It returns a *linkOptionError if the options are invalid.
+func expiresAt(opts models.LinkOptions, now time.Time) (*time.Time, error) {
+	if opts.TTL != "" && opts.ExpiresAt != nil {
+		return nil, &linkOptionError{field: "ttl", message: "ttl and expires_at are mutually exclusive"}
+	}
+
+	if opts.TTL != "" {
+		ttl, err := time.ParseDuration(opts.TTL)
+		if err != nil || ttl <= 0 {
+			return nil, &linkOptionError{field: "ttl", message: "ttl must be a positive duration, e.g. 24h"}
+		}
+
+		t := now.Add(ttl).UTC()
+		return &t, nil
+	}
+
+	if opts.ExpiresAt != nil {
+		if !opts.ExpiresAt.After(now) {
+			return nil, &linkOptionError{field: "expires_at", message: "expires_at must be in the future"}
+		}
+
+		t := opts.ExpiresAt.UTC()
+		return &t, nil
+	}
+
+	return nil, nil
+}
+
+// linkOptions converts the protobuf link options to the internal model.
+// It returns a *linkOptionError if expires_at is not in RFC 3339 format.
+func linkOptions(opts *pb.LinkOptions) (models.LinkOptions, error) {
+	res := models.LinkOptions{TTL: opts.GetTtl(), MaxClicks: int(opts.GetMaxClicks()),
+		Password: opts.GetPassword(), RedirectCode: int(opts.GetRedirectCode()),
+		QueryPolicy: opts.GetQueryPolicy(), UTMSource: opts.GetUtmSource(), UTMMedium: opts.GetUtmMedium(),
+		UTMCampaign: opts.GetUtmCampaign(), Sticky: opts.GetSticky(), Tags: opts.GetTags(),
+		Note: opts.GetNote(), Domain: opts.GetDomain()}
+
+	for _, rule := range opts.GetRules() {
+		res.Rules = append(res.Rules, models.Rule{Platform: rule.GetPlatform(), Language: rule.GetLanguage(),
+			Referrer: rule.GetReferrer(), Country: rule.GetCountry(), URL: rule.GetUrl()})
+	}
+
+	for _, v := range opts.GetVariants() {
+		res.Variants = append(res.Variants, models.Variant{URL: v.GetUrl(), Weight: int(v.GetWeight())})
+	}
+
+	if opts.GetExpiresAt() != "" {
+		t, err := time.Parse(time.RFC3339, opts.GetExpiresAt())
+		if err != nil {
+			return res, &linkOptionError{field: "expires_at", message: "expires_at must be in RFC 3339 format"}
+		}
+		res.ExpiresAt = &t
+	}
+
+	return res, nil
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/split"
+	"github.com/wurt83ow/tinyurl/internal/services/targeting"
+	"go.uber.org/zap"
+)
+
+// previewSuffix appended to a short key asks for the preview page of the link
+// instead of the redirect, e.g. GET /abc+.
+const previewSuffix = "+"
+
+// previewParam set to true in the query string asks for the preview page as well.
+const previewParam = "preview"
+
+// previewTmpl is the page showing the destination of a link before following it.
+var previewTmpl = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Link preview</title>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+{{if eq (len .Destinations) 1}}<p>This link leads to:</p>
+<p><code>{{index .Destinations 0}}</code></p>{{else}}<p>This link leads to one of:</p>
+<ul>{{range .Destinations}}
+<li><code>{{.}}</code></li>{{end}}
+</ul>{{end}}
+{{if .Created}}<p>Created on {{.Created}}{{if .Owner}} by {{.Owner}}{{end}}.</p>{{else if .Owner}}<p>Created by {{.Owner}}.</p>{{end}}
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+// previewKey returns the key of the link the request is for and reports whether
+// the request asks for the preview page of the link.
+func previewKey(r *http.Request, key string) (string, bool) {
+	if k, ok := strings.CutSuffix(key, previewSuffix); ok {
+		return k, true
+	}
+
+	preview, _ := strconv.ParseBool(r.URL.Query().Get(previewParam))
+
+	return key, preview
+}
+
+// previewPage responds with the preview page of the link: its destinations for the request,
+// the date it was created on and the name of its owner, if known. The continue button posts
+// to the link, which redirects like the link itself.
+func (h *BaseController) previewPage(w http.ResponseWriter, r *http.Request, key string, data models.DataURL) {
+	var created, owner string
+	if data.CreatedAt != nil {
+		created = data.CreatedAt.UTC().Format("2 January 2006")
+	}
+	if data.UserID != "" {
+		if user, err := h.storage.GetUserByID(data.UserID); err == nil {
+			owner = user.Name
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+
+	err := previewTmpl.Execute(w, struct {
+		Action       template.URL
+		Destinations []string
+		Created      string
+		Owner        string
+	}{Action: template.URL(formAction(r, key)), Destinations: h.destinations(r, data), Created: created,
+		Owner: owner})
+	if err != nil {
+		h.log.Info("error rendering preview page: ", zap.Error(err))
+	}
+}
+
+// destinations returns the URLs the continue button of the preview page of the request may
+// redirect to, as route chooses them: the target of the matching targeting rule or the
+// variant the visitor sticks to if any, and every variant the split may choose otherwise.
+// The query policy of the link is applied with the query string the form is posted with.
+func (h *BaseController) destinations(r *http.Request, data models.DataURL) []string {
+	rawQuery := formQuery(r)
+
+	if target, ok := targeting.Match(data.Rules, h.requestClient(r)); ok {
+		return []string{destination(data, target, rawQuery)}
+	}
+
+	if variant := stickyVariant(r); data.Sticky && split.Valid(data.Variants, variant) {
+		return []string{destination(data, data.Variants[variant-1].URL, rawQuery)}
+	}
+
+	var list []string
+	for _, v := range data.Variants {
+		if v.Weight > 0 {
+			list = append(list, destination(data, v.URL, rawQuery))
+		}
+	}
+	if len(list) == 0 {
+		list = append(list, destination(data, data.OriginalURL, rawQuery))
+	}
+
+	return list
+}
+
+// interstitial reports whether the preview page is shown instead of redirecting because
+// the server is configured so for external domains and one of the destinations of the link
+// is on neither a domain of the short URLs nor its subdomains.
+func (h *BaseController) interstitial(data models.DataURL) bool {
+	if !h.options.InterstitialExternal() {
+		return false
+	}
+
+	targets := []string{data.OriginalURL}
+	for _, rule := range data.Rules {
+		targets = append(targets, rule.URL)
+	}
+	for _, v := range data.Variants {
+		targets = append(targets, v.URL)
+	}
+
+	for _, target := range targets {
+		if external(target, h.domains.Bases()...) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// external reports whether the URL is on a domain other than the domains of the base URLs
+// and their subdomains. Malformed URLs are considered external.
+func external(rawURL string, bases ...string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+
+	for _, base := range bases {
+		b, err := url.Parse(base)
+		if err != nil {
+			continue
+		}
+
+		own := strings.ToLower(b.Hostname())
+		if host == own || strings.HasSuffix(host, "."+own) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formAction returns the URL the forms of the link are posted to: the URL of the link
+// with the query string of formQuery.
+func formAction(r *http.Request, key string) string {
+	rawQuery := formQuery(r)
+
+	action := "/" + key
+	if rawQuery != "" {
+		action += "?" + rawQuery
+	}
+
+	return action
+}
+
+// formQuery returns the query string the forms of the link are posted with: the query
+// string of the request, kept for the query policy of the link, without the preview parameter.
+func formQuery(r *http.Request) string {
+	if query := r.URL.Query(); query.Has(previewParam) {
+		query.Del(previewParam)
+		return query.Encode()
+	}
+
+	return r.URL.RawQuery
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/linkpass"
+	"github.com/wurt83ow/tinyurl/internal/services/targeting"
+	"github.com/wurt83ow/tinyurl/internal/storage"
+	"go.uber.org/zap"
+)
+
+// variantCookie is the cookie keeping the visitors of a sticky split link on the variant
+// they were sent to first. It is scoped to the path of the link.
+const variantCookie = "variant"
+
+// variantCookieAge is the time the visitors stick to their variant for.
+const variantCookieAge = 30 * 24 * time.Hour
+
+// passwordFormTmpl is the page asking for the password of a protected link.
+var passwordFormTmpl = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Protected link</title>
+</head>
+<body>
+<form method="post" action="{{.Action}}">
+<p>This link is protected by a password.</p>
+{{if .Message}}<p>{{.Message}}</p>{{end}}
+<input type="password" name="password" autofocus required>
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// passwordForm responds with the password form of the protected link. The form is posted
+// to the URL of the request, keeping its query string for the query policy of the link.
+func (h *BaseController) passwordForm(w http.ResponseWriter, r *http.Request, key string, code int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+
+	err := passwordFormTmpl.Execute(w, struct {
+		Action  template.URL
+		Message string
+	}{Action: template.URL(formAction(r, key)), Message: message})
+	if err != nil {
+		h.log.Info("error rendering password form: ", zap.Error(err))
+	}
+}
+
+// unlockURL is a handler method for following a password-protected link.
+// It checks the password POSTed by the form served by getFullURL and redirects
+// to the original URL if it is correct. Failed attempts are rate limited per link.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) unlockURL(w http.ResponseWriter, r *http.Request) {
+	// Extract the key from the URL path
+	key := strings.Replace(r.URL.Path, "/", "", -1)
+
+	// Get the full URL from storage, the key is looked up on the domain of the request
+	data, err := h.storage.GetURL(h.storageKey(r, key))
+
+	// Respond with a Bad Request status code if the URL is not found or there is an error
+	if err != nil || len(data.OriginalURL) == 0 {
+		w.WriteHeader(http.StatusBadRequest) // Code 400
+		return
+	}
+
+	// Respond with a Gone status code if the URL has been marked as deleted or has expired
+	if data.DeletedFlag || data.Expired(time.Now()) {
+		w.WriteHeader(http.StatusGone) // Code 410
+		return
+	}
+
+	// Links without a password are followed right away
+	if !data.Protected() {
+		h.redirect(w, r, key, data, http.StatusSeeOther)
+		return
+	}
+
+	// Refuse the attempt if the link has had too many failed ones
+	k := h.storageKey(r, key)
+	if h.limiter.Blocked(k) {
+		h.log.Info("too many password attempts: ", zap.String("key", k))
+		h.passwordForm(w, r, key, http.StatusTooManyRequests, "Too many attempts, try again later.")
+		return
+	}
+
+	// Check the password
+	if !linkpass.Check(data.PasswordHash, r.PostFormValue("password")) {
+		h.limiter.Fail(k)
+		h.passwordForm(w, r, key, http.StatusUnauthorized, "Wrong password.")
+		return
+	}
+	h.limiter.Reset(k)
+
+	h.redirect(w, r, key, data, http.StatusSeeOther)
+}
+
+// redirect counts the click of the URL of the key, on the domain of the request, if it is click-limited
+// and redirects to the URL of the targeting rule the client matches, to a split variant
+// or to the original URL with the given status code, applying the query policy of the URL
+// to the query string of the request. The redirects to the variants are counted per
+// variant, and the visitors of a sticky link are kept on their variant by a cookie.
+// Every redirect is recorded as a click event.
+func (h *BaseController) redirect(w http.ResponseWriter, r *http.Request, key string, data models.DataURL, code int) {
+	// Count the redirect of a click-limited URL, the link is gone once all clicks are used
+	if data.MaxClicks > 0 {
+		var err error
+		data, err = h.storage.UseClick(h.storageKey(r, key))
+		if errors.Is(err, storage.ErrClicksExhausted) {
+			w.WriteHeader(http.StatusGone) // Code 410
+			return
+		}
+		if err != nil {
+			h.log.Info("cannot count click: ", zap.Error(err))
+			w.WriteHeader(http.StatusInternalServerError) // Code 500
+			return
+		}
+	}
+
+	// Choose the destination of the client
+	target, variant := route(data, h.requestClient(r), stickyVariant(r))
+
+	// Count the redirect to a variant, the redirect is served even if it is not counted
+	if variant != 0 {
+		if err := h.storage.AddVariantClick(h.storageKey(r, key), variant); err != nil {
+			h.log.Info("cannot count variant click: ", zap.Error(err))
+		}
+		if data.Sticky {
+			http.SetCookie(w, &http.Cookie{Name: variantCookie, Value: strconv.Itoa(variant), Path: "/" + key,
+				MaxAge: int(variantCookieAge.Seconds()), HttpOnly: true, SameSite: http.SameSiteLaxMode})
+		}
+	}
+
+	// Set the Location header for the redirect
+	w.Header().Set("Location", destination(data, target, r.URL.RawQuery))
+	w.WriteHeader(code)
+	h.log.Info("redirect", zap.Int("status", code))
+
+	// Record the click event of the redirect
+	h.recordClick(r, h.storageKey(r, key))
+}
+
+// requestClient returns the client of the request the targeting rules are matched against.
+func (h *BaseController) requestClient(r *http.Request) targeting.Client {
+	return targeting.Client{UserAgent: r.UserAgent(), AcceptLanguage: r.Header.Get("Accept-Language"),
+		Referrer: r.Referer(), Country: clientCountry(h.locator, getClientIP(r))}
+}
+
+// stickyVariant returns the number of the variant the visitor was sent to before by the
+// link of the request, or zero.
+func stickyVariant(r *http.Request) int {
+	c, err := r.Cookie(variantCookie)
+	if err != nil {
+		return 0
+	}
+
+	n, err := strconv.Atoi(c.Value)
+	if err != nil {
+		return 0
+	}
+
+	return n
+}
@@ -19,15 +19,20 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	URLService_ShortenURL_FullMethodName     = "/grpc.URLService/ShortenURL"
-	URLService_RegisterUser_FullMethodName   = "/grpc.URLService/RegisterUser"
-	URLService_Login_FullMethodName          = "/grpc.URLService/Login"
-	URLService_GetFullURL_FullMethodName     = "/grpc.URLService/GetFullURL"
-	URLService_DeleteUserURLs_FullMethodName = "/grpc.URLService/DeleteUserURLs"
-	URLService_HealthCheck_FullMethodName    = "/grpc.URLService/HealthCheck"
-	URLService_GetUserURLs_FullMethodName    = "/grpc.URLService/GetUserURLs"
-	URLService_ShortenJSON_FullMethodName    = "/grpc.URLService/ShortenJSON"
-	URLService_ShortenBatch_FullMethodName   = "/grpc.URLService/ShortenBatch"
+	URLService_ShortenURL_FullMethodName      = "/grpc.URLService/ShortenURL"
+	URLService_RegisterUser_FullMethodName    = "/grpc.URLService/RegisterUser"
+	URLService_Login_FullMethodName           = "/grpc.URLService/Login"
+	URLService_GetFullURL_FullMethodName      = "/grpc.URLService/GetFullURL"
+	URLService_GetQRCode_FullMethodName       = "/grpc.URLService/GetQRCode"
+	URLService_DeleteUserURLs_FullMethodName  = "/grpc.URLService/DeleteUserURLs"
+	URLService_RestoreUserURLs_FullMethodName = "/grpc.URLService/RestoreUserURLs"
+	URLService_HealthCheck_FullMethodName     = "/grpc.URLService/HealthCheck"
+	URLService_GetUserURLs_FullMethodName     = "/grpc.URLService/GetUserURLs"
+	URLService_SearchUserURLs_FullMethodName  = "/grpc.URLService/SearchUserURLs"
+	URLService_ShortenJSON_FullMethodName     = "/grpc.URLService/ShortenJSON"
+	URLService_ShortenBatch_FullMethodName    = "/grpc.URLService/ShortenBatch"
+	URLService_UpdateURL_FullMethodName       = "/grpc.URLService/UpdateURL"
+	URLService_GetURLStats_FullMethodName     = "/grpc.URLService/GetURLStats"
 )
 
 // URLServiceClient is the client API for URLService service.
@@ -38,11 +43,16 @@ type URLServiceClient interface {
 	RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*RegisterUserResponse, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
 	GetFullURL(ctx context.Context, in *GetURLRequest, opts ...grpc.CallOption) (*GetURLResponse, error)
+	GetQRCode(ctx context.Context, in *GetQRCodeRequest, opts ...grpc.CallOption) (*GetQRCodeResponse, error)
 	DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest, opts ...grpc.CallOption) (*DeleteUserURLsResponse, error)
+	RestoreUserURLs(ctx context.Context, in *RestoreUserURLsRequest, opts ...grpc.CallOption) (*RestoreUserURLsResponse, error)
 	HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error)
 	GetUserURLs(ctx context.Context, in *GetUserURLsRequest, opts ...grpc.CallOption) (*GetUserURLsResponse, error)
+	SearchUserURLs(ctx context.Context, in *SearchUserURLsRequest, opts ...grpc.CallOption) (*SearchUserURLsResponse, error)
 	ShortenJSON(ctx context.Context, in *ShortenJSONRequest, opts ...grpc.CallOption) (*ShortenJSONResponse, error)
 	ShortenBatch(ctx context.Context, in *ShortenBatchRequest, opts ...grpc.CallOption) (*ShortenBatchResponse, error)
+	UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error)
+	GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error)
 }
 
 type uRLServiceClient struct {
@@ -89,6 +99,15 @@ func (c *uRLServiceClient) GetFullURL(ctx context.Context, in *GetURLRequest, op
 	return out, nil
 }
 
+func (c *uRLServiceClient) GetQRCode(ctx context.Context, in *GetQRCodeRequest, opts ...grpc.CallOption) (*GetQRCodeResponse, error) {
+	out := new(GetQRCodeResponse)
+	err := c.cc.Invoke(ctx, URLService_GetQRCode_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLServiceClient) DeleteUserURLs(ctx context.Context, in *DeleteUserURLsRequest, opts ...grpc.CallOption) (*DeleteUserURLsResponse, error) {
 	out := new(DeleteUserURLsResponse)
 	err := c.cc.Invoke(ctx, URLService_DeleteUserURLs_FullMethodName, in, out, opts...)
@@ -98,6 +117,15 @@ func (c *uRLServiceClient) DeleteUserURLs(ctx context.Context, in *DeleteUserURL
 	return out, nil
 }
 
+func (c *uRLServiceClient) RestoreUserURLs(ctx context.Context, in *RestoreUserURLsRequest, opts ...grpc.CallOption) (*RestoreUserURLsResponse, error) {
+	out := new(RestoreUserURLsResponse)
+	err := c.cc.Invoke(ctx, URLService_RestoreUserURLs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLServiceClient) HealthCheck(ctx context.Context, in *HealthCheckRequest, opts ...grpc.CallOption) (*HealthCheckResponse, error) {
 	out := new(HealthCheckResponse)
 	err := c.cc.Invoke(ctx, URLService_HealthCheck_FullMethodName, in, out, opts...)
@@ -116,6 +144,15 @@ func (c *uRLServiceClient) GetUserURLs(ctx context.Context, in *GetUserURLsReque
 	return out, nil
 }
 
+func (c *uRLServiceClient) SearchUserURLs(ctx context.Context, in *SearchUserURLsRequest, opts ...grpc.CallOption) (*SearchUserURLsResponse, error) {
+	out := new(SearchUserURLsResponse)
+	err := c.cc.Invoke(ctx, URLService_SearchUserURLs_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLServiceClient) ShortenJSON(ctx context.Context, in *ShortenJSONRequest, opts ...grpc.CallOption) (*ShortenJSONResponse, error) {
 	out := new(ShortenJSONResponse)
 	err := c.cc.Invoke(ctx, URLService_ShortenJSON_FullMethodName, in, out, opts...)
@@ -134,6 +171,24 @@ func (c *uRLServiceClient) ShortenBatch(ctx context.Context, in *ShortenBatchReq
 	return out, nil
 }
 
+func (c *uRLServiceClient) UpdateURL(ctx context.Context, in *UpdateURLRequest, opts ...grpc.CallOption) (*UpdateURLResponse, error) {
+	out := new(UpdateURLResponse)
+	err := c.cc.Invoke(ctx, URLService_UpdateURL_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLServiceClient) GetURLStats(ctx context.Context, in *GetURLStatsRequest, opts ...grpc.CallOption) (*GetURLStatsResponse, error) {
+	out := new(GetURLStatsResponse)
+	err := c.cc.Invoke(ctx, URLService_GetURLStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // URLServiceServer is the server API for URLService service.
 // All implementations must embed UnimplementedURLServiceServer
 // for forward compatibility
@@ -142,11 +197,16 @@ type URLServiceServer interface {
 	RegisterUser(context.Context, *RegisterUserRequest) (*RegisterUserResponse, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
 	GetFullURL(context.Context, *GetURLRequest) (*GetURLResponse, error)
+	GetQRCode(context.Context, *GetQRCodeRequest) (*GetQRCodeResponse, error)
 	DeleteUserURLs(context.Context, *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error)
+	RestoreUserURLs(context.Context, *RestoreUserURLsRequest) (*RestoreUserURLsResponse, error)
 	HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error)
 	GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error)
+	SearchUserURLs(context.Context, *SearchUserURLsRequest) (*SearchUserURLsResponse, error)
 	ShortenJSON(context.Context, *ShortenJSONRequest) (*ShortenJSONResponse, error)
 	ShortenBatch(context.Context, *ShortenBatchRequest) (*ShortenBatchResponse, error)
+	UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error)
+	GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error)
 	mustEmbedUnimplementedURLServiceServer()
 }
 
@@ -166,21 +226,36 @@ func (UnimplementedURLServiceServer) Login(context.Context, *LoginRequest) (*Log
 func (UnimplementedURLServiceServer) GetFullURL(context.Context, *GetURLRequest) (*GetURLResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetFullURL not implemented")
 }
+func (UnimplementedURLServiceServer) GetQRCode(context.Context, *GetQRCodeRequest) (*GetQRCodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetQRCode not implemented")
+}
 func (UnimplementedURLServiceServer) DeleteUserURLs(context.Context, *DeleteUserURLsRequest) (*DeleteUserURLsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserURLs not implemented")
 }
+func (UnimplementedURLServiceServer) RestoreUserURLs(context.Context, *RestoreUserURLsRequest) (*RestoreUserURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreUserURLs not implemented")
+}
 func (UnimplementedURLServiceServer) HealthCheck(context.Context, *HealthCheckRequest) (*HealthCheckResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method HealthCheck not implemented")
 }
 func (UnimplementedURLServiceServer) GetUserURLs(context.Context, *GetUserURLsRequest) (*GetUserURLsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserURLs not implemented")
 }
+func (UnimplementedURLServiceServer) SearchUserURLs(context.Context, *SearchUserURLsRequest) (*SearchUserURLsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchUserURLs not implemented")
+}
 func (UnimplementedURLServiceServer) ShortenJSON(context.Context, *ShortenJSONRequest) (*ShortenJSONResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ShortenJSON not implemented")
 }
 func (UnimplementedURLServiceServer) ShortenBatch(context.Context, *ShortenBatchRequest) (*ShortenBatchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ShortenBatch not implemented")
 }
+func (UnimplementedURLServiceServer) UpdateURL(context.Context, *UpdateURLRequest) (*UpdateURLResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
+func (UnimplementedURLServiceServer) GetURLStats(context.Context, *GetURLStatsRequest) (*GetURLStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLStats not implemented")
+}
 func (UnimplementedURLServiceServer) mustEmbedUnimplementedURLServiceServer() {}
 
 // UnsafeURLServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -266,6 +341,24 @@ func _URLService_GetFullURL_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLService_GetQRCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetQRCodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).GetQRCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_GetQRCode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).GetQRCode(ctx, req.(*GetQRCodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLService_DeleteUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteUserURLsRequest)
 	if err := dec(in); err != nil {
@@ -284,6 +377,24 @@ func _URLService_DeleteUserURLs_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLService_RestoreUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreUserURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).RestoreUserURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_RestoreUserURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).RestoreUserURLs(ctx, req.(*RestoreUserURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLService_HealthCheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthCheckRequest)
 	if err := dec(in); err != nil {
@@ -320,6 +431,24 @@ func _URLService_GetUserURLs_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLService_SearchUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchUserURLsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).SearchUserURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_SearchUserURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).SearchUserURLs(ctx, req.(*SearchUserURLsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLService_ShortenJSON_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(ShortenJSONRequest)
 	if err := dec(in); err != nil {
@@ -356,6 +485,42 @@ func _URLService_ShortenBatch_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLService_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).UpdateURL(ctx, req.(*UpdateURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLService_GetURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLServiceServer).GetURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLService_GetURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLServiceServer).GetURLStats(ctx, req.(*GetURLStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // URLService_ServiceDesc is the grpc.ServiceDesc for URLService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -379,10 +544,18 @@ var URLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetFullURL",
 			Handler:    _URLService_GetFullURL_Handler,
 		},
+		{
+			MethodName: "GetQRCode",
+			Handler:    _URLService_GetQRCode_Handler,
+		},
 		{
 			MethodName: "DeleteUserURLs",
 			Handler:    _URLService_DeleteUserURLs_Handler,
 		},
+		{
+			MethodName: "RestoreUserURLs",
+			Handler:    _URLService_RestoreUserURLs_Handler,
+		},
 		{
 			MethodName: "HealthCheck",
 			Handler:    _URLService_HealthCheck_Handler,
@@ -391,6 +564,10 @@ var URLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserURLs",
 			Handler:    _URLService_GetUserURLs_Handler,
 		},
+		{
+			MethodName: "SearchUserURLs",
+			Handler:    _URLService_SearchUserURLs_Handler,
+		},
 		{
 			MethodName: "ShortenJSON",
 			Handler:    _URLService_ShortenJSON_Handler,
@@ -399,6 +576,14 @@ var URLService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ShortenBatch",
 			Handler:    _URLService_ShortenBatch_Handler,
 		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _URLService_UpdateURL_Handler,
+		},
+		{
+			MethodName: "GetURLStats",
+			Handler:    _URLService_GetURLStats_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/grpc_info.proto",
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/qr"
+	"go.uber.org/zap"
+)
+
+// qrSuffix is the path suffix of the QR codes of the short URLs, e.g. GET /abc/qr.
+const qrSuffix = "/qr"
+
+// qrOptionField returns the name of the query parameter rejected with the error of
+// qr.ParseOptions.
+func qrOptionField(err error) string {
+	switch {
+	case errors.Is(err, qr.ErrSize):
+		return "size"
+	case errors.Is(err, qr.ErrLevel):
+		return "level"
+	}
+
+	return "format"
+}
+
+// matchesETag reports whether the If-None-Match header lists the entity tag.
+func matchesETag(header string, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getQRCode is a handler method for the QR code of a short URL.
+// It renders the QR code encoding the short URL as a PNG or SVG image, sized and
+// error-corrected by the size, level and format query parameters. The image is
+// served with an ETag and revalidated by the clients, so that it is gone with the URL.
+//
+// Like the redirects, it responds with 410 Gone for a deleted, expired or used up URL,
+// and with 404 Not Found for an unknown one.
+//
+// Parameters:
+//   - h: A pointer to the BaseController instance.
+//   - w: An http.ResponseWriter for writing the HTTP response.
+//   - r: An http.Request representing the incoming HTTP request.
+func (h *BaseController) getQRCode(w http.ResponseWriter, r *http.Request) {
+	// Extract the key from the URL path
+	key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), qrSuffix)
+
+	// Parse the options of the image
+	query := r.URL.Query()
+	opts, err := qr.ParseOptions(query.Get("size"), query.Get("level"), query.Get("format"))
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		if err := json.NewEncoder(w).Encode(models.ErrorResponse{
+			Error:   "invalid_options",
+			Message: err.Error(),
+			Field:   qrOptionField(err),
+		}); err != nil {
+			h.log.Info("error encoding response: ", zap.Error(err))
+		}
+		return
+	}
+
+	// Get the URL from storage, the key is looked up on the domain of the request
+	data, err := h.storage.GetURL(h.storageKey(r, key))
+	if err != nil || len(data.OriginalURL) == 0 {
+		w.WriteHeader(http.StatusNotFound) // Code 404
+		return
+	}
+
+	// Respond with a Gone status code if the URL no longer redirects
+	if data.DeletedFlag || data.Expired(time.Now()) || data.ClicksExhausted() {
+		w.WriteHeader(http.StatusGone) // Code 410
+		return
+	}
+
+	// Respond with a Not Modified status code if the client has the image
+	etag := qr.ETag(data.ShortURL, opts)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if matchesETag(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified) // Code 304
+		return
+	}
+
+	image, err := qr.Render(data.ShortURL, opts)
+	if err != nil {
+		h.log.Info("cannot render QR code: ", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError) // Code 500
+		return
+	}
+
+	w.Header().Set("Content-Type", opts.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(image); err != nil {
+		h.log.Info("error writing QR code: ", zap.Error(err))
+	}
+}
@@ -0,0 +1,175 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/services/tags"
+	"github.com/wurt83ow/tinyurl/internal/storage"
+)
+
+// nextPageHeader carries the token of the next page of the user URLs, it is absent on the last page.
+const nextPageHeader = "X-Next-Page-Token"
+
+// queryError reports an invalid parameter of a user URLs query.
+type queryError struct {
+	field   string
+	message string
+}
+
+// Error implements the error interface.
+func (e *queryError) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.field, e.message)
+}
+
+// urlQueryParams are the raw parameters of a user URLs query, named like the query
+// parameters of GET /api/user/urls and the fields of the gRPC request.
+type urlQueryParams struct {
+	tags        []string
+	search      string
+	deleted     string
+	createdFrom string
+	createdTo   string
+	sort        string
+	order       string
+	pageSize    string
+	pageToken   string
+}
+
+// query returns the storage query described by the parameters. The tags are normalized.
+// It returns a *queryError if a parameter is invalid.
+func (p urlQueryParams) query() (storage.URLQuery, error) {
+	q := storage.URLQuery{Search: p.search, PageToken: p.pageToken}
+
+	for _, tag := range p.tags {
+		q.Tags = append(q.Tags, tags.Normalize(tag))
+	}
+
+	if p.deleted != "" {
+		deleted, err := strconv.ParseBool(p.deleted)
+		if err != nil {
+			return q, &queryError{field: "deleted", message: "deleted must be true or false"}
+		}
+		q.Deleted = &deleted
+	}
+
+	var err error
+	if q.CreatedFrom, err = parseQueryTime("created_from", p.createdFrom); err != nil {
+		return q, err
+	}
+	if q.CreatedTo, err = parseQueryTime("created_to", p.createdTo); err != nil {
+		return q, err
+	}
+
+	switch p.sort {
+	case "", storage.SortCreated, storage.SortKey:
+		q.Sort = p.sort
+	default:
+		return q, &queryError{field: "sort", message: "sort must be created or key"}
+	}
+
+	switch p.order {
+	case "", "asc":
+	case "desc":
+		q.Desc = true
+	default:
+		return q, &queryError{field: "order", message: "order must be asc or desc"}
+	}
+
+	if p.pageSize != "" {
+		q.PageSize, err = strconv.Atoi(p.pageSize)
+		if err != nil || q.PageSize < 1 || q.PageSize > storage.MaxPageSize {
+			return q, &queryError{field: "page_size",
+				message: fmt.Sprintf("page_size must be between 1 and %d", storage.MaxPageSize)}
+		}
+	}
+
+	return q, nil
+}
+
+// parseQueryTime parses the time of the query parameter in RFC 3339 format, empty means unset.
+func parseQueryTime(field string, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, &queryError{field: field, message: field + " must be in RFC 3339 format"}
+	}
+
+	return &t, nil
+}
+
+// defaultStatsRange is the range of the URL stats ending now, by interval, used when
+// the start of the range is not given.
+var defaultStatsRange = map[string]time.Duration{
+	storage.IntervalHour: 24 * time.Hour,
+	storage.IntervalDay:  30 * 24 * time.Hour,
+}
+
+// statsQueryParams are the raw parameters of a URL stats query, named like the query
+// parameters of GET /api/user/urls/{key}/stats and the fields of the gRPC request.
+type statsQueryParams struct {
+	from     string
+	to       string
+	interval string
+	top      string
+}
+
+// query returns the storage query described by the parameters at the time now. The range
+// is widened to whole buckets, it ends now and spans the default range of the interval
+// unless given. It returns a *queryError if a parameter is invalid.
+func (p statsQueryParams) query(now time.Time) (storage.StatsQuery, error) {
+	q := storage.StatsQuery{Interval: p.interval}
+
+	switch p.interval {
+	case "":
+		q.Interval = storage.IntervalDay
+	case storage.IntervalHour, storage.IntervalDay:
+	default:
+		return q, &queryError{field: "interval", message: "interval must be hour or day"}
+	}
+	step := q.Step()
+
+	from, err := parseQueryTime("from", p.from)
+	if err != nil {
+		return q, err
+	}
+	to, err := parseQueryTime("to", p.to)
+	if err != nil {
+		return q, err
+	}
+
+	q.To = now.UTC()
+	if to != nil {
+		q.To = to.UTC()
+	}
+	if t := q.To.Truncate(step); t.Before(q.To) {
+		q.To = t.Add(step)
+	}
+
+	q.From = q.To.Add(-defaultStatsRange[q.Interval])
+	if from != nil {
+		q.From = from.UTC().Truncate(step)
+	}
+
+	if !q.From.Before(q.To) {
+		return q, &queryError{field: "from", message: "from must be before to"}
+	}
+	if q.To.Sub(q.From)/step > storage.MaxStatsBuckets {
+		return q, &queryError{field: "from",
+			message: fmt.Sprintf("the range must span %d %ss at most", storage.MaxStatsBuckets, q.Interval)}
+	}
+
+	if p.top != "" {
+		q.Top, err = strconv.Atoi(p.top)
+		if err != nil || q.Top < 1 || q.Top > storage.MaxStatsTop {
+			return q, &queryError{field: "top",
+				message: fmt.Sprintf("top must be between 1 and %d", storage.MaxStatsTop)}
+		}
+	}
+
+	return q, nil
+}
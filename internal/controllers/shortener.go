@@ -0,0 +1,249 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/domains"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
+	"github.com/wurt83ow/tinyurl/internal/storage"
+)
+
+// maxKeyAttempts limits how many times a colliding key is re-derived.
+const maxKeyAttempts = 8
+
+// shortener shortens original URLs and saves them to the storage.
+type shortener struct {
+	storage    Storage
+	keygen     KeyGenerator
+	normalizer Normalizer
+	validator  Validator
+	domains    *domains.Set
+}
+
+// batchItemError reports the batch item whose original URL was rejected.
+type batchItemError struct {
+	index int
+	err   error
+}
+
+// Error implements the error interface.
+func (e *batchItemError) Error() string {
+	return fmt.Sprintf("batch item %d: %v", e.index, e.err)
+}
+
+// Unwrap returns the error of the batch item.
+func (e *batchItemError) Unwrap() error {
+	return e.err
+}
+
+// insertURL validates and normalizes the original URL of data and shortens it with the key generator,
+// or reserves the custom alias when one is given, on the domain of data and saves the result to the storage.
+// It returns a *shorturl.ValidationError if the original URL violates the URL policy,
+// a *linkOptionError if the URL of a targeting rule or a split variant does or the domain is not
+// configured and shorturl.ErrInvalidAlias if the alias violates the alias policy.
+//
+// If the generated key is already bound to a different original URL on the domain, the key is
+// re-derived from the salted URL, up to maxKeyAttempts times.
+func (s *shortener) insertURL(alias string, data models.DataURL) (models.DataURL, error) {
+	if err := s.validator.Validate(data.OriginalURL); err != nil {
+		return data, err
+	}
+	data.OriginalURL = s.normalizer.Normalize(data.OriginalURL)
+
+	if err := s.validateTargets(&data); err != nil {
+		return data, err
+	}
+
+	if err := s.resolveDomain(&data); err != nil {
+		return data, err
+	}
+	shortURLAdress := s.domains.Base(data.Domain)
+
+	now := time.Now().UTC()
+	data.CreatedAt = &now
+
+	if alias == "" {
+		for attempt := 0; attempt < maxKeyAttempts; attempt++ {
+			key, err := s.keygen.Generate(shorturl.Salt(data.OriginalURL, attempt))
+			if err != nil {
+				return data, err
+			}
+			data.ShortURL = shorturl.Build(shortURLAdress, key)
+
+			m, err := s.storage.InsertURL(storage.DomainKey(data.Domain, key), data)
+			if errors.Is(err, storage.ErrCollision) {
+				continue
+			}
+
+			return m, err
+		}
+
+		return data, storage.ErrCollision
+	}
+
+	key, shurl, err := shorturl.Alias(alias, shortURLAdress)
+	if err != nil {
+		return data, err
+	}
+	data.ShortURL = shurl
+
+	return s.storage.InsertAlias(storage.DomainKey(data.Domain, key), data)
+}
+
+// resolveDomain binds data to the name of its requested domain, the default domain if none
+// is requested. It returns a *linkOptionError if the domain is not configured.
+func (s *shortener) resolveDomain(data *models.DataURL) error {
+	domain, err := s.domains.Resolve(data.Domain)
+	if err != nil {
+		return &linkOptionError{field: "domain", message: err.Error()}
+	}
+	data.Domain = domain
+
+	return nil
+}
+
+// updateURL validates and normalizes the new original URL and binds it to the user's
+// link stored under the key, keeping the key. It returns a *shorturl.ValidationError if the
+// original URL violates the URL policy.
+func (s *shortener) updateURL(key string, userID string, originalURL string) (models.DataURL, error) {
+	if err := s.validator.Validate(originalURL); err != nil {
+		return models.DataURL{}, err
+	}
+
+	return s.storage.UpdateURL(key, userID, s.normalizer.Normalize(originalURL))
+}
+
+// updateTags normalizes the tags and replaces the tags of the user's link with them.
+// It returns a *linkOptionError if the tags are invalid.
+func (s *shortener) updateTags(key string, userID string, list []string) (models.DataURL, error) {
+	list, err := normalizeTags(list)
+	if err != nil {
+		return models.DataURL{}, err
+	}
+
+	return s.storage.UpdateTags(key, userID, list)
+}
+
+// validateTargets validates and normalizes the URLs of the targeting rules and the split
+// variants of data in place. It returns a *linkOptionError if one of the URLs violates
+// the URL policy.
+func (s *shortener) validateTargets(data *models.DataURL) error {
+	for i := range data.Rules {
+		if err := s.validateTarget(fmt.Sprintf("rules[%d].url", i), &data.Rules[i].URL); err != nil {
+			return err
+		}
+	}
+
+	for i := range data.Variants {
+		if err := s.validateTarget(fmt.Sprintf("variants[%d].url", i), &data.Variants[i].URL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateTarget validates and normalizes the URL of the option field in place.
+func (s *shortener) validateTarget(field string, target *string) error {
+	if err := s.validator.Validate(*target); err != nil {
+		var ve *shorturl.ValidationError
+		if errors.As(err, &ve) {
+			return &linkOptionError{field: field, message: ve.Message}
+		}
+		return err
+	}
+	*target = s.normalizer.Normalize(*target)
+
+	return nil
+}
+
+// insertBatch validates and normalizes the original URLs of the batch, shortens them
+// with the key generator on their domains and saves them to the storage in one go.
+// It returns the batch with the short URLs set, or a *batchItemError for the first rejected URL.
+//
+// Keys that collide with each other or with the stored ones are re-derived from
+// the salted URLs, up to maxKeyAttempts times, the rest of the batch keeps its keys.
+// The URLs already shortened in the scope, or repeated in the batch, get their stored keys.
+func (s *shortener) insertBatch(batch []models.DataURL) ([]models.DataURL, error) {
+	for i := range batch {
+		if err := s.validator.Validate(batch[i].OriginalURL); err != nil {
+			return nil, &batchItemError{index: i, err: err}
+		}
+		batch[i].OriginalURL = s.normalizer.Normalize(batch[i].OriginalURL)
+
+		if err := s.validateTargets(&batch[i]); err != nil {
+			return nil, &batchItemError{index: i, err: err}
+		}
+
+		if err := s.resolveDomain(&batch[i]); err != nil {
+			return nil, &batchItemError{index: i, err: err}
+		}
+	}
+
+	now := time.Now().UTC()
+	for i := range batch {
+		batch[i].CreatedAt = &now
+	}
+
+	keys := make([]string, len(batch))
+	attempts := make([]int, len(batch))
+
+	for try := 0; try < maxKeyAttempts; try++ {
+		dataURL := make(storage.StorageURL)
+
+		for i := range batch {
+			for keys[i] == "" {
+				if attempts[i] >= maxKeyAttempts {
+					return nil, storage.ErrCollision
+				}
+
+				key, err := s.keygen.Generate(shorturl.Salt(batch[i].OriginalURL, attempts[i]))
+				if err != nil {
+					return nil, err
+				}
+
+				// The same key is taken by another URL of the batch on the domain
+				if cur, ok := dataURL[storage.DomainKey(batch[i].Domain, key)]; ok && cur.OriginalURL != batch[i].OriginalURL {
+					attempts[i]++
+					continue
+				}
+
+				keys[i] = key
+			}
+
+			batch[i].ShortURL = shorturl.Build(s.domains.Base(batch[i].Domain), keys[i])
+			dataURL[storage.DomainKey(batch[i].Domain, keys[i])] = batch[i]
+		}
+
+		err := s.storage.InsertBatch(dataURL)
+
+		var collision *storage.CollisionError
+		if !errors.As(err, &collision) {
+			if err != nil {
+				return nil, err
+			}
+
+			// the URLs already shortened get the short URLs they are stored under
+			for i := range batch {
+				batch[i].ShortURL = dataURL[storage.DomainKey(batch[i].Domain, keys[i])].ShortURL
+			}
+
+			return batch, nil
+		}
+
+		// Re-derive the keys already bound to different URLs
+		for _, key := range collision.Keys {
+			for i := range batch {
+				if storage.DomainKey(batch[i].Domain, keys[i]) == key {
+					keys[i] = ""
+					attempts[i]++
+				}
+			}
+		}
+	}
+
+	return nil, storage.ErrCollision
+}
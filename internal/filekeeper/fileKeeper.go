@@ -4,16 +4,34 @@
 package filekeeper
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/hll"
 	"github.com/wurt83ow/tinyurl/internal/storage"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// MaxClicksFileSize is the size the clicks file is rotated at.
+const MaxClicksFileSize = 64 << 20
+
+// sequenceBlock is the number of the numbers of the short key sequence reserved at once.
+const sequenceBlock = 100
+
 // Log is an interface for logging operations.
 type Log interface {
 	Info(string, ...zapcore.Field)
@@ -21,12 +39,22 @@ type Log interface {
 
 // FileKeeper is an implementation of the storage.Keeper interface that uses JSON files for persistence.
 type FileKeeper struct {
-	path func() string
-	log  Log
+	path  func() string
+	log   Log
+	scope string
+	// mx serializes the writes to the data file and to the click counts, variant clicks,
+	// clicks and sketches files, the appends as well as the rewrites
+	mx sync.Mutex
+	// seqNext is the last issued and seqEnd the last reserved number of the short key
+	// sequence, guarded by smx
+	seqNext uint64
+	seqEnd  uint64
+	smx     sync.Mutex
 }
 
 // NewFileKeeper creates a new instance of FileKeeper with the specified file path and logger.
-func NewFileKeeper(path func() string, log Log) *FileKeeper {
+// The scope defines whether an original URL is unique across all users or per user.
+func NewFileKeeper(path func() string, log Log, scope string) *FileKeeper {
 	addr := path()
 	if addr == "" {
 		log.Info("file json path is empty")
@@ -34,13 +62,17 @@ func NewFileKeeper(path func() string, log Log) *FileKeeper {
 	}
 
 	return &FileKeeper{
-		path: path,
-		log:  log,
+		path:  path,
+		log:   log,
+		scope: scope,
 	}
 }
 
 // Load implements storage.Keeper.
 func (kp *FileKeeper) Load() (storage.StorageURL, error) {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
 	dataFile := kp.path()
 	data := make(storage.StorageURL)
 
@@ -60,11 +92,22 @@ func (kp *FileKeeper) Load() (storage.StorageURL, error) {
 	for decoder.More() {
 		var m models.DataURL
 		err := decoder.Decode(&m)
-		data[m.ShortURL] = m
-
 		if err != nil {
 			kp.log.Info("cannot decode JSON file1: ", zap.Error(err))
 		}
+
+		// user records share the file with url records
+		if m.OriginalURL == "" {
+			continue
+		}
+		data[storage.DataKey(m)] = m
+	}
+
+	if err = kp.loadCounts(data); err != nil {
+		kp.log.Info("cannot load click counts: ", zap.Error(err))
+	}
+	if err = kp.loadVariantCounts(data); err != nil {
+		kp.log.Info("cannot load variant clicks: ", zap.Error(err))
 	}
 
 	return data, nil
@@ -148,68 +191,122 @@ func (kp *FileKeeper) GetURLsCount() (int, error) {
 
 // Save implements storage.Keeper.
 func (kp *FileKeeper) Save(key string, data models.DataURL) (models.DataURL, error) {
-	dataFile := kp.path()
-	var (
-		action string
-		err    error
-		cfile  *os.File
-	)
-
-	if _, err = os.Stat(dataFile); err == nil {
-		// file exists. Open file
-		cfile, err = os.OpenFile(dataFile, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-		action = "open"
-	} else {
-		// file not exists. Create file
-		cfile, err = os.Create(dataFile)
-		action = "create"
-	}
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
 
+	cfile, err := os.OpenFile(kp.path(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
 	if err != nil {
-		kp.log.Info("Cannot %v file: %v", zap.Any(action, zapcore.StringType), zap.Error(err))
+		kp.log.Info("cannot open file: ", zap.Error(err))
 		return data, err
 	}
 	defer cfile.Close()
 
-	// check if there is an entry in the file
-	if action == "open" {
-		decoder := json.NewDecoder(cfile)
-		for decoder.More() {
-			var m models.DataURL
-			err = decoder.Decode(&m)
-			if err != nil {
-				kp.log.Info("cannot decode JSON file4: ", zap.Error(err))
-			}
-			if m.ShortURL == key {
-				return m, storage.ErrConflict
-			}
+	// check if the key or the original url is already taken
+	if m, found := kp.find(cfile, key, data); found {
+		if storage.DataKey(m) == key && (m.OriginalURL != data.OriginalURL ||
+			storage.Owner(kp.scope, m.UserID) != storage.Owner(kp.scope, data.UserID)) {
+			return m, storage.ErrCollision
 		}
+		return m, storage.ErrConflict
 	}
 
-	var id string
 	if data.UUID == "" {
-		neuuid := uuid.New()
-		id = neuuid.String()
-	} else {
-		id = data.UUID
+		data.UUID = uuid.New().String()
 	}
 
-	du := models.DataURL{
-		UUID: id, ShortURL: data.ShortURL,
-		OriginalURL: data.OriginalURL}
+	encoder := json.NewEncoder(cfile)
+	err = encoder.Encode(data)
+	if err != nil {
+		kp.log.Info("cannot encode JSON data", zap.Error(err))
+		return data, err
+	}
+
+	return data, nil
+}
+
+// SaveAlias implements storage.Keeper.
+func (kp *FileKeeper) SaveAlias(key string, data models.DataURL) (models.DataURL, error) {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	cfile, err := os.OpenFile(kp.path(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return data, err
+	}
+	defer cfile.Close()
+
+	// check if the alias or the original url is already taken
+	if m, found := kp.find(cfile, key, data); found {
+		return m, storage.ErrConflict
+	}
+
+	if data.UUID == "" {
+		data.UUID = uuid.New().String()
+	}
 
 	encoder := json.NewEncoder(cfile)
-	err = encoder.Encode(du)
+	err = encoder.Encode(data)
 	if err != nil {
 		kp.log.Info("cannot encode JSON data", zap.Error(err))
 		return data, err
 	}
 
-	return du, nil
+	return data, nil
+}
+
+// find scans the url records of the file for the one stored under the key or
+// owning the original url of data in the scope.
+func (kp *FileKeeper) find(r io.Reader, key string, data models.DataURL) (models.DataURL, bool) {
+	records := kp.latest(r)
+	if m, ok := records[key]; ok {
+		return m, true
+	}
+
+	return kp.owning(records, data)
+}
+
+// owning returns the record owning the original url of data on its domain in the scope.
+func (kp *FileKeeper) owning(records storage.StorageURL, data models.DataURL) (models.DataURL, bool) {
+	owner := storage.Owner(kp.scope, data.UserID)
+	for _, m := range records {
+		if m.OriginalURL == data.OriginalURL && m.Domain == data.Domain && storage.Owner(kp.scope, m.UserID) == owner {
+			return m, true
+		}
+	}
+
+	return models.DataURL{}, false
+}
+
+// latest reads the url records of the file by key. A record appended later
+// supersedes the earlier ones stored under the same key.
+func (kp *FileKeeper) latest(r io.Reader) storage.StorageURL {
+	records := make(storage.StorageURL)
+
+	decoder := json.NewDecoder(r)
+	for decoder.More() {
+		var m models.DataURL
+		err := decoder.Decode(&m)
+		if err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			break
+		}
+
+		// user records share the file with url records
+		if m.OriginalURL == "" {
+			continue
+		}
+		records[storage.DataKey(m)] = m
+	}
+
+	return records
 }
 
 // SaveUser implements storage.Keeper.
 func (kp *FileKeeper) SaveUser(key string, data models.DataUser) (models.DataUser, error) {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
 	dataFile := kp.path()
 	var (
 		action string
@@ -218,8 +315,8 @@ func (kp *FileKeeper) SaveUser(key string, data models.DataUser) (models.DataUse
 	)
 
 	if _, err = os.Stat(dataFile); err == nil {
-		// file exists. Open file
-		cfile, err = os.OpenFile(dataFile, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
+		// file exists. Open file, it is read for the entry first
+		cfile, err = os.OpenFile(dataFile, os.O_RDWR|os.O_APPEND, 0o644)
 		action = "open"
 	} else {
 		// file not exists. Create file
@@ -241,6 +338,7 @@ func (kp *FileKeeper) SaveUser(key string, data models.DataUser) (models.DataUse
 			err = decoder.Decode(&m)
 			if err != nil {
 				kp.log.Info("cannot decode JSON file5: ", zap.Error(err))
+				break
 			}
 			if m.Email == key {
 				return m, storage.ErrConflict
@@ -271,44 +369,38 @@ func (kp *FileKeeper) SaveUser(key string, data models.DataUser) (models.DataUse
 }
 
 // SaveBatch implements storage.Keeper.
+// Like the database keeper, it skips the urls whose original url is already
+// owned in the scope.
 func (kp *FileKeeper) SaveBatch(data storage.StorageURL) error {
-	dataFile := kp.path()
-	var (
-		action string
-		err    error
-		cfile  *os.File
-	)
-
-	if _, err = os.Stat(dataFile); err == nil {
-		// file exists. Open file
-		cfile, err = os.OpenFile(dataFile, os.O_APPEND|os.O_WRONLY, os.ModeAppend)
-		action = "open"
-	} else {
-		// file not exists. Create file
-		cfile, err = os.Create(dataFile)
-		action = "create"
-	}
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
 
+	cfile, err := os.OpenFile(kp.path(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
 	if err != nil {
-		kp.log.Info("Cannot %v file: %v", zap.Any(action, zapcore.StringType), zap.Error(err))
+		kp.log.Info("cannot open file: ", zap.Error(err))
 		return err
 	}
 	defer cfile.Close()
 
-	for k, v := range data {
-		var id string
+	// collect the original urls already owned on their domains in the scope
+	owned := make(map[[3]string]struct{})
+	for _, m := range kp.latest(cfile) {
+		owned[[3]string{storage.Owner(kp.scope, m.UserID), m.Domain, m.OriginalURL}] = struct{}{}
+	}
+
+	encoder := json.NewEncoder(cfile)
+	for _, v := range data {
+		ownedKey := [3]string{storage.Owner(kp.scope, v.UserID), v.Domain, v.OriginalURL}
+		if _, ok := owned[ownedKey]; ok {
+			continue
+		}
+		owned[ownedKey] = struct{}{}
+
 		if v.UUID == "" {
-			neuuid := uuid.New()
-			id = neuuid.String()
-		} else {
-			id = v.UUID
+			v.UUID = uuid.New().String()
 		}
 
-		du := models.DataURL{
-			UUID: id, ShortURL: k,
-			OriginalURL: v.OriginalURL}
-		encoder := json.NewEncoder(cfile)
-		err = encoder.Encode(du)
+		err = encoder.Encode(v)
 		if err != nil {
 			kp.log.Info("cannot encode JSON data", zap.Error(err))
 			return err
@@ -318,11 +410,705 @@ func (kp *FileKeeper) SaveBatch(data storage.StorageURL) error {
 	return nil
 }
 
+// Update implements storage.Keeper.
+// The updated record is appended to the file and supersedes the previous one on Load.
+func (kp *FileKeeper) Update(key string, data models.DataURL) (models.DataURL, error) {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	cfile, err := os.OpenFile(kp.path(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return data, err
+	}
+	defer cfile.Close()
+
+	records := kp.latest(cfile)
+
+	cur, ok := records[key]
+	if !ok || cur.DeletedFlag || cur.UserID != data.UserID {
+		return data, storage.ErrNotFound
+	}
+
+	// check if the original url is already shortened in the scope
+	if m, found := kp.owning(records, data); found && storage.DataKey(m) != key {
+		return m, storage.ErrConflict
+	}
+
+	cur.OriginalURL = data.OriginalURL
+	cur.Tags = data.Tags
+
+	encoder := json.NewEncoder(cfile)
+	err = encoder.Encode(cur)
+	if err != nil {
+		kp.log.Info("cannot encode JSON data", zap.Error(err))
+		return data, err
+	}
+
+	return cur, nil
+}
+
+// CountsPath returns the path of the click counts file of the data file.
+func CountsPath(dataFile string) string {
+	return dataFile + ".counts"
+}
+
+// clickCount is a record of the click counts file: the number of the redirects the
+// click-limited url stored under the key has served.
+type clickCount struct {
+	Key    string `json:"key"`
+	Clicks int    `json:"clicks"`
+}
+
+// AddClick implements storage.Keeper.
+// The count of the clicks is appended to the click counts file next to the data file and
+// supersedes the counts of the previous records on Load, so that the redirects do not grow
+// the data file every insert scans. The caller serializes the clicks, the file is not
+// shared between processes.
+func (kp *FileKeeper) AddClick(key string, data models.DataURL) (models.DataURL, error) {
+	if data.ClicksExhausted() {
+		return data, storage.ErrClicksExhausted
+	}
+
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	cfile, err := os.OpenFile(CountsPath(kp.path()), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return data, err
+	}
+	defer cfile.Close()
+
+	data.Clicks++
+
+	encoder := json.NewEncoder(cfile)
+	err = encoder.Encode(clickCount{Key: key, Clicks: data.Clicks})
+	if err != nil {
+		kp.log.Info("cannot encode JSON data", zap.Error(err))
+		data.Clicks--
+		return data, err
+	}
+
+	return data, nil
+}
+
+// loadCounts sets the clicks of the urls to their latest counts of the click counts file.
+// The file is rewritten with the latest counts of the loaded urls only.
+func (kp *FileKeeper) loadCounts(data storage.StorageURL) error {
+	countsFile := CountsPath(kp.path())
+
+	src, err := os.Open(countsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer src.Close()
+
+	counts := make(map[string]int)
+	var order []string
+	decoder := json.NewDecoder(src)
+	for decoder.More() {
+		var c clickCount
+		if err = decoder.Decode(&c); err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			return err
+		}
+
+		if _, ok := data[c.Key]; !ok {
+			continue
+		}
+		if _, ok := counts[c.Key]; !ok {
+			order = append(order, c.Key)
+		}
+		counts[c.Key] = c.Clicks
+	}
+
+	return rewrite(countsFile, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, k := range order {
+			v := data[k]
+			v.Clicks = counts[k]
+			data[k] = v
+
+			if err := encoder.Encode(clickCount{Key: k, Clicks: v.Clicks}); err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// VariantsPath returns the path of the variant clicks file of the data file.
+func VariantsPath(dataFile string) string {
+	return dataFile + ".variants"
+}
+
+// variantCount is a record of the variant clicks file: a number of the redirects to the
+// variant, numbered from one, of the split url stored under the key.
+type variantCount struct {
+	Key     string `json:"key"`
+	Variant int    `json:"variant"`
+	Clicks  int    `json:"clicks"`
+}
+
+// AddVariantClick implements storage.Keeper.
+// The click is appended to the variant clicks file next to the data file and the clicks of
+// every variant are summed up on Load, so that the redirects do not grow the data file and
+// the order of the concurrent clicks does not matter.
+func (kp *FileKeeper) AddVariantClick(key string, data models.DataURL, variant int) (models.DataURL, error) {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	vfile, err := os.OpenFile(VariantsPath(kp.path()), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return data, err
+	}
+	defer vfile.Close()
+
+	encoder := json.NewEncoder(vfile)
+	err = encoder.Encode(variantCount{Key: key, Variant: variant, Clicks: 1})
+	if err != nil {
+		kp.log.Info("cannot encode JSON data", zap.Error(err))
+		return data, err
+	}
+
+	return data, nil
+}
+
+// loadVariantCounts sets the clicks of the variants of the urls to their sums of the
+// variant clicks file. The file is rewritten with a single record of the sum of every
+// variant of the loaded urls.
+func (kp *FileKeeper) loadVariantCounts(data storage.StorageURL) error {
+	variantsFile := VariantsPath(kp.path())
+
+	src, err := os.Open(variantsFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer src.Close()
+
+	type variantKey struct {
+		key     string
+		variant int
+	}
+	counts := make(map[variantKey]int)
+	var order []variantKey
+	decoder := json.NewDecoder(src)
+	for decoder.More() {
+		var c variantCount
+		if err = decoder.Decode(&c); err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			return err
+		}
+
+		if c.Variant < 1 || c.Variant > len(data[c.Key].Variants) {
+			continue
+		}
+		vk := variantKey{key: c.Key, variant: c.Variant}
+		if _, ok := counts[vk]; !ok {
+			order = append(order, vk)
+		}
+		counts[vk] += c.Clicks
+	}
+
+	return rewrite(variantsFile, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, vk := range order {
+			v := data[vk.key]
+			v.Variants[vk.variant-1].Clicks = counts[vk]
+
+			err := encoder.Encode(variantCount{Key: vk.key, Variant: vk.variant, Clicks: counts[vk]})
+			if err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // UpdateBatch implements storage.Keeper.
-func (*FileKeeper) UpdateBatch(...models.DeleteURL) error {
+// The deleted records are appended to the file and supersede the previous ones on Load.
+func (kp *FileKeeper) UpdateBatch(data ...models.DeleteURL) error {
+	now := time.Now().UTC()
+
+	return kp.update(func(m *models.DataURL) {
+		m.DeletedFlag = true
+		if m.DeletedAt == nil {
+			m.DeletedAt = &now
+		}
+	}, data...)
+}
+
+// RestoreBatch implements storage.Keeper.
+// The restored records are appended to the file and supersede the previous ones on Load.
+func (kp *FileKeeper) RestoreBatch(data ...models.DeleteURL) error {
+	return kp.update(func(m *models.DataURL) {
+		m.DeletedFlag = false
+		m.DeletedAt = nil
+	}, data...)
+}
+
+// update appends the records of the users' keys changed by fn to the file.
+func (kp *FileKeeper) update(fn func(*models.DataURL), data ...models.DeleteURL) error {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	cfile, err := os.OpenFile(kp.path(), os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer cfile.Close()
+
+	records := kp.latest(cfile)
+
+	encoder := json.NewEncoder(cfile)
+	for _, u := range data {
+		for _, k := range u.ShortURLs {
+			m, ok := records[k]
+			if !ok || m.UserID != u.UserID {
+				continue
+			}
+
+			fn(&m)
+			if err = encoder.Encode(m); err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Purge implements storage.Keeper.
+// The file is rewritten without the records of the purged urls, the user records are kept.
+// Like the database keeper, the click counts, the variant clicks, the clicks, rotated or
+// not, and the sketches of the purged urls are dropped, so that they are not counted for
+// the urls reusing their keys.
+func (kp *FileKeeper) Purge(shortURLs ...string) error {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	if len(shortURLs) == 0 {
+		return nil
+	}
+
+	purged := make(map[string]struct{}, len(shortURLs))
+	for _, u := range shortURLs {
+		purged[u] = struct{}{}
+	}
+	keys := make(map[string]struct{}, len(shortURLs))
+
+	dataFile := kp.path()
+	src, err := os.Open(dataFile)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer src.Close()
+
+	err = rewrite(dataFile, func(w io.Writer) error {
+		decoder := json.NewDecoder(src)
+		encoder := json.NewEncoder(w)
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+				return err
+			}
+
+			var m models.DataURL
+			if err := json.Unmarshal(raw, &m); err == nil && m.OriginalURL != "" {
+				if _, ok := purged[m.ShortURL]; ok {
+					keys[storage.DataKey(m)] = struct{}{}
+					continue
+				}
+			}
+
+			if err := encoder.Encode(raw); err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		kp.log.Info("cannot rewrite file: ", zap.Error(err))
+		return err
+	}
+
+	clicksFile := ClicksPath(dataFile)
+	files, err := filepath.Glob(clicksFile + ".*")
+	if err != nil {
+		return err
+	}
+	files = append(files, CountsPath(dataFile), VariantsPath(dataFile), clicksFile, SketchesPath(dataFile))
+
+	for _, name := range files {
+		if err = kp.dropKeys(name, keys); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// dropKeys rewrites the file of the records of the urls, the click counts, variant clicks,
+// clicks or sketches, without the records of the urls stored under the keys. A missing file has none.
+func (kp *FileKeeper) dropKeys(name string, keys map[string]struct{}) error {
+	src, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer src.Close()
+
+	err = rewrite(name, func(w io.Writer) error {
+		decoder := json.NewDecoder(src)
+		encoder := json.NewEncoder(w)
+		for decoder.More() {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+				return err
+			}
+
+			var r struct {
+				Key string `json:"key"`
+			}
+			if err := json.Unmarshal(raw, &r); err == nil {
+				if _, ok := keys[r.Key]; ok {
+					continue
+				}
+			}
+
+			if err := encoder.Encode(raw); err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		kp.log.Info("cannot rewrite file: ", zap.Error(err))
+	}
+
+	return err
+}
+
+// SequencePath returns the path of the short key sequence file of the data file.
+func SequencePath(dataFile string) string {
+	return dataFile + ".sequence"
+}
+
+// NextSequence implements storage.SequenceKeeper.
+// The numbers are reserved in blocks of sequenceBlock, the end of the reserved block is
+// stored in the sequence file next to the data file, so that the numbers issued before
+// a restart are skipped. Without the file the sequence continues from the number of
+// the url records of the data file.
+func (kp *FileKeeper) NextSequence() (uint64, error) {
+	kp.smx.Lock()
+	defer kp.smx.Unlock()
+
+	if kp.seqNext == kp.seqEnd {
+		start, err := kp.readSequence()
+		if err != nil {
+			return 0, err
+		}
+
+		end := start + sequenceBlock
+		err = rewrite(SequencePath(kp.path()), func(w io.Writer) error {
+			_, err := fmt.Fprintln(w, end)
+			return err
+		})
+		if err != nil {
+			kp.log.Info("cannot save sequence: ", zap.Error(err))
+			return 0, err
+		}
+		kp.seqNext, kp.seqEnd = start, end
+	}
+
+	kp.seqNext++
+
+	return kp.seqNext, nil
+}
+
+// readSequence returns the end of the last reserved block of the short key sequence.
+func (kp *FileKeeper) readSequence() (uint64, error) {
+	b, err := os.ReadFile(SequencePath(kp.path()))
+	if errors.Is(err, os.ErrNotExist) {
+		return kp.countRecords()
+	}
+	if err != nil {
+		kp.log.Info("cannot read file: ", zap.Error(err))
+		return 0, err
+	}
+
+	return strconv.ParseUint(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// countRecords returns the number of the url records of the data file, superseded or not.
+func (kp *FileKeeper) countRecords() (uint64, error) {
+	cfile, err := os.Open(kp.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return 0, err
+	}
+	defer cfile.Close()
+
+	var n uint64
+	decoder := json.NewDecoder(cfile)
+	for decoder.More() {
+		var m models.DataURL
+		if err = decoder.Decode(&m); err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			return 0, err
+		}
+
+		// user records share the file with url records
+		if m.OriginalURL != "" {
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// rewrite replaces the file with the content written by fn. The content is written to
+// a temporary file next to it, renamed over the file once complete. The temporary file
+// is hidden, so that it is not taken for a rotated clicks file.
+func rewrite(name string, fn func(w io.Writer) error) error {
+	dst, err := os.CreateTemp(path.Dir(name), "."+path.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(dst.Name())
+	defer dst.Close()
+
+	if err = dst.Chmod(0o644); err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(dst)
+	if err = fn(w); err != nil {
+		return err
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(dst.Name(), name)
+}
+
+// ClicksPath returns the path of the clicks file of the data file. The rotated clicks
+// files are named by the path with the time of the rotation appended.
+func ClicksPath(dataFile string) string {
+	return dataFile + ".clicks"
+}
+
+// SaveClicks implements storage.ClickKeeper.
+// The events are appended to the clicks file next to the data file. Once the file exceeds
+// MaxClicksFileSize it is rotated: renamed with the time of the rotation appended, and
+// a new file is started.
+func (kp *FileKeeper) SaveClicks(events ...models.ClickEvent) error {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	clicksFile := ClicksPath(kp.path())
+
+	if fi, err := os.Stat(clicksFile); err == nil && fi.Size() >= MaxClicksFileSize {
+		rotated := clicksFile + "." + time.Now().UTC().Format("20060102T150405.000000000")
+		if err = os.Rename(clicksFile, rotated); err != nil {
+			kp.log.Info("cannot rotate clicks file: ", zap.Error(err))
+			return err
+		}
+	}
+
+	cfile, err := os.OpenFile(clicksFile, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer cfile.Close()
+
+	// the batch is written at once
+	w := bufio.NewWriter(cfile)
+	encoder := json.NewEncoder(w)
+	for _, e := range events {
+		if err = encoder.Encode(e); err != nil {
+			kp.log.Info("cannot encode JSON data", zap.Error(err))
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// LoadClicks implements storage.ClickLoader.
+// The rotated clicks files are read in the order of their rotation, the current one last.
+func (kp *FileKeeper) LoadClicks(fn func(models.ClickEvent)) error {
+	clicksFile := ClicksPath(kp.path())
+
+	files, err := filepath.Glob(clicksFile + ".*")
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	files = append(files, clicksFile)
+
+	for _, name := range files {
+		if err = kp.readClicks(name, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readClicks calls fn for every click event of the clicks file, a missing file has none.
+func (kp *FileKeeper) readClicks(name string, fn func(models.ClickEvent)) error {
+	cfile, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer cfile.Close()
+
+	decoder := json.NewDecoder(cfile)
+	for decoder.More() {
+		var e models.ClickEvent
+		if err = decoder.Decode(&e); err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			return err
+		}
+		fn(e)
+	}
+
+	return nil
+}
+
+// SketchesPath returns the path of the sketches file of the data file.
+func SketchesPath(dataFile string) string {
+	return dataFile + ".sketches"
+}
+
+// SaveSketches implements storage.SketchKeeper.
+// The sketches are appended to the sketches file next to the data file, they are merged
+// with the ones saved before for the same URL and hour on load.
+func (kp *FileKeeper) SaveSketches(sketches ...models.ClickSketch) error {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	cfile, err := os.OpenFile(SketchesPath(kp.path()), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer cfile.Close()
+
+	// the batch is written at once
+	w := bufio.NewWriter(cfile)
+	encoder := json.NewEncoder(w)
+	for _, cs := range sketches {
+		if err = encoder.Encode(cs); err != nil {
+			kp.log.Info("cannot encode JSON data", zap.Error(err))
+			return err
+		}
+	}
+
+	return w.Flush()
+}
+
+// LoadSketches implements storage.SketchKeeper.
+// The sketches saved for the same URL and hour, by this server or copied from another one,
+// are merged, and the file is rewritten with the merged sketches only.
+func (kp *FileKeeper) LoadSketches(fn func(models.ClickSketch)) error {
+	kp.mx.Lock()
+	defer kp.mx.Unlock()
+
+	sketchesFile := SketchesPath(kp.path())
+
+	src, err := os.Open(sketchesFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		kp.log.Info("cannot open file: ", zap.Error(err))
+		return err
+	}
+	defer src.Close()
+
+	type hour struct {
+		key   string
+		start int64
+	}
+
+	merged := make(map[hour]*hll.Sketch)
+	var order []models.ClickSketch
+	decoder := json.NewDecoder(src)
+	for decoder.More() {
+		var cs models.ClickSketch
+		if err = decoder.Decode(&cs); err != nil {
+			kp.log.Info("cannot decode JSON file: ", zap.Error(err))
+			return err
+		}
+
+		visitors, err := hll.Parse(cs.Sketch)
+		if err != nil {
+			kp.log.Info("cannot parse sketch: ", zap.String("key", cs.Key), zap.Error(err))
+			continue
+		}
+
+		h := hour{key: cs.Key, start: cs.Start.Unix()}
+		if m, ok := merged[h]; ok {
+			m.Merge(visitors)
+			continue
+		}
+		merged[h] = visitors
+		order = append(order, models.ClickSketch{Key: cs.Key, Start: cs.Start})
+	}
+
+	return rewrite(sketchesFile, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, cs := range order {
+			var err error
+			if cs.Sketch, err = merged[hour{key: cs.Key, start: cs.Start.Unix()}].MarshalBinary(); err != nil {
+				return err
+			}
+			if err = encoder.Encode(cs); err != nil {
+				kp.log.Info("cannot encode JSON data", zap.Error(err))
+				return err
+			}
+			fn(cs)
+		}
+		return nil
+	})
+}
+
 // Ping implements storage.Keeper.
 func (kp *FileKeeper) Ping() bool { return true }
 
@@ -0,0 +1,286 @@
+package filekeeper
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/shorturl"
+	"github.com/wurt83ow/tinyurl/internal/storage"
+	"go.uber.org/zap"
+)
+
+// open opens the storage of the data file the way the application does, with the counter
+// based key generator continuing the sequence of the keeper.
+func open(t *testing.T, dataFile string) (*storage.MemoryStorage, shorturl.KeyGenerator) {
+	t.Helper()
+
+	keeper := NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+	memStorage := storage.NewMemoryStorage(keeper, zap.NewNop(), storage.ScopeGlobal)
+
+	keygen, err := shorturl.NewKeyGenerator(shorturl.StrategyCounter, 0, "", "",
+		shorturl.SequenceFunc(memStorage.NextSequence))
+	if err != nil {
+		t.Fatalf("NewKeyGenerator return error %v", err)
+	}
+
+	return memStorage, keygen
+}
+
+// shorten shortens the original URL for the user with the key generator.
+func shorten(t *testing.T, memStorage *storage.MemoryStorage, keygen shorturl.KeyGenerator, originalURL string) (string, error) {
+	t.Helper()
+
+	key, err := keygen.Generate(originalURL)
+	if err != nil {
+		t.Fatalf("Generate return error %v", err)
+	}
+
+	_, err = memStorage.InsertURL(key, models.DataURL{OriginalURL: originalURL,
+		ShortURL: "http://localhost:8080/" + key, UserID: "some_user_UUID"})
+
+	return key, err
+}
+
+func TestNextSequence(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	keeper := NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+	var last uint64
+	for i := 0; i < 3; i++ {
+		n, err := keeper.NextSequence()
+		if err != nil {
+			t.Fatalf("NextSequence return error %v", err)
+		}
+		if n <= last {
+			t.Errorf("NextSequence return %d; want more than %d", n, last)
+		}
+		last = n
+	}
+
+	// after a restart the numbers issued before are not issued again
+	keeper = NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+	n, err := keeper.NextSequence()
+	if err != nil {
+		t.Fatalf("NextSequence return error %v", err)
+	}
+	if n <= last {
+		t.Errorf("NextSequence return %d after restart; want more than %d", n, last)
+	}
+}
+
+func TestShortenAfterPurge(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	memStorage, keygen := open(t, dataFile)
+	var keys []string
+	for _, u := range []string{"https://a.example.com/", "https://b.example.com/", "https://c.example.com/"} {
+		key, err := shorten(t, memStorage, keygen, u)
+		if err != nil {
+			t.Fatalf("InsertURL(%q) return error %v", u, err)
+		}
+		keys = append(keys, key)
+	}
+
+	err := memStorage.DeleteURLs(models.DeleteURL{UserID: "some_user_UUID", ShortURLs: keys[:1]})
+	if err != nil {
+		t.Fatalf("DeleteURLs return error %v", err)
+	}
+	if n, err := memStorage.PurgeURLs(time.Now().Add(time.Hour)); n != 1 || err != nil {
+		t.Fatalf("PurgeURLs return %d, %v; want 1, nil", n, err)
+	}
+
+	// after a restart there are fewer stored URLs than issued keys, the keys of the
+	// live URLs are not issued again
+	memStorage, keygen = open(t, dataFile)
+	key, err := shorten(t, memStorage, keygen, "https://d.example.com/")
+	if err != nil {
+		t.Fatalf("InsertURL return error %v", err)
+	}
+	for _, k := range keys {
+		if key == k {
+			t.Errorf("Generate return the issued key %q", key)
+		}
+	}
+
+	for _, k := range keys[1:] {
+		if _, err = memStorage.GetURL(k); err != nil {
+			t.Errorf("GetURL(%q) return error %v", k, err)
+		}
+	}
+}
+
+func TestAddClick(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	memStorage, _ := open(t, dataFile)
+	_, err := memStorage.InsertURL("limited", models.DataURL{OriginalURL: "https://a.example.com/",
+		ShortURL: "http://localhost:8080/limited", UserID: "some_user_UUID", MaxClicks: 5})
+	if err != nil {
+		t.Fatalf("InsertURL return error %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = memStorage.UseClick("limited"); err != nil {
+			t.Fatalf("UseClick return error %v", err)
+		}
+	}
+
+	// the clicks do not grow the data file
+	keeper := NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+	if n, err := keeper.countRecords(); n != 1 || err != nil {
+		t.Errorf("data file has %d url records, %v; want 1", n, err)
+	}
+
+	memStorage, _ = open(t, dataFile)
+	if v, _ := memStorage.GetURL("limited"); v.Clicks != 3 {
+		t.Errorf("GetURL return %d clicks after a restart; want 3", v.Clicks)
+	}
+}
+
+func TestAddVariantClick(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	memStorage, _ := open(t, dataFile)
+	_, err := memStorage.InsertURL("split", models.DataURL{OriginalURL: "https://a.example.com/",
+		ShortURL: "http://localhost:8080/split", UserID: "some_user_UUID",
+		Variants: []models.Variant{{URL: "https://a.example.com/", Weight: 1}, {URL: "https://b.example.com/", Weight: 1}}})
+	if err != nil {
+		t.Fatalf("InsertURL return error %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(variant int) {
+			defer wg.Done()
+			if err := memStorage.AddVariantClick("split", variant); err != nil {
+				t.Errorf("AddVariantClick return error %v", err)
+			}
+		}(i%2 + 1)
+	}
+	wg.Wait()
+
+	// the clicks do not grow the data file
+	keeper := NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+	if n, err := keeper.countRecords(); n != 1 || err != nil {
+		t.Errorf("data file has %d url records, %v; want 1", n, err)
+	}
+
+	for i := 0; i < 2; i++ {
+		memStorage, _ = open(t, dataFile)
+		if v, _ := memStorage.GetURL("split"); v.Variants[0].Clicks != 5 || v.Variants[1].Clicks != 5 {
+			t.Errorf("GetURL return the variants %v after a restart; want 5 clicks each", v.Variants)
+		}
+	}
+}
+
+func TestRestoreURLs(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	memStorage, _ := open(t, dataFile)
+	for _, k := range []string{"abc", "xabcd"} {
+		_, err := memStorage.InsertURL(k, models.DataURL{OriginalURL: "https://" + k + ".example.com/",
+			ShortURL: "http://localhost:8080/" + k, UserID: "some_user_UUID"})
+		if err != nil {
+			t.Fatalf("InsertURL return error %v", err)
+		}
+	}
+
+	err := memStorage.DeleteURLs(models.DeleteURL{UserID: "some_user_UUID", ShortURLs: []string{"abc", "xabcd"}})
+	if err != nil {
+		t.Fatalf("DeleteURLs return error %v", err)
+	}
+
+	// only the URL of the key is restored, not the ones of the keys containing it
+	err = memStorage.RestoreURLs(models.DeleteURL{UserID: "some_user_UUID", ShortURLs: []string{"abc"}})
+	if err != nil {
+		t.Fatalf("RestoreURLs return error %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if v, _ := memStorage.GetURL("abc"); v.DeletedFlag {
+			t.Errorf("GetURL return the restored url deleted")
+		}
+		if v, _ := memStorage.GetURL("xabcd"); !v.DeletedFlag {
+			t.Errorf("GetURL return the deleted url restored")
+		}
+
+		memStorage, _ = open(t, dataFile)
+	}
+}
+
+func TestPurge(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+	keeper := NewFileKeeper(func() string { return dataFile }, zap.NewNop(), storage.ScopeGlobal)
+
+	for _, k := range []string{"purged", "kept"} {
+		data := models.DataURL{OriginalURL: "https://" + k + ".example.com/", ShortURL: "http://localhost:8080/" + k,
+			UserID: "some_user_UUID", MaxClicks: 5}
+		if _, err := keeper.Save(k, data); err != nil {
+			t.Fatalf("Save return error %v", err)
+		}
+		if _, err := keeper.AddClick(k, data); err != nil {
+			t.Fatalf("AddClick return error %v", err)
+		}
+		if err := keeper.SaveClicks(models.ClickEvent{Time: time.Now(), Key: k}); err != nil {
+			t.Fatalf("SaveClicks return error %v", err)
+		}
+		if err := keeper.SaveSketches(models.ClickSketch{Key: k, Start: time.Now().Truncate(time.Hour),
+			Sketch: []byte{1, 12, 0, 0}}); err != nil {
+			t.Fatalf("SaveSketches return error %v", err)
+		}
+	}
+
+	// the clicks file rotated before the purge is purged as well
+	if err := os.Rename(ClicksPath(dataFile), ClicksPath(dataFile)+".20261017T000000.000000000"); err != nil {
+		t.Fatal(err)
+	}
+
+	err := keeper.UpdateBatch(models.DeleteURL{UserID: "some_user_UUID", ShortURLs: []string{"purged"}})
+	if err != nil {
+		t.Fatalf("UpdateBatch return error %v", err)
+	}
+
+	// the records written while the file is rewritten are kept
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			email := fmt.Sprintf("user%d@example.com", i)
+			if _, err := keeper.SaveUser(email, models.DataUser{Email: email}); err != nil {
+				t.Errorf("SaveUser return error %v", err)
+			}
+		}
+	}()
+	for i := 0; i < 5; i++ {
+		if err = keeper.Purge("http://localhost:8080/purged"); err != nil {
+			t.Errorf("Purge return error %v", err)
+		}
+	}
+	wg.Wait()
+
+	users, _ := keeper.LoadUsers()
+	for i := 0; i < 50; i++ {
+		if email := fmt.Sprintf("user%d@example.com", i); users[email].Email != email {
+			t.Errorf("LoadUsers return no user %q", email)
+		}
+	}
+
+	data, _ := keeper.Load()
+	if _, ok := data["purged"]; ok || data["kept"].Clicks != 1 {
+		t.Errorf("Load return %v; want the kept url with 1 click only", data)
+	}
+
+	var keys []string
+	_ = keeper.LoadClicks(func(e models.ClickEvent) { keys = append(keys, e.Key) })
+	_ = keeper.LoadSketches(func(cs models.ClickSketch) { keys = append(keys, cs.Key) })
+	if len(keys) != 2 || keys[0] != "kept" || keys[1] != "kept" {
+		t.Errorf("LoadClicks and LoadSketches return the keys %v; want the kept url only", keys)
+	}
+}
@@ -1,12 +1,86 @@
 // Package models provides data structures used in the application.
 package models
 
+import "time"
+
 // Key is an alias for string and represents a key used in various contexts.
 type Key string
 
+// LinkOptions describes the optional settings of a link.
+type LinkOptions struct {
+	// ExpiresAt is the time the link stops working at.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// TTL is the time to live of the link, e.g. "24h". It is an alternative to ExpiresAt.
+	TTL string `json:"ttl,omitempty"`
+	// MaxClicks is the number of redirects the link serves, zero means unlimited.
+	MaxClicks int `json:"max_clicks,omitempty"`
+	// Password protects the link, it is required to follow the redirect.
+	Password string `json:"password,omitempty"`
+	// RedirectCode is the HTTP status code the link redirects with, zero means the server default.
+	RedirectCode int `json:"redirect_code,omitempty"`
+	// QueryPolicy decides what happens to the query string the link is opened with:
+	// "drop" (default), "merge" or "utm".
+	QueryPolicy string `json:"query_policy,omitempty"`
+	// UTMSource, UTMMedium and UTMCampaign are appended to the original URL by the "utm" policy.
+	UTMSource   string `json:"utm_source,omitempty"`
+	UTMMedium   string `json:"utm_medium,omitempty"`
+	UTMCampaign string `json:"utm_campaign,omitempty"`
+	// Rules are the targeting rules of the link, the first matching rule wins.
+	Rules []Rule `json:"rules,omitempty"`
+	// Variants split the traffic of the link not matching a rule across several
+	// destinations by their weights.
+	Variants []Variant `json:"variants,omitempty"`
+	// Sticky keeps sending a visitor to the same variant, remembered by a cookie.
+	Sticky bool `json:"sticky,omitempty"`
+	// Tags organize the links of the user, e.g. "marketing".
+	Tags []string `json:"tags,omitempty"`
+	// Note is a free-form description of the link for its owner.
+	Note string `json:"note,omitempty"`
+	// Domain is the name of the domain the short URL is served under, e.g. "go.example.com",
+	// empty means the default domain.
+	Domain string `json:"domain,omitempty"`
+}
+
+// Rule is a targeting rule of a link: the clients matching all of its conditions
+// are redirected to URL instead of the original URL.
+type Rule struct {
+	// Platform is the platform of the client detected from its User-Agent:
+	// ios, android, windows, macos or linux.
+	Platform string `db:"platform" json:"platform,omitempty"`
+	// Language is a language the client accepts, e.g. "de" or "pt-BR".
+	Language string `db:"language" json:"language,omitempty"`
+	// Referrer is the host of the referring page, its subdomains match too.
+	Referrer string `db:"referrer" json:"referrer,omitempty"`
+	// Country is the ISO 3166-1 alpha-2 code of the country the client IP address
+	// is located in, e.g. "US".
+	Country string `db:"country" json:"country,omitempty"`
+	// URL is the destination of the matching clients.
+	URL string `db:"url" json:"url"`
+}
+
 // Request describes the user's request.
 type Request struct {
-	URL string `json:"url"`
+	URL   string `json:"url"`
+	Alias string `json:"alias,omitempty"`
+	LinkOptions
+}
+
+// UpdateRequest describes the user's request to change the original URL or the tags of a link.
+type UpdateRequest struct {
+	URL string `json:"url,omitempty"`
+	// Tags replace the tags of the link if set, an empty list removes them.
+	Tags *[]string `json:"tags,omitempty"`
+}
+
+// Variant is a destination of a split link receiving the share of its traffic
+// given by the weight.
+type Variant struct {
+	// URL is the destination of the variant.
+	URL string `db:"url" json:"url"`
+	// Weight is the relative share of the traffic of the variant, e.g. 70 and 30.
+	Weight int `db:"weight" json:"weight"`
+	// Clicks is the number of redirects to the variant.
+	Clicks int `db:"clicks" json:"clicks,omitempty"`
 }
 
 // Response describes the server's response.
@@ -14,20 +88,63 @@ type Response struct {
 	Result string `json:"result"`
 }
 
+// ErrorResponse describes the server's response for a rejected request.
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+	Field   string `json:"field,omitempty"`
+}
+
 // DataURLite represents a simplified version of data related to a URL.
 type DataURLite struct {
-	UUID        string `db:"correlation_id" json:"correlation_id"`
-	ShortURL    string `db:"short_url" json:"short_url"`
-	OriginalURL string `db:"original_url" json:"original_url"`
+	UUID        string     `db:"correlation_id" json:"correlation_id"`
+	ShortURL    string     `db:"short_url" json:"short_url"`
+	OriginalURL string     `db:"original_url" json:"original_url"`
+	Deleted     bool       `db:"is_deleted" json:"is_deleted,omitempty"`
+	CreatedAt   *time.Time `db:"created_at" json:"created_at,omitempty"`
+	LinkOptions
 }
 
 // DataURL represents data related to a URL.
 type DataURL struct {
-	UUID        string `db:"correlation_id" json:"result"`
-	ShortURL    string `db:"short_url" json:"short_url"`
-	OriginalURL string `db:"original_url" json:"original_url"`
-	UserID      string `db:"user_id" json:"user_id"`
-	DeletedFlag bool   `db:"is_deleted" json:"is_deleted"`
+	UUID         string     `db:"correlation_id" json:"result"`
+	ShortURL     string     `db:"short_url" json:"short_url"`
+	OriginalURL  string     `db:"original_url" json:"original_url"`
+	UserID       string     `db:"user_id" json:"user_id"`
+	DeletedFlag  bool       `db:"is_deleted" json:"is_deleted"`
+	ExpiresAt    *time.Time `db:"expires_at" json:"expires_at,omitempty"`
+	MaxClicks    int        `db:"max_clicks" json:"max_clicks,omitempty"`
+	Clicks       int        `db:"clicks" json:"clicks,omitempty"`
+	PasswordHash []byte     `db:"password_hash" json:"password_hash,omitempty"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	RedirectCode int        `db:"redirect_code" json:"redirect_code,omitempty"`
+	QueryPolicy  string     `db:"query_policy" json:"query_policy,omitempty"`
+	UTMSource    string     `db:"utm_source" json:"utm_source,omitempty"`
+	UTMMedium    string     `db:"utm_medium" json:"utm_medium,omitempty"`
+	UTMCampaign  string     `db:"utm_campaign" json:"utm_campaign,omitempty"`
+	Sticky       bool       `db:"sticky" json:"sticky,omitempty"`
+	CreatedAt    *time.Time `db:"created_at" json:"created_at,omitempty"`
+	Note         string     `db:"note" json:"note,omitempty"`
+	Domain       string     `db:"domain" json:"domain,omitempty"`
+	Rules        []Rule     `db:"-" json:"rules,omitempty"`
+	Variants     []Variant  `db:"-" json:"variants,omitempty"`
+	Tags         []string   `db:"-" json:"tags,omitempty"`
+}
+
+// Expired reports whether the link has expired at the time now.
+func (d DataURL) Expired(now time.Time) bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(now)
+}
+
+// ClicksExhausted reports whether the link has served all of its redirects.
+func (d DataURL) ClicksExhausted() bool {
+	return d.MaxClicks > 0 && d.Clicks >= d.MaxClicks
+}
+
+// Protected reports whether the link requires a password.
+func (d DataURL) Protected() bool {
+	return len(d.PasswordHash) != 0
 }
 
 // DataUser represents data related to a user.
@@ -61,3 +178,62 @@ type StatsResponse struct {
 	Urls  int `json:"urls"`
 	Users int `json:"users"`
 }
+
+// ClickEvent describes a redirect served by a short URL. The URL is identified by its
+// storage key, the IP address of the client is truncated to its network.
+type ClickEvent struct {
+	Time      time.Time `db:"clicked_at" json:"time"`
+	Key       string    `db:"short_url" json:"key"`
+	Referrer  string    `db:"referrer" json:"referrer,omitempty"`
+	UserAgent string    `db:"user_agent" json:"user_agent,omitempty"`
+	IP        string    `db:"ip" json:"ip,omitempty"`
+	UserID    string    `db:"user_id" json:"user_id,omitempty"`
+	Country   string    `db:"country" json:"country,omitempty"`
+}
+
+// Visitor returns the identity of the visitor of the click: the user if known,
+// the truncated IP address and the user agent otherwise.
+func (e ClickEvent) Visitor() string {
+	if e.UserID != "" {
+		return "u:" + e.UserID
+	}
+
+	return "a:" + e.IP + "|" + e.UserAgent
+}
+
+// ClickSketch is the serialized HyperLogLog sketch of the visitors of a short URL,
+// identified by its storage key, in the hour starting at Start.
+type ClickSketch struct {
+	Key    string    `json:"key"`
+	Start  time.Time `json:"start"`
+	Sketch []byte    `json:"sketch"`
+}
+
+// URLStats describes the clicks of a short URL over a range of time, the end excluded.
+// The unique visitors are estimated with the relative standard error UniqueError.
+type URLStats struct {
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Interval    string        `json:"interval"`
+	Clicks      int           `json:"clicks"`
+	Unique      int           `json:"unique"`
+	UniqueError float64       `json:"unique_error"`
+	Buckets     []StatsBucket `json:"buckets"`
+	Referrers   []StatsCount  `json:"top_referrers"`
+	Countries   []StatsCount  `json:"top_countries"`
+	UserAgents  []StatsCount  `json:"top_user_agents"`
+}
+
+// StatsBucket describes the clicks of a short URL in the bucket of time starting at Start.
+type StatsBucket struct {
+	Start  time.Time `json:"start"`
+	Clicks int       `json:"clicks"`
+	Unique int       `json:"unique"`
+}
+
+// StatsCount is the number of the clicks of a short URL with a value of a dimension,
+// e.g. a referrer.
+type StatsCount struct {
+	Value  string `json:"value"`
+	Clicks int    `json:"clicks"`
+}
@@ -0,0 +1,118 @@
+// Package domains resolves the domains the short URLs are served under.
+//
+// The default domain is the one of the base URL of the server, the additional branded
+// domains are configured by their base URLs. A domain is named by the lower-case host
+// and port of its base URL, e.g. "go.example.com". The links of the default domain are
+// bound to the empty name, so that the links created before the additional domains were
+// configured stay on it.
+package domains
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// ErrUnknownDomain indicates that the domain is not configured.
+var ErrUnknownDomain = errors.New("unknown domain")
+
+// Set is the set of the domains the short URLs are served under.
+type Set struct {
+	base  string
+	name  string
+	bases map[string]string
+}
+
+// New returns the set of the default domain of the base URL and the additional domains
+// of the comma-separated base URLs of the list. The malformed base URLs of the list are
+// skipped and reported in the error, so that the returned set is usable either way.
+func New(base string, list string) (*Set, error) {
+	s := &Set{base: base, bases: make(map[string]string)}
+	s.name, _ = Name(base)
+
+	var errs []error
+	for _, b := range strings.Split(list, ",") {
+		b = strings.TrimSpace(b)
+		if b == "" {
+			continue
+		}
+
+		name, err := Name(b)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if name != s.name {
+			s.bases[name] = b
+		}
+	}
+
+	return s, errors.Join(errs...)
+}
+
+// Name returns the name of the domain of the base URL.
+func Name(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid domain base URL %q", base)
+	}
+
+	return strings.ToLower(u.Host), nil
+}
+
+// Resolve returns the name the links of the requested domain are bound to: the empty
+// name for the default domain, requested by an empty name or its own, or the name of
+// the additional domain. It returns ErrUnknownDomain if the domain is not configured.
+func (s *Set) Resolve(domain string) (string, error) {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if domain == "" || domain == s.name {
+		return "", nil
+	}
+
+	if _, ok := s.bases[domain]; !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownDomain, domain)
+	}
+
+	return domain, nil
+}
+
+// Serving returns the name of the domain a request for the host of the Host header is
+// served on: the additional domain named by the host, with or without its port, or
+// the default domain for any other host.
+func (s *Set) Serving(host string) string {
+	host = strings.ToLower(host)
+	if _, ok := s.bases[host]; ok {
+		return host
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		if _, ok := s.bases[h]; ok {
+			return h
+		}
+	}
+
+	return ""
+}
+
+// Base returns the base URL of the short URLs of the resolved domain, the default one
+// for the empty name or an unknown domain.
+func (s *Set) Base(domain string) string {
+	if b, ok := s.bases[domain]; ok {
+		return b
+	}
+
+	return s.base
+}
+
+// Bases returns the base URLs of all the domains, the default one first.
+func (s *Set) Bases() []string {
+	bases := make([]string, 0, len(s.bases)+1)
+	bases = append(bases, s.base)
+	for _, b := range s.bases {
+		bases = append(bases, b)
+	}
+
+	return bases
+}
@@ -0,0 +1,66 @@
+package domains
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	s, err := New("http://localhost:8080/", " https://Go.Example.com/ , ftp, http://localhost:8080/")
+	if err == nil {
+		t.Errorf("New() error = nil; want the malformed base URL reported")
+	}
+
+	if got := s.Base("go.example.com"); got != "https://Go.Example.com/" {
+		t.Errorf("Base(go.example.com) = %q; want https://Go.Example.com/", got)
+	}
+	if got := s.Base(""); got != "http://localhost:8080/" {
+		t.Errorf("Base() = %q; want the default base URL", got)
+	}
+	if got := len(s.Bases()); got != 2 {
+		t.Errorf("len(Bases()) = %d; want 2", got)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	s, err := New("http://localhost:8080/", "https://go.example.com/")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	testCases := []struct {
+		domain string
+		want   string
+		err    error
+	}{
+		{domain: "", want: ""},
+		{domain: "localhost:8080", want: ""},
+		{domain: " GO.example.com", want: "go.example.com"},
+		{domain: "example.org", err: ErrUnknownDomain},
+	}
+
+	for _, tc := range testCases {
+		got, err := s.Resolve(tc.domain)
+		if !errors.Is(err, tc.err) || got != tc.want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q, %v", tc.domain, got, err, tc.want, tc.err)
+		}
+	}
+}
+
+func TestServing(t *testing.T) {
+	s, err := New("http://localhost:8080/", "https://go.example.com/")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for host, want := range map[string]string{
+		"go.example.com":     "go.example.com",
+		"Go.Example.com:443": "go.example.com",
+		"localhost:8080":     "",
+		"example.org":        "",
+	} {
+		if got := s.Serving(host); got != want {
+			t.Errorf("Serving(%q) = %q; want %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package geoip resolves the countries of the client IP addresses against a local
+// MaxMind-format (.mmdb) database file. It does no network lookups.
+package geoip
+
+import (
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record is the part of a GeoIP2 or GeoLite2 Country or City record used for the lookups.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// Locator looks up the countries of the IP addresses. The database file can be
+// reloaded while the lookups are served. The nil Locator knows no countries.
+type Locator struct {
+	path string
+	mu   sync.RWMutex
+	db   *maxminddb.Reader
+}
+
+// Open opens the database file at path.
+func Open(path string) (*Locator, error) {
+	db, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Locator{path: path, db: db}, nil
+}
+
+// Reload reopens the database file, e.g. after it has been updated. The lookups keep
+// using the previous database until the new one is open, and on error.
+func (l *Locator) Reload() error {
+	db, err := maxminddb.Open(l.path)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	old := l.db
+	l.db = db
+	l.mu.Unlock()
+
+	return old.Close()
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of the IP address,
+// or an empty string if the address is malformed or its country is unknown.
+// A comma-separated list of addresses, as in X-Forwarded-For, is resolved by its
+// first address, the client.
+func (l *Locator) Country(ip string) string {
+	if l == nil {
+		return ""
+	}
+
+	first, _, _ := strings.Cut(ip, ",")
+	addr := net.ParseIP(strings.TrimSpace(first))
+	if addr == nil {
+		return ""
+	}
+
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var r record
+	if err := l.db.Lookup(addr, &r); err != nil {
+		return ""
+	}
+
+	return r.Country.ISOCode
+}
+
+// Close closes the database file.
+func (l *Locator) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.db.Close()
+}
@@ -0,0 +1,131 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeDB writes an IPv4 MaxMind DB file to path resolving 0.0.0.0/1 to the country
+// low and 128.0.0.0/1 to the country high.
+func writeDB(t *testing.T, path string, low, high string) {
+	t.Helper()
+
+	str := func(s string) []byte { return append([]byte{0x40 | byte(len(s))}, s...) }
+	uint16v := func(n uint16) []byte { return []byte{0xa0 | 2, byte(n >> 8), byte(n)} }
+	uint32v := func(n uint32) []byte {
+		b := []byte{0xc0 | 4, 0, 0, 0, 0}
+		binary.BigEndian.PutUint32(b[1:], n)
+		return b
+	}
+	country := func(code string) []byte {
+		b := []byte{0xe0 | 1}
+		b = append(b, str("country")...)
+		b = append(b, 0xe0|1)
+		b = append(b, str("iso_code")...)
+		return append(b, str(code)...)
+	}
+
+	// The data section holds the records of both countries
+	lowData := country(low)
+	data := append(lowData, country(high)...)
+
+	// The search tree has a single node with 24-bit records pointing to the data section
+	const nodeCount = 1
+	var buf bytes.Buffer
+	for _, offset := range []int{0, len(lowData)} {
+		v := nodeCount + 16 + offset
+		buf.Write([]byte{byte(v >> 16), byte(v >> 8), byte(v)})
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(data)
+
+	// The metadata describes the tree
+	buf.WriteString("\xAB\xCD\xEFMaxMind.com")
+	buf.WriteByte(0xe0 | 9)
+	for _, kv := range [][2][]byte{
+		{str("node_count"), uint32v(nodeCount)},
+		{str("record_size"), uint16v(24)},
+		{str("ip_version"), uint16v(4)},
+		{str("database_type"), str("Test-Country")},
+		{str("languages"), {0x00, 0x04}},
+		{str("binary_format_major_version"), uint16v(2)},
+		{str("binary_format_minor_version"), uint16v(0)},
+		{str("build_epoch"), uint32v(0)},
+		{str("description"), {0xe0}},
+	} {
+		buf.Write(kv[0])
+		buf.Write(kv[1])
+	}
+
+	replaceFile(t, path, buf.Bytes())
+}
+
+// replaceFile atomically replaces the file at path as the database updaters do,
+// since the open database file is memory-mapped.
+func replaceFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLocator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "country.mmdb")
+	writeDB(t, path, "US", "DE")
+
+	l, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer l.Close()
+
+	testCases := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "8.8.8.8", want: "US"},
+		{ip: "192.0.2.1", want: "DE"},
+		{ip: "192.0.2.1, 10.0.0.1", want: "DE"},
+		{ip: "not an ip", want: ""},
+		{ip: "", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := l.Country(tc.ip); got != tc.want {
+			t.Errorf("Country(%q) = %q; want %q", tc.ip, got, tc.want)
+		}
+	}
+
+	// The updated database file is used after a reload
+	writeDB(t, path, "FR", "JP")
+	if err = l.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := l.Country("8.8.8.8"); got != "FR" {
+		t.Errorf("Country() after Reload = %q; want FR", got)
+	}
+
+	// A broken database file keeps the previous one in use
+	replaceFile(t, path, []byte("broken"))
+	if err = l.Reload(); err == nil {
+		t.Errorf("Reload() of a broken file error = nil")
+	}
+	if got := l.Country("192.0.2.1"); got != "JP" {
+		t.Errorf("Country() after a failed Reload = %q; want JP", got)
+	}
+}
+
+func TestNilLocator(t *testing.T) {
+	var l *Locator
+	if got := l.Country("8.8.8.8"); got != "" {
+		t.Errorf("Country() of the nil Locator = %q; want empty", got)
+	}
+}
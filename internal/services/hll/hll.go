@@ -0,0 +1,243 @@
+// Package hll estimates the number of the distinct values with HyperLogLog sketches.
+//
+// A sketch keeps 2^Precision registers with the maximum rank of the hashes of the values
+// and estimates the number of the distinct values with the relative standard error of
+// StdError. The sketches are merged by the maximum of their registers, so that the sketches
+// of the visitors of different hours or of different servers are united without counting
+// a visitor twice, and merging a sketch again changes nothing. A sketch of a few values
+// keeps its registers sparse and turns dense as it grows.
+package hll
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// Precision is the number of the bits of the hashes indexing the registers.
+const Precision = 12
+
+// registers is the number of the registers of a sketch.
+const registers = 1 << Precision
+
+// sparseMax is the number of the registers a sparse sketch keeps at most.
+const sparseMax = registers / 16
+
+// version is the version of the binary format of the sketches.
+const version = 1
+
+// Encodings of the registers of the binary format.
+const (
+	encodingSparse = 0
+	encodingDense  = 1
+)
+
+// ErrInvalidSketch indicates that the binary sketch is malformed or of another precision.
+var ErrInvalidSketch = errors.New("invalid sketch")
+
+// Sketch is a HyperLogLog sketch. The zero value is not usable, New returns an empty sketch.
+// It is not safe for concurrent use.
+type Sketch struct {
+	sparse map[uint16]uint8
+	dense  []uint8
+}
+
+// New returns an empty sketch.
+func New() *Sketch {
+	return &Sketch{sparse: make(map[uint16]uint8)}
+}
+
+// StdError returns the relative standard error of the estimates.
+func StdError() float64 {
+	return 1.04 / math.Sqrt(registers)
+}
+
+// hash returns the 64-bit hash of the value. The FNV hash is mixed by the finalizer of
+// MurmurHash3 for the uniformly distributed bits HyperLogLog needs; unlike the hashes of
+// hash/maphash it is the same on every server, so that their sketches can be merged.
+func hash(v string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(v))
+	x := h.Sum64()
+
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
+
+// Add adds the value to the sketch.
+func (s *Sketch) Add(v string) {
+	x := hash(v)
+
+	// the first bits index the register, the rank is the position of the first set bit
+	// of the rest, bounded by a set bit past its end
+	idx := uint16(x >> (64 - Precision))
+	rank := uint8(bits.LeadingZeros64(x<<Precision|1<<(Precision-1))) + 1
+
+	s.set(idx, rank)
+}
+
+// set raises the register to the rank.
+func (s *Sketch) set(idx uint16, rank uint8) {
+	if s.dense != nil {
+		if rank > s.dense[idx] {
+			s.dense[idx] = rank
+		}
+		return
+	}
+
+	if rank > s.sparse[idx] {
+		s.sparse[idx] = rank
+	}
+
+	if len(s.sparse) > sparseMax {
+		s.dense = make([]uint8, registers)
+		for i, r := range s.sparse {
+			s.dense[i] = r
+		}
+		s.sparse = nil
+	}
+}
+
+// Merge adds the values of the other sketch to the sketch.
+func (s *Sketch) Merge(o *Sketch) {
+	if o.dense != nil {
+		for i, r := range o.dense {
+			if r != 0 {
+				s.set(uint16(i), r)
+			}
+		}
+		return
+	}
+
+	for i, r := range o.sparse {
+		s.set(i, r)
+	}
+}
+
+// Estimate returns the estimated number of the distinct values added to the sketch.
+func (s *Sketch) Estimate() uint64 {
+	var sum float64
+	zeros := 0
+
+	if s.dense != nil {
+		for _, r := range s.dense {
+			if r == 0 {
+				zeros++
+			}
+			sum += math.Ldexp(1, -int(r))
+		}
+	} else {
+		zeros = registers - len(s.sparse)
+		sum = float64(zeros)
+		for _, r := range s.sparse {
+			sum += math.Ldexp(1, -int(r))
+		}
+	}
+
+	m := float64(registers)
+	e := 0.7213 / (1 + 1.079/m) * m * m / sum
+
+	// the small cardinalities are estimated by linear counting of the empty registers
+	if e <= 2.5*m && zeros != 0 {
+		e = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(e + 0.5)
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler. The sketch is encoded by the version
+// of the format, the precision and its registers: the sparse ones as pairs of the
+// increments of their indexes and their ranks, the dense ones as they are.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	if s.dense != nil {
+		b := make([]byte, 0, 3+registers)
+		b = append(b, version, Precision, encodingDense)
+		return append(b, s.dense...), nil
+	}
+
+	idx := make([]int, 0, len(s.sparse))
+	for i := range s.sparse {
+		idx = append(idx, int(i))
+	}
+	sort.Ints(idx)
+
+	b := make([]byte, 0, 3+binary.MaxVarintLen16+len(idx)*(binary.MaxVarintLen16+1))
+	b = append(b, version, Precision, encodingSparse)
+	b = binary.AppendUvarint(b, uint64(len(idx)))
+	prev := 0
+	for _, i := range idx {
+		b = binary.AppendUvarint(b, uint64(i-prev))
+		b = append(b, s.sparse[uint16(i)])
+		prev = i
+	}
+
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler. It returns ErrInvalidSketch
+// if the data is malformed or of another version or precision.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < 3 || data[0] != version || data[1] != Precision {
+		return ErrInvalidSketch
+	}
+
+	switch data[2] {
+	case encodingDense:
+		if len(data) != 3+registers {
+			return ErrInvalidSketch
+		}
+		s.sparse = nil
+		s.dense = append(make([]uint8, 0, registers), data[3:]...)
+		return nil
+	case encodingSparse:
+	default:
+		return ErrInvalidSketch
+	}
+
+	data = data[3:]
+	n, k := binary.Uvarint(data)
+	if k <= 0 || n > sparseMax {
+		return ErrInvalidSketch
+	}
+	data = data[k:]
+
+	s.dense = nil
+	s.sparse = make(map[uint16]uint8, n)
+	i := uint64(0)
+	for ; n != 0; n-- {
+		d, k := binary.Uvarint(data)
+		if k <= 0 || len(data) <= k {
+			return ErrInvalidSketch
+		}
+		i += d
+		if i >= registers {
+			return ErrInvalidSketch
+		}
+		s.sparse[uint16(i)] = data[k]
+		data = data[k+1:]
+	}
+
+	if len(data) != 0 {
+		return ErrInvalidSketch
+	}
+
+	return nil
+}
+
+// Parse returns the sketch of the binary data, see UnmarshalBinary.
+func Parse(data []byte) (*Sketch, error) {
+	s := New()
+	if err := s.UnmarshalBinary(data); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
@@ -0,0 +1,80 @@
+package hll
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestEstimate(t *testing.T) {
+	for _, n := range []int{0, 1, 10, 100, 1000, 100000} {
+		s := New()
+		for i := 0; i < n; i++ {
+			// every value is added twice, the repetitions are not counted
+			s.Add("visitor-" + strconv.Itoa(i))
+			s.Add("visitor-" + strconv.Itoa(i))
+		}
+
+		// the estimates are within four standard errors, the small ones within one
+		got := float64(s.Estimate())
+		if tolerance := math.Max(4*StdError()*float64(n), 1); math.Abs(got-float64(n)) > tolerance {
+			t.Errorf("Estimate() of %d values = %v; want within %v", n, got, tolerance)
+		}
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a, b, all := New(), New(), New()
+	for i := 0; i < 3000; i++ {
+		v := strconv.Itoa(i)
+		if i < 2000 {
+			a.Add(v)
+		}
+		if i >= 1000 {
+			b.Add(v)
+		}
+		all.Add(v)
+	}
+
+	a.Merge(b)
+	if a.Estimate() != all.Estimate() {
+		t.Errorf("Estimate() of the union = %d; want %d", a.Estimate(), all.Estimate())
+	}
+
+	// merging a sketch again changes nothing
+	a.Merge(b)
+	if a.Estimate() != all.Estimate() {
+		t.Errorf("Estimate() after merging again = %d; want %d", a.Estimate(), all.Estimate())
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{0, 5, sparseMax + 1, 50000} {
+		s := New()
+		for i := 0; i < n; i++ {
+			s.Add(strconv.Itoa(i))
+		}
+
+		b, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() error = %v", err)
+		}
+
+		got, err := Parse(b)
+		if err != nil {
+			t.Fatalf("Parse() of %d values error = %v", n, err)
+		}
+		if got.Estimate() != s.Estimate() {
+			t.Errorf("Estimate() of the parsed sketch of %d values = %d; want %d", n, got.Estimate(), s.Estimate())
+		}
+	}
+
+	sparse, _ := New().MarshalBinary()
+	for _, b := range [][]byte{nil, {version, Precision + 1, encodingSparse, 0}, {version, Precision, 7},
+		{version, Precision, encodingDense, 1}, {version, Precision, encodingSparse, 1, 0}, append(sparse, 0)} {
+		if _, err := Parse(b); !errors.Is(err, ErrInvalidSketch) {
+			t.Errorf("Parse(%v) error = %v; want %v", b, err, ErrInvalidSketch)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package linkpass provides the password protection of short links: hashing and
+// checking of the link passwords and rate limiting of the failed attempts.
+package linkpass
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultMaxAttempts is the number of failed attempts allowed per link within DefaultWindow.
+const DefaultMaxAttempts = 5
+
+// DefaultWindow is the period the failed attempts of a link are counted in.
+const DefaultWindow = time.Minute
+
+// ErrPasswordTooLong indicates that the password is longer than bcrypt supports.
+var ErrPasswordTooLong = errors.New("password is longer than 72 bytes")
+
+// Hash returns the bcrypt hash of the link password.
+func Hash(password string) ([]byte, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if errors.Is(err, bcrypt.ErrPasswordTooLong) {
+		return nil, ErrPasswordTooLong
+	}
+
+	return hash, err
+}
+
+// Check reports whether the password matches the hash.
+func Check(hash []byte, password string) bool {
+	return bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+}
+
+// attempts counts the failed attempts of a link within a window.
+type attempts struct {
+	count int
+	start time.Time
+}
+
+// Limiter rate limits the failed password attempts per link. A link is blocked once
+// it has had maxAttempts failures within the window, until the window has passed.
+type Limiter struct {
+	mx          sync.Mutex
+	maxAttempts int
+	window      time.Duration
+	failures    map[string]*attempts
+	now         func() time.Time
+}
+
+// NewLimiter creates a Limiter. Non-positive arguments fall back to
+// DefaultMaxAttempts and DefaultWindow.
+func NewLimiter(maxAttempts int, window time.Duration) *Limiter {
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+
+	if window <= 0 {
+		window = DefaultWindow
+	}
+
+	return &Limiter{
+		maxAttempts: maxAttempts,
+		window:      window,
+		failures:    make(map[string]*attempts),
+		now:         time.Now,
+	}
+}
+
+// Blocked reports whether the password attempts of the link are blocked.
+func (l *Limiter) Blocked(key string) bool {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	a := l.current(key)
+
+	return a != nil && a.count >= l.maxAttempts
+}
+
+// Fail records a failed password attempt of the link.
+func (l *Limiter) Fail(key string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	if a := l.current(key); a != nil {
+		a.count++
+		return
+	}
+
+	l.failures[key] = &attempts{count: 1, start: l.now()}
+}
+
+// Reset forgets the failed password attempts of the link.
+func (l *Limiter) Reset(key string) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+
+	delete(l.failures, key)
+}
+
+// current returns the failed attempts of the link within the current window,
+// dropping the outdated ones. The caller must hold the lock.
+func (l *Limiter) current(key string) *attempts {
+	a, ok := l.failures[key]
+	if !ok {
+		return nil
+	}
+
+	if l.now().Sub(a.start) >= l.window {
+		delete(l.failures, key)
+		return nil
+	}
+
+	return a
+}
@@ -0,0 +1,58 @@
+package linkpass
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHashCheck(t *testing.T) {
+	hash, err := Hash("s3cret")
+	if err != nil {
+		t.Fatalf("Hash return error %v", err)
+	}
+
+	if !Check(hash, "s3cret") {
+		t.Errorf("Check rejected the right password")
+	}
+
+	if Check(hash, "wrong") {
+		t.Errorf("Check accepted a wrong password")
+	}
+
+	if _, err := Hash(strings.Repeat("a", 73)); err != ErrPasswordTooLong {
+		t.Errorf("Hash return error %v; want %v", err, ErrPasswordTooLong)
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	l := NewLimiter(2, time.Minute)
+	l.now = func() time.Time { return now }
+
+	l.Fail("key")
+	if l.Blocked("key") {
+		t.Fatalf("Blocked after 1 failure; want 2")
+	}
+
+	l.Fail("key")
+	if !l.Blocked("key") {
+		t.Fatalf("not Blocked after 2 failures")
+	}
+
+	if l.Blocked("other") {
+		t.Errorf("the failures of a link blocked another link")
+	}
+
+	// the block is lifted once the window has passed
+	now = now.Add(time.Minute)
+	if l.Blocked("key") {
+		t.Errorf("Blocked after the window has passed")
+	}
+
+	l.Fail("key")
+	l.Reset("key")
+	if l.Blocked("key") {
+		t.Errorf("Blocked after Reset")
+	}
+}
@@ -0,0 +1,153 @@
+// Package qr renders the QR codes of short URLs as PNG or SVG images in-process.
+package qr
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Image formats of the QR codes.
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Error correction levels of the QR codes, recovering about 7%, 15%, 25% and 30%
+// of the damaged code.
+const (
+	LevelLow      = "L"
+	LevelMedium   = "M"
+	LevelQuartile = "Q"
+	LevelHigh     = "H"
+)
+
+// Sizes of the QR code images in pixels.
+const (
+	DefaultSize = 256
+	MinSize     = 64
+	MaxSize     = 2048
+)
+
+var (
+	// ErrFormat indicates that the image format is not supported.
+	ErrFormat = errors.New("format must be png or svg")
+	// ErrLevel indicates that the error correction level is not supported.
+	ErrLevel = errors.New("level must be one of L, M, Q or H")
+	// ErrSize indicates that the image size is out of range.
+	ErrSize = fmt.Errorf("size must be between %d and %d", MinSize, MaxSize)
+)
+
+// levels maps the error correction levels to the ones of the encoder.
+var levels = map[string]qrcode.RecoveryLevel{
+	LevelLow:      qrcode.Low,
+	LevelMedium:   qrcode.Medium,
+	LevelQuartile: qrcode.High,
+	LevelHigh:     qrcode.Highest,
+}
+
+// Options describe the image of a QR code.
+type Options struct {
+	// Size is the width and the height of the image in pixels.
+	Size int
+	// Level is the error correction level: L, M, Q or H.
+	Level string
+	// Format is the image format: png or svg.
+	Format string
+}
+
+// ParseOptions returns the options described by the size, the level and the format,
+// any of them may be empty for the default: 256 pixels, the M level and png.
+// It returns ErrSize, ErrLevel or ErrFormat if an option is not supported.
+func ParseOptions(size, level, format string) (Options, error) {
+	opts := Options{Size: DefaultSize, Level: LevelMedium, Format: FormatPNG}
+
+	if size != "" {
+		n, err := strconv.Atoi(size)
+		if err != nil || n < MinSize || n > MaxSize {
+			return opts, ErrSize
+		}
+		opts.Size = n
+	}
+
+	if level != "" {
+		opts.Level = strings.ToUpper(level)
+		if _, ok := levels[opts.Level]; !ok {
+			return opts, ErrLevel
+		}
+	}
+
+	if format != "" {
+		opts.Format = strings.ToLower(format)
+		if opts.Format != FormatPNG && opts.Format != FormatSVG {
+			return opts, ErrFormat
+		}
+	}
+
+	return opts, nil
+}
+
+// ContentType returns the media type of the image format.
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+
+	return "image/png"
+}
+
+// ETag returns the strong entity tag of the QR code image of the content,
+// the images are the same for the same content and options.
+func ETag(content string, opts Options) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\n%d\n%s\n%s", content, opts.Size, opts.Level, opts.Format)))
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// Render returns the image of the QR code encoding the content.
+func Render(content string, opts Options) ([]byte, error) {
+	level, ok := levels[opts.Level]
+	if !ok {
+		return nil, ErrLevel
+	}
+
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Format {
+	case FormatPNG:
+		return code.PNG(opts.Size)
+	case FormatSVG:
+		return svg(code.Bitmap(), opts.Size), nil
+	}
+
+	return nil, ErrFormat
+}
+
+// svg draws the bitmap, quiet zone included, as an SVG image of the size,
+// one unit of the view box per module.
+func svg(bitmap [][]bool, size int) []byte {
+	var b strings.Builder
+
+	n := len(bitmap)
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		size, size, n, n)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, n, n)
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+
+	return []byte(b.String())
+}
@@ -0,0 +1,81 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func TestParseOptions(t *testing.T) {
+	testCases := []struct {
+		name   string
+		size   string
+		level  string
+		format string
+		want   Options
+		err    error
+	}{
+		{name: "defaults", want: Options{Size: DefaultSize, Level: LevelMedium, Format: FormatPNG}},
+		{name: "all set", size: "512", level: "h", format: "SVG", want: Options{Size: 512, Level: LevelHigh, Format: FormatSVG}},
+		{name: "small", size: "16", err: ErrSize},
+		{name: "not a number", size: "big", err: ErrSize},
+		{name: "level", level: "X", err: ErrLevel},
+		{name: "format", format: "gif", err: ErrFormat},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseOptions(tc.size, tc.level, tc.format)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("ParseOptions() error = %v; want %v", err, tc.err)
+			}
+			if err == nil && got != tc.want {
+				t.Errorf("ParseOptions() = %+v; want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRender(t *testing.T) {
+	const content = "http://localhost:8080/abc"
+
+	data, err := Render(content, Options{Size: 128, Level: LevelQuartile, Format: FormatPNG})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	if b := img.Bounds(); b.Dx() != 128 || b.Dy() != 128 {
+		t.Errorf("Render() image bounds = %v; want 128x128", b)
+	}
+
+	data, err = Render(content, Options{Size: 128, Level: LevelLow, Format: FormatSVG})
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if s := string(data); !strings.HasPrefix(s, "<svg") || !strings.Contains(s, `width="128"`) ||
+		!strings.Contains(s, "h1v1h-1z") {
+		t.Errorf("Render() SVG = %.80q...", s)
+	}
+}
+
+func TestETag(t *testing.T) {
+	opts := Options{Size: DefaultSize, Level: LevelMedium, Format: FormatPNG}
+
+	if ETag("a", opts) != ETag("a", opts) {
+		t.Errorf("ETag() differs for the same image")
+	}
+	if ETag("a", opts) == ETag("b", opts) {
+		t.Errorf("ETag() is the same for different contents")
+	}
+
+	svg := opts
+	svg.Format = FormatSVG
+	if ETag("a", opts) == ETag("a", svg) {
+		t.Errorf("ETag() is the same for different formats")
+	}
+}
@@ -0,0 +1,143 @@
+// Package search finds the short links of a user by the text of their original URL,
+// host, key, tags and note.
+//
+// A query is split into terms on white space, a link matches if every term is a substring
+// of its text, regardless of case. Like the pg_trgm indexes of PostgreSQL, the Index narrows
+// the links down by the trigrams of the terms before their texts are matched.
+package search
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+// Terms returns the lower-case terms of the query.
+func Terms(query string) []string {
+	return strings.Fields(strings.ToLower(query))
+}
+
+// Document returns the lower-case searchable text of the link stored under the key:
+// the key, the original URL, its host, the tags and the note, one per line.
+func Document(key string, v models.DataURL) string {
+	fields := []string{key, v.OriginalURL}
+	if u, err := url.Parse(v.OriginalURL); err == nil && u.Host != "" {
+		fields = append(fields, u.Hostname())
+	}
+	fields = append(fields, v.Tags...)
+	fields = append(fields, v.Note)
+
+	return strings.ToLower(strings.Join(fields, "\n"))
+}
+
+// Match reports whether every term is a substring of the document.
+func Match(doc string, terms []string) bool {
+	for _, term := range terms {
+		if !strings.Contains(doc, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// trigrams returns the distinct trigrams of the text, counted in runes.
+func trigrams(text string) []string {
+	runes := []rune(text)
+	if len(runes) < 3 {
+		return nil
+	}
+
+	seen := make(map[string]bool, len(runes)-2)
+	grams := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		g := string(runes[i : i+3])
+		if !seen[g] {
+			seen[g] = true
+			grams = append(grams, g)
+		}
+	}
+
+	return grams
+}
+
+// Index is an inverted index of the trigrams of the documents of the links.
+// It is not safe for concurrent use.
+type Index struct {
+	grams map[string]map[string]struct{}
+	keys  map[string]struct{}
+}
+
+// NewIndex returns an empty Index.
+func NewIndex() *Index {
+	return &Index{
+		grams: make(map[string]map[string]struct{}),
+		keys:  make(map[string]struct{}),
+	}
+}
+
+// Add indexes the document of the link stored under the key.
+func (ix *Index) Add(key string, doc string) {
+	ix.keys[key] = struct{}{}
+	for _, g := range trigrams(doc) {
+		keys, ok := ix.grams[g]
+		if !ok {
+			keys = make(map[string]struct{})
+			ix.grams[g] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+// Remove removes the document of the link stored under the key, as it was added, from the index.
+func (ix *Index) Remove(key string, doc string) {
+	delete(ix.keys, key)
+	for _, g := range trigrams(doc) {
+		delete(ix.grams[g], key)
+		if len(ix.grams[g]) == 0 {
+			delete(ix.grams, g)
+		}
+	}
+}
+
+// Len returns the number of the indexed links.
+func (ix *Index) Len() int {
+	return len(ix.keys)
+}
+
+// Candidates returns the keys of the links containing all of the trigrams of the terms.
+// The documents of the candidates are yet to be matched against the terms. The terms
+// shorter than three characters have no trigrams and narrow nothing down.
+func (ix *Index) Candidates(terms []string) []string {
+	var res map[string]struct{}
+	for _, term := range terms {
+		for _, g := range trigrams(term) {
+			keys := ix.grams[g]
+			if res == nil {
+				res = make(map[string]struct{}, len(keys))
+				for k := range keys {
+					res[k] = struct{}{}
+				}
+				continue
+			}
+			for k := range res {
+				if _, ok := keys[k]; !ok {
+					delete(res, k)
+				}
+			}
+		}
+	}
+
+	// no term has a trigram, every link is a candidate
+	if res == nil {
+		res = ix.keys
+	}
+
+	keys := make([]string, 0, len(res))
+	for k := range res {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
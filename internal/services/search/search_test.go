@@ -0,0 +1,83 @@
+package search
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+func TestDocument(t *testing.T) {
+	doc := Document("Promo", models.DataURL{OriginalURL: "https://Shop.Example.com/sale?id=1",
+		Tags: []string{"marketing"}, Note: "Autumn campaign"})
+
+	for _, terms := range [][]string{{"promo"}, {"shop.example.com"}, {"sale?id"}, {"marketing", "autumn"}} {
+		if !Match(doc, terms) {
+			t.Errorf("Match(%q) = false; want true", terms)
+		}
+	}
+	if Match(doc, []string{"promo", "winter"}) {
+		t.Errorf("Match() = true for a missing term; want false")
+	}
+}
+
+func TestTerms(t *testing.T) {
+	terms := Terms("  Shop  SALE ")
+	if len(terms) != 2 || terms[0] != "shop" || terms[1] != "sale" {
+		t.Errorf("Terms() = %q; want [shop sale]", terms)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	docs := map[string]string{
+		"a": Document("a", models.DataURL{OriginalURL: "https://example.com/shoes", Note: "running"}),
+		"b": Document("b", models.DataURL{OriginalURL: "https://example.org/shirts"}),
+		"c": Document("c", models.DataURL{OriginalURL: "https://shop.test/", Tags: []string{"shoes"}}),
+	}
+
+	ix := NewIndex()
+	for k, doc := range docs {
+		ix.Add(k, doc)
+	}
+
+	candidates := func(query string) []string {
+		terms := Terms(query)
+		var keys []string
+		for _, k := range ix.Candidates(terms) {
+			if Match(docs[k], terms) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		return keys
+	}
+
+	testCases := []struct {
+		query string
+		want  string
+	}{
+		{query: "shoes", want: "ac"},
+		{query: "example", want: "ab"},
+		{query: "shoes run", want: "a"},
+		{query: "s", want: "abc"},
+		{query: "boots", want: ""},
+	}
+
+	for _, tc := range testCases {
+		got := ""
+		for _, k := range candidates(tc.query) {
+			got += k
+		}
+		if got != tc.want {
+			t.Errorf("search %q = %q; want %q", tc.query, got, tc.want)
+		}
+	}
+
+	ix.Remove("a", docs["a"])
+	if got := candidates("shoes"); len(got) != 1 || got[0] != "c" {
+		t.Errorf("search after Remove = %q; want [c]", got)
+	}
+	if ix.Len() != 2 {
+		t.Errorf("Len() = %d; want 2", ix.Len())
+	}
+}
@@ -0,0 +1,358 @@
+package shorturl
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"sync/atomic"
+)
+
+// Key generation strategies.
+const (
+	// StrategyHash derives the key from the MD5 hash of the original URL.
+	StrategyHash = "hash"
+	// StrategyRandom generates a crypto-random key of a fixed length.
+	StrategyRandom = "random"
+	// StrategyCounter encodes a monotonic counter.
+	StrategyCounter = "counter"
+	// StrategyHashids encodes a monotonic counter with the Hashids algorithm,
+	// so that consecutive keys do not look sequential.
+	StrategyHashids = "hashids"
+)
+
+// DefaultKeyLength is the key length used when none is configured.
+const DefaultKeyLength = 8
+
+// ErrUnknownStrategy indicates that the key generation strategy is not supported.
+var ErrUnknownStrategy = errors.New("unknown key generation strategy")
+
+// KeyGenerator generates short keys for original URLs.
+type KeyGenerator interface {
+	// Generate returns a short key for the original URL.
+	Generate(url string) (string, error)
+}
+
+// Sequence issues the increasing numbers the counter based strategies encode.
+type Sequence interface {
+	// Next returns the next number of the sequence, never issued before.
+	Next() (uint64, error)
+}
+
+// SequenceFunc is an adapter to use an ordinary function as a Sequence.
+type SequenceFunc func() (uint64, error)
+
+// Next implements Sequence.
+func (f SequenceFunc) Next() (uint64, error) {
+	return f()
+}
+
+// MemorySequence is a Sequence kept in memory, it starts over with the process.
+type MemorySequence struct {
+	counter atomic.Uint64
+}
+
+// NewMemorySequence creates a MemorySequence whose first number is start+1.
+func NewMemorySequence(start uint64) *MemorySequence {
+	s := &MemorySequence{}
+	s.counter.Store(start)
+
+	return s
+}
+
+// Next implements Sequence.
+func (s *MemorySequence) Next() (uint64, error) {
+	return s.counter.Add(1), nil
+}
+
+// NewKeyGenerator creates a KeyGenerator for the strategy. Empty or zero arguments
+// fall back to the defaults: the hash strategy, DefaultKeyLength and the base62 alphabet.
+// The salt is used by the hashids strategy only, seq issues the numbers of the counter
+// based strategies, a nil seq falls back to a MemorySequence starting from zero.
+func NewKeyGenerator(strategy string, length int, keyAlphabet string, salt string, seq Sequence) (KeyGenerator, error) {
+	if length <= 0 {
+		length = DefaultKeyLength
+	}
+
+	if seq == nil {
+		seq = NewMemorySequence(0)
+	}
+
+	if keyAlphabet == "" {
+		keyAlphabet = alphabet
+	}
+
+	if err := validateAlphabet(keyAlphabet); err != nil {
+		return nil, err
+	}
+
+	switch strategy {
+	case "", StrategyHash:
+		return NewHashGenerator(keyAlphabet), nil
+	case StrategyRandom:
+		return NewRandomGenerator(length, keyAlphabet), nil
+	case StrategyCounter:
+		return NewCounterGenerator(seq, length, keyAlphabet), nil
+	case StrategyHashids:
+		return NewHashidsGenerator(seq, length, keyAlphabet, salt)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrUnknownStrategy, strategy)
+}
+
+// validateAlphabet checks that the alphabet has at least two characters and no duplicates.
+func validateAlphabet(a string) error {
+	if len(a) < 2 {
+		return errors.New("key alphabet must contain at least 2 characters")
+	}
+
+	for i := 0; i < len(a); i++ {
+		if a[i] > 127 {
+			return errors.New("key alphabet must contain ASCII characters only")
+		}
+		if strings.IndexByte(a[i+1:], a[i]) >= 0 {
+			return fmt.Errorf("key alphabet contains duplicate character %q", a[i])
+		}
+	}
+
+	return nil
+}
+
+// encode converts n to the alphabet, most significant digit first.
+func encode(n uint64, a string) string {
+	base := uint64(len(a))
+	var buf []byte
+	for {
+		buf = append(buf, a[n%base])
+		n /= base
+		if n == 0 {
+			break
+		}
+	}
+
+	for i, j := 0, len(buf)-1; i < j; i, j = i+1, j-1 {
+		buf[i], buf[j] = buf[j], buf[i]
+	}
+
+	return string(buf)
+}
+
+// HashGenerator derives the key from the hash of the original URL.
+// The same URL always yields the same key, the key length varies.
+type HashGenerator struct {
+	alphabet string
+	hash     func(string) uint64
+}
+
+// NewHashGenerator creates a HashGenerator over the alphabet.
+func NewHashGenerator(keyAlphabet string) *HashGenerator {
+	return NewHashGeneratorFunc(keyAlphabet, strToUint64)
+}
+
+// NewHashGeneratorFunc creates a HashGenerator over the alphabet with a custom hash
+// function, e.g. to force key collisions in tests.
+func NewHashGeneratorFunc(keyAlphabet string, hash func(string) uint64) *HashGenerator {
+	return &HashGenerator{alphabet: keyAlphabet, hash: hash}
+}
+
+// Generate implements KeyGenerator.
+func (g *HashGenerator) Generate(url string) (string, error) {
+	n := g.hash(strings.TrimSpace(url))
+	base := uint64(len(g.alphabet))
+
+	// least significant digit first, to stay compatible with Shorten
+	var s strings.Builder
+	for n > 0 {
+		s.WriteByte(g.alphabet[n%base])
+		n /= base
+	}
+
+	return s.String(), nil
+}
+
+// RandomGenerator generates crypto-random keys of a fixed length.
+type RandomGenerator struct {
+	length   int
+	alphabet string
+}
+
+// NewRandomGenerator creates a RandomGenerator.
+func NewRandomGenerator(length int, keyAlphabet string) *RandomGenerator {
+	return &RandomGenerator{length: length, alphabet: keyAlphabet}
+}
+
+// Generate implements KeyGenerator.
+func (g *RandomGenerator) Generate(string) (string, error) {
+	max := big.NewInt(int64(len(g.alphabet)))
+	buf := make([]byte, g.length)
+	for i := range buf {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		buf[i] = g.alphabet[n.Int64()]
+	}
+
+	return string(buf), nil
+}
+
+// CounterGenerator encodes the numbers of a sequence, left-padded to the minimum length.
+type CounterGenerator struct {
+	seq      Sequence
+	length   int
+	alphabet string
+}
+
+// NewCounterGenerator creates a CounterGenerator encoding the numbers of the sequence.
+func NewCounterGenerator(seq Sequence, length int, keyAlphabet string) *CounterGenerator {
+	return &CounterGenerator{seq: seq, length: length, alphabet: keyAlphabet}
+}
+
+// Generate implements KeyGenerator.
+func (g *CounterGenerator) Generate(string) (string, error) {
+	n, err := g.seq.Next()
+	if err != nil {
+		return "", err
+	}
+
+	key := encode(n, g.alphabet)
+	if pad := g.length - len(key); pad > 0 {
+		key = strings.Repeat(g.alphabet[:1], pad) + key
+	}
+
+	return key, nil
+}
+
+const (
+	hashidsMinAlphabetLength = 16
+	hashidsSeparators        = "cfhistuCFHISTU"
+	hashidsSepDiv            = 3.5
+	hashidsGuardDiv          = 12.0
+)
+
+// HashidsGenerator encodes the numbers of a sequence with the Hashids algorithm.
+type HashidsGenerator struct {
+	seq       Sequence
+	minLength int
+	alphabet  string
+	salt      string
+	seps      string
+	guards    string
+}
+
+// NewHashidsGenerator creates a HashidsGenerator encoding the numbers of the sequence.
+func NewHashidsGenerator(seq Sequence, minLength int, keyAlphabet string, salt string) (*HashidsGenerator, error) {
+	if len(keyAlphabet) < hashidsMinAlphabetLength {
+		return nil, fmt.Errorf("hashids alphabet must contain at least %d characters", hashidsMinAlphabetLength)
+	}
+
+	// separators are the characters of hashidsSeparators present in the alphabet
+	var seps, rest []byte
+	for i := 0; i < len(keyAlphabet); i++ {
+		if strings.IndexByte(hashidsSeparators, keyAlphabet[i]) >= 0 {
+			seps = append(seps, keyAlphabet[i])
+		} else {
+			rest = append(rest, keyAlphabet[i])
+		}
+	}
+	shuffle(seps, salt)
+
+	if len(seps) == 0 || float64(len(rest))/float64(len(seps)) > hashidsSepDiv {
+		sepsLength := int(math.Ceil(float64(len(rest)) / hashidsSepDiv))
+		if sepsLength == 1 {
+			sepsLength++
+		}
+
+		if sepsLength > len(seps) {
+			diff := sepsLength - len(seps)
+			seps = append(seps, rest[:diff]...)
+			rest = rest[diff:]
+		} else {
+			seps = seps[:sepsLength]
+		}
+	}
+	shuffle(rest, salt)
+
+	var guards []byte
+	guardCount := int(math.Ceil(float64(len(rest)) / hashidsGuardDiv))
+	if len(rest) < 3 {
+		guards, seps = seps[:guardCount], seps[guardCount:]
+	} else {
+		guards, rest = rest[:guardCount], rest[guardCount:]
+	}
+
+	g := &HashidsGenerator{
+		seq:       seq,
+		minLength: minLength,
+		alphabet:  string(rest),
+		salt:      salt,
+		seps:      string(seps),
+		guards:    string(guards),
+	}
+
+	return g, nil
+}
+
+// Generate implements KeyGenerator.
+func (g *HashidsGenerator) Generate(string) (string, error) {
+	n, err := g.seq.Next()
+	if err != nil {
+		return "", err
+	}
+
+	return g.encode(n), nil
+}
+
+// encode encodes a single number according to the Hashids algorithm.
+func (g *HashidsGenerator) encode(n uint64) string {
+	a := []byte(g.alphabet)
+	id := n % 100
+
+	lottery := a[id%uint64(len(a))]
+	buffer := append([]byte{lottery}, g.salt...)
+	buffer = append(buffer, a...)
+	shuffle(a, string(buffer[:len(a)]))
+
+	ret := append([]byte{lottery}, encode(n, string(a))...)
+
+	if len(ret) < g.minLength {
+		guard := g.guards[(id+uint64(ret[0]))%uint64(len(g.guards))]
+		ret = append([]byte{guard}, ret...)
+
+		if len(ret) < g.minLength {
+			guard = g.guards[(id+uint64(ret[2]))%uint64(len(g.guards))]
+			ret = append(ret, guard)
+		}
+	}
+
+	half := len(a) / 2
+	for len(ret) < g.minLength {
+		shuffle(a, string(a))
+		padded := append([]byte{}, a[half:]...)
+		padded = append(padded, ret...)
+		ret = append(padded, a[:half]...)
+
+		if excess := len(ret) - g.minLength; excess > 0 {
+			ret = ret[excess/2 : excess/2+g.minLength]
+		}
+	}
+
+	return string(ret)
+}
+
+// shuffle permutes the alphabet in place, deterministically for the salt.
+func shuffle(a []byte, salt string) {
+	if salt == "" {
+		return
+	}
+
+	for i, v, p := len(a)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		c := int(salt[v])
+		p += c
+		j := (c + v + p) % i
+		a[i], a[j] = a[j], a[i]
+	}
+}
@@ -0,0 +1,125 @@
+package shorturl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNewKeyGenerator(t *testing.T) {
+	testCases := []struct {
+		name     string
+		strategy string
+		alphabet string
+		wantErr  bool
+	}{
+		{name: "default", strategy: ""},
+		{name: "hash", strategy: StrategyHash},
+		{name: "random", strategy: StrategyRandom},
+		{name: "counter", strategy: StrategyCounter},
+		{name: "hashids", strategy: StrategyHashids},
+		{name: "unknown strategy", strategy: "sequence", wantErr: true},
+		{name: "duplicate alphabet", strategy: StrategyRandom, alphabet: "abca", wantErr: true},
+		{name: "short hashids alphabet", strategy: StrategyHashids, alphabet: "abcdef", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gen, err := NewKeyGenerator(tc.strategy, 0, tc.alphabet, "salt", nil)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("NewKeyGenerator(%q) returned no error", tc.strategy)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("NewKeyGenerator(%q) returned error %v", tc.strategy, err)
+			}
+
+			key, err := gen.Generate("https://practicum.yandex.ru/")
+			if err != nil || key == "" {
+				t.Errorf("Generate() = %q, %v", key, err)
+			}
+		})
+	}
+
+	_, err := NewKeyGenerator("sequence", 0, "", "", nil)
+	if !errors.Is(err, ErrUnknownStrategy) {
+		t.Errorf("NewKeyGenerator error %v; want %v", err, ErrUnknownStrategy)
+	}
+}
+
+func TestHashGenerator(t *testing.T) {
+	gen := NewHashGenerator(alphabet)
+	url := "https://practicum.yandex.ru/"
+
+	key, _ := gen.Generate(url)
+	if want, _ := Shorten(url, "http://localhost:8080/"); key != want {
+		t.Errorf("Generate() = %q; want %q", key, want)
+	}
+}
+
+func TestRandomGenerator(t *testing.T) {
+	gen := NewRandomGenerator(12, "ab")
+
+	first, _ := gen.Generate("")
+	second, _ := gen.Generate("")
+	if len(first) != 12 || strings.Trim(first, "ab") != "" {
+		t.Errorf("Generate() = %q; want 12 characters of the alphabet", first)
+	}
+	if first == second {
+		t.Errorf("Generate() returned %q twice", first)
+	}
+}
+
+func TestCounterGenerator(t *testing.T) {
+	gen := NewCounterGenerator(NewMemorySequence(60), 3, alphabet)
+
+	want := []string{"aa9", "aba", "abb"}
+	for _, w := range want {
+		if key, _ := gen.Generate(""); key != w {
+			t.Errorf("Generate() = %q; want %q", key, w)
+		}
+	}
+}
+
+func TestCounterGeneratorSequence(t *testing.T) {
+	// the keys continue from the numbers of the sequence, e.g. one persisted by the keeper
+	next := uint64(62)
+	gen := NewCounterGenerator(SequenceFunc(func() (uint64, error) {
+		next++
+		return next, nil
+	}), 3, alphabet)
+	if key, _ := gen.Generate(""); key != "abb" {
+		t.Errorf("Generate() = %q; want %q", key, "abb")
+	}
+
+	failing := NewCounterGenerator(SequenceFunc(func() (uint64, error) {
+		return 0, errors.New("sequence unavailable")
+	}), 3, alphabet)
+	if key, err := failing.Generate(""); err == nil {
+		t.Errorf("Generate() = %q; want the error of the sequence", key)
+	}
+}
+
+func TestHashidsGenerator(t *testing.T) {
+	// reference values of the Hashids algorithm for its default alphabet
+	const hashidsAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+
+	gen, err := NewHashidsGenerator(NewMemorySequence(0), 0, hashidsAlphabet, "this is my salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := gen.encode(12345); key != "NkK9" {
+		t.Errorf("encode(12345) = %q; want %q", key, "NkK9")
+	}
+
+	gen, err = NewHashidsGenerator(NewMemorySequence(0), 8, hashidsAlphabet, "this is my salt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := gen.Generate(""); key != "gB0NV05e" {
+		t.Errorf("Generate() = %q; want %q", key, "gB0NV05e")
+	}
+}
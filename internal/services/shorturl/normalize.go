@@ -0,0 +1,206 @@
+package shorturl
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// URL normalization rules.
+const (
+	// RuleLowercase lowercases the scheme and the host.
+	RuleLowercase = "lowercase"
+	// RuleDefaultPort removes the default port of the scheme, e.g. :443 for https.
+	RuleDefaultPort = "default-port"
+	// RulePunycode converts internationalized host names to punycode.
+	RulePunycode = "punycode"
+	// RuleDotSegments removes the "." and ".." segments from the path.
+	RuleDotSegments = "dot-segments"
+	// RuleSortQuery sorts the query parameters by name.
+	RuleSortQuery = "sort-query"
+	// RuleStripTracking removes the tracking query parameters (utm_*, fbclid).
+	RuleStripTracking = "strip-tracking"
+	// RuleNone disables the normalization.
+	RuleNone = "none"
+)
+
+// DefaultRules lists the normalization rules used when none are configured.
+var DefaultRules = []string{RuleLowercase, RuleDefaultPort, RulePunycode, RuleDotSegments}
+
+// ErrUnknownRule indicates that the normalization rule is not supported.
+var ErrUnknownRule = errors.New("unknown normalization rule")
+
+// defaultPorts maps the schemes to their default ports.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+	"ftp":   "21",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// Normalizer canonicalizes original URLs, so that equivalent URLs get the same key.
+// The zero value only trims the URLs.
+type Normalizer struct {
+	rules map[string]bool
+}
+
+// NewNormalizer creates a Normalizer from a comma-separated list of rules.
+// An empty list falls back to DefaultRules, "none" disables the normalization.
+func NewNormalizer(rules string) (*Normalizer, error) {
+	n := &Normalizer{rules: make(map[string]bool)}
+
+	list := DefaultRules
+	if strings.TrimSpace(rules) != "" {
+		list = strings.Split(rules, ",")
+	}
+
+	for _, rule := range list {
+		rule = strings.TrimSpace(rule)
+		switch rule {
+		case RuleNone:
+			return &Normalizer{rules: make(map[string]bool)}, nil
+		case RuleLowercase, RuleDefaultPort, RulePunycode, RuleDotSegments, RuleSortQuery, RuleStripTracking:
+			n.rules[rule] = true
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnknownRule, rule)
+		}
+	}
+
+	return n, nil
+}
+
+// Normalize returns the canonical form of the URL. The URL is returned trimmed
+// but otherwise unchanged if it cannot be parsed as an absolute URL.
+func (n *Normalizer) Normalize(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" || len(n.rules) == 0 {
+		return rawURL
+	}
+
+	host, port := u.Hostname(), u.Port()
+
+	if n.rules[RuleLowercase] {
+		u.Scheme = strings.ToLower(u.Scheme)
+		host = strings.ToLower(host)
+	}
+
+	if n.rules[RulePunycode] {
+		if ascii, err := idna.Lookup.ToASCII(host); err == nil {
+			host = ascii
+		}
+	}
+
+	if n.rules[RuleDefaultPort] && port == defaultPorts[strings.ToLower(u.Scheme)] {
+		port = ""
+	}
+
+	if strings.Contains(host, ":") {
+		// IPv6 literal
+		host = "[" + host + "]"
+	}
+	if port != "" {
+		host = host + ":" + port
+	}
+	u.Host = host
+
+	if n.rules[RuleDotSegments] {
+		escaped := removeDotSegments(u.EscapedPath())
+		if p, err := url.PathUnescape(escaped); err == nil {
+			u.Path, u.RawPath = p, escaped
+		}
+	}
+
+	if u.RawQuery != "" && (n.rules[RuleSortQuery] || n.rules[RuleStripTracking]) {
+		u.RawQuery = n.normalizeQuery(u.RawQuery)
+	}
+
+	return u.String()
+}
+
+// normalizeQuery strips the tracking parameters and sorts the parameters by name,
+// keeping the original encoding of the names and values.
+func (n *Normalizer) normalizeQuery(query string) string {
+	params := strings.Split(query, "&")
+
+	kept := params[:0]
+	for _, p := range params {
+		if p == "" || n.rules[RuleStripTracking] && isTrackingParam(p) {
+			continue
+		}
+		kept = append(kept, p)
+	}
+
+	if n.rules[RuleSortQuery] {
+		sort.SliceStable(kept, func(i, j int) bool {
+			return paramName(kept[i]) < paramName(kept[j])
+		})
+	}
+
+	return strings.Join(kept, "&")
+}
+
+// paramName returns the decoded name of the query parameter.
+func paramName(param string) string {
+	name, _, _ := strings.Cut(param, "=")
+	if unescaped, err := url.QueryUnescape(name); err == nil {
+		return unescaped
+	}
+
+	return name
+}
+
+// isTrackingParam reports whether the query parameter is used for tracking only.
+func isTrackingParam(param string) bool {
+	name := strings.ToLower(paramName(param))
+
+	return strings.HasPrefix(name, "utm_") || name == "fbclid"
+}
+
+// removeDotSegments implements the remove_dot_segments algorithm of RFC 3986, section 5.2.4.
+func removeDotSegments(p string) string {
+	var out []string
+	in := p
+	for in != "" {
+		switch {
+		case strings.HasPrefix(in, "../"):
+			in = in[3:]
+		case strings.HasPrefix(in, "./"):
+			in = in[2:]
+		case strings.HasPrefix(in, "/./"):
+			in = in[2:]
+		case in == "/.":
+			in = "/"
+		case strings.HasPrefix(in, "/../"):
+			in = in[3:]
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case in == "/..":
+			in = "/"
+			if len(out) > 0 {
+				out = out[:len(out)-1]
+			}
+		case in == "." || in == "..":
+			in = ""
+		default:
+			// move the first path segment, including its leading "/", to the output
+			i := strings.IndexByte(in[1:], '/')
+			if i < 0 {
+				out = append(out, in)
+				in = ""
+			} else {
+				out = append(out, in[:i+1])
+				in = in[i+1:]
+			}
+		}
+	}
+
+	return strings.Join(out, "")
+}
@@ -0,0 +1,61 @@
+package shorturl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name  string
+		rules string
+		url   string
+		want  string
+	}{
+		{name: "lowercase", url: "HTTPS://Example.COM/Path", want: "https://example.com/Path"},
+		{name: "default port", url: "https://example.com:443/a", want: "https://example.com/a"},
+		{name: "custom port", url: "https://example.com:8443/a", want: "https://example.com:8443/a"},
+		{name: "punycode", url: "https://bücher.example/", want: "https://xn--bcher-kva.example/"},
+		{name: "dot segments", url: "https://example.com/a/./b/../c/", want: "https://example.com/a/c/"},
+		{name: "query kept by default", url: "https://example.com/a?b=1&a=2&utm_source=x",
+			want: "https://example.com/a?b=1&a=2&utm_source=x"},
+		{name: "sort query", rules: "lowercase,sort-query", url: "https://Example.com/a?b=1&a=2",
+			want: "https://example.com/a?a=2&b=1"},
+		{name: "strip tracking", rules: "strip-tracking", url: "https://example.com/?utm_source=x&id=7&fbclid=abc",
+			want: "https://example.com/?id=7"},
+		{name: "none", rules: "none", url: "HTTPS://Example.com:443/./a", want: "HTTPS://Example.com:443/./a"},
+		{name: "not absolute", url: " example ", want: "example"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			n, err := NewNormalizer(tc.rules)
+			if err != nil {
+				t.Fatalf("NewNormalizer(%q) error = %v", tc.rules, err)
+			}
+
+			if got := n.Normalize(tc.url); got != tc.want {
+				t.Errorf("Normalize(%q) = %q; want %q", tc.url, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEquivalentURLs(t *testing.T) {
+	n, err := NewNormalizer("lowercase,default-port,punycode,dot-segments,sort-query,strip-tracking")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := n.Normalize("https://Example.com/a?b=1&a=2")
+	b := n.Normalize("https://example.com:443/a?a=2&b=1&utm_medium=email")
+	if a != b {
+		t.Errorf("Normalize results differ: %q and %q", a, b)
+	}
+}
+
+func TestNewNormalizerUnknownRule(t *testing.T) {
+	if _, err := NewNormalizer("lowercase,unknown"); !errors.Is(err, ErrUnknownRule) {
+		t.Errorf("NewNormalizer error = %v; want %v", err, ErrUnknownRule)
+	}
+}
@@ -0,0 +1,84 @@
+package shorturl
+
+import (
+	"errors"
+	"net/url"
+)
+
+// Query policies of the links, they decide what happens to the query string
+// the short URL is opened with.
+const (
+	// QueryDrop drops the query string, it is the default policy.
+	QueryDrop = "drop"
+	// QueryMerge merges the query string into the query of the original URL,
+	// the parameters of the query string win.
+	QueryMerge = "merge"
+	// QueryUTM drops the query string and appends the UTM parameters of the link
+	// to the query of the original URL.
+	QueryUTM = "utm"
+)
+
+// ErrUnknownQueryPolicy indicates that the query policy is not supported.
+var ErrUnknownQueryPolicy = errors.New("query policy must be one of drop, merge or utm")
+
+// UTM holds the UTM parameters appended to the original URL by the QueryUTM policy.
+type UTM struct {
+	Source   string
+	Medium   string
+	Campaign string
+}
+
+// IsZero reports whether none of the UTM parameters is set.
+func (u UTM) IsZero() bool {
+	return u == UTM{}
+}
+
+// ValidateQueryPolicy returns ErrUnknownQueryPolicy if the query policy is not supported.
+// An empty policy stands for QueryDrop.
+func ValidateQueryPolicy(policy string) error {
+	switch policy {
+	case "", QueryDrop, QueryMerge, QueryUTM:
+		return nil
+	}
+
+	return ErrUnknownQueryPolicy
+}
+
+// Destination returns the URL a short link redirects to: the original URL with
+// the raw query string the short URL is opened with applied according to policy.
+// The original URL is returned as is if it cannot be parsed.
+func Destination(originalURL string, policy string, rawQuery string, utm UTM) string {
+	switch {
+	case policy == QueryMerge && rawQuery != "":
+	case policy == QueryUTM && !utm.IsZero():
+	default:
+		return originalURL
+	}
+
+	u, err := url.Parse(originalURL)
+	if err != nil {
+		return originalURL
+	}
+	q := u.Query()
+
+	if policy == QueryMerge {
+		params, err := url.ParseQuery(rawQuery)
+		if err != nil {
+			return originalURL
+		}
+		for name, values := range params {
+			q[name] = values
+		}
+	} else {
+		for name, value := range map[string]string{"utm_source": utm.Source,
+			"utm_medium": utm.Medium, "utm_campaign": utm.Campaign} {
+			if value != "" {
+				q.Set(name, value)
+			}
+		}
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
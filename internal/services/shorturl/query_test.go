@@ -0,0 +1,56 @@
+package shorturl
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDestination(t *testing.T) {
+	utm := UTM{Source: "newsletter", Medium: "email", Campaign: "spring sale"}
+
+	testCases := []struct {
+		name   string
+		url    string
+		policy string
+		query  string
+		utm    UTM
+		want   string
+	}{
+		{name: "default drops", url: "https://example.com/a?id=7", query: "ref=x",
+			want: "https://example.com/a?id=7"},
+		{name: "drop", url: "https://example.com/a", policy: QueryDrop, query: "ref=x", utm: utm,
+			want: "https://example.com/a"},
+		{name: "merge", url: "https://example.com/a?id=7", policy: QueryMerge, query: "ref=x",
+			want: "https://example.com/a?id=7&ref=x"},
+		{name: "merge overrides", url: "https://example.com/a?id=7&ref=a", policy: QueryMerge, query: "ref=b",
+			want: "https://example.com/a?id=7&ref=b"},
+		{name: "merge empty query", url: "https://example.com/a?b=1&a=2", policy: QueryMerge,
+			want: "https://example.com/a?b=1&a=2"},
+		{name: "utm", url: "https://example.com/a?id=7", policy: QueryUTM, query: "ref=x", utm: utm,
+			want: "https://example.com/a?id=7&utm_campaign=spring+sale&utm_medium=email&utm_source=newsletter"},
+		{name: "utm partial", url: "https://example.com/a?utm_source=old", policy: QueryUTM,
+			utm: UTM{Source: "new"}, want: "https://example.com/a?utm_source=new"},
+		{name: "fragment kept", url: "https://example.com/a#top", policy: QueryMerge, query: "ref=x",
+			want: "https://example.com/a?ref=x#top"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Destination(tc.url, tc.policy, tc.query, tc.utm); got != tc.want {
+				t.Errorf("Destination(%q, %q, %q) = %q; want %q", tc.url, tc.policy, tc.query, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestValidateQueryPolicy(t *testing.T) {
+	for _, policy := range []string{"", QueryDrop, QueryMerge, QueryUTM} {
+		if err := ValidateQueryPolicy(policy); err != nil {
+			t.Errorf("ValidateQueryPolicy(%q) error = %v", policy, err)
+		}
+	}
+
+	if err := ValidateQueryPolicy("keep"); !errors.Is(err, ErrUnknownQueryPolicy) {
+		t.Errorf("ValidateQueryPolicy error = %v; want %v", err, ErrUnknownQueryPolicy)
+	}
+}
@@ -4,14 +4,38 @@ package shorturl
 import (
 	"crypto/md5"
 	"encoding/hex"
+	"errors"
 	"math/big"
+	"strconv"
 	"strings"
 )
 
 const (
 	alphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	// aliasAlphabet lists the characters allowed in a custom alias.
+	aliasAlphabet = alphabet + "-_"
+
+	// MinAliasLength is the minimum length of a custom alias.
+	MinAliasLength = 3
+
+	// MaxAliasLength is the maximum length of a custom alias.
+	MaxAliasLength = 64
 )
 
+// ErrInvalidAlias indicates that a custom alias violates the alias policy.
+var ErrInvalidAlias = errors.New("invalid alias")
+
+// reservedAliases contains names that collide with the server routes.
+var reservedAliases = map[string]struct{}{
+	"api":      {},
+	"login":    {},
+	"ping":     {},
+	"pprof":    {},
+	"register": {},
+	"vars":     {},
+}
+
 // strHash calculates the string hash from a uint64 value.
 func strHash(n uint64) string {
 	var s string
@@ -35,14 +59,59 @@ func strToUint64(str string) uint64 {
 	return bi.Uint64()
 }
 
-// Shorten generates a short URL from the given URL and base short URL address.
-func Shorten(url string, shortURLAdress string) (string, string) {
-	key := strHash(strToUint64(strings.TrimSpace(url)))
+// Build joins the base short URL address and the key into a short URL.
+func Build(shortURLAdress string, key string) string {
 	shortURLAdress = strings.TrimSpace(shortURLAdress)
 	if string(shortURLAdress[len(shortURLAdress)-1]) != "/" {
 		shortURLAdress += "/"
 	}
 
-	return key, shortURLAdress + key
+	return shortURLAdress + key
+}
+
+// Salt returns the input for re-deriving the key of the URL after attempt collisions.
+// The zero attempt yields the URL itself, so the first key stays the usual one.
+func Salt(url string, attempt int) string {
+	if attempt == 0 {
+		return url
+	}
+
+	return url + "\x00" + strconv.Itoa(attempt)
+}
+
+// Shorten generates a short URL from the given URL and base short URL address.
+func Shorten(url string, shortURLAdress string) (string, string) {
+	key := strHash(strToUint64(strings.TrimSpace(url)))
+
+	return key, Build(shortURLAdress, key)
+}
+
+// ValidateAlias checks that the alias consists of letters, digits, '-' and '_',
+// fits into the allowed length and does not shadow a server route.
+func ValidateAlias(alias string) error {
+	if len(alias) < MinAliasLength || len(alias) > MaxAliasLength {
+		return ErrInvalidAlias
+	}
+
+	for _, r := range alias {
+		if !strings.ContainsRune(aliasAlphabet, r) {
+			return ErrInvalidAlias
+		}
+	}
+
+	if _, ok := reservedAliases[strings.ToLower(alias)]; ok {
+		return ErrInvalidAlias
+	}
+
+	return nil
+}
+
+// Alias validates the custom alias and builds a short URL from it and the base short URL address.
+func Alias(alias string, shortURLAdress string) (string, string, error) {
+	alias = strings.TrimSpace(alias)
+	if err := ValidateAlias(alias); err != nil {
+		return "", "", err
+	}
 
+	return alias, Build(shortURLAdress, alias), nil
 }
@@ -1,6 +1,7 @@
 package shorturl
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -33,3 +34,33 @@ func TestShorten(t *testing.T) {
 		})
 	}
 }
+
+func TestAlias(t *testing.T) {
+	testCases := []struct {
+		name, alias, key, shurl string
+		wantErr                 bool
+	}{
+		{name: "valid alias", alias: "spring-sale", key: "spring-sale", shurl: "http://localhost:8080/spring-sale"},
+		{name: "trimmed alias", alias: " spring_sale ", key: "spring_sale", shurl: "http://localhost:8080/spring_sale"},
+		{name: "too short", alias: "ab", wantErr: true},
+		{name: "too long", alias: strings.Repeat("a", MaxAliasLength+1), wantErr: true},
+		{name: "bad charset", alias: "spring/sale", wantErr: true},
+		{name: "reserved", alias: "Ping", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, shurl, err := Alias(tc.alias, "http://localhost:8080")
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Alias(%q) returned no error", tc.alias)
+				}
+				return
+			}
+
+			if err != nil || key != tc.key || shurl != tc.shurl {
+				t.Errorf("Alias(%q) = %q, %q, %v", tc.alias, key, shurl, err)
+			}
+		})
+	}
+}
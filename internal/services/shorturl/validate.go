@@ -0,0 +1,131 @@
+package shorturl
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// DefaultSchemes lists the URL schemes allowed when none are configured.
+const DefaultSchemes = "http,https"
+
+// DefaultMaxURLLength is the maximum URL length used when none is configured.
+const DefaultMaxURLLength = 2048
+
+// Reasons of the URL validation errors.
+const (
+	ReasonEmpty       = "empty"
+	ReasonMalformed   = "malformed"
+	ReasonNotAbsolute = "not_absolute"
+	ReasonScheme      = "scheme_not_allowed"
+	ReasonTooLong     = "too_long"
+	ReasonPrivateHost = "private_host"
+)
+
+// ErrInvalidURL indicates that an original URL violates the URL policy.
+var ErrInvalidURL = errors.New("invalid URL")
+
+// ValidationError describes why an original URL was rejected.
+type ValidationError struct {
+	// Reason is a machine readable reason, one of the Reason constants.
+	Reason string
+	// Message is a human readable description.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidURL, e.Message)
+}
+
+// Unwrap returns ErrInvalidURL, so that errors.Is(err, ErrInvalidURL) reports true.
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidURL
+}
+
+// Validator checks original URLs against the URL policy: an absolute URL with
+// an allowed scheme and a host, not longer than the maximum length and, optionally,
+// not pointing to a private or loopback host.
+type Validator struct {
+	schemes     map[string]bool
+	maxLength   int
+	denyPrivate bool
+}
+
+// NewValidator creates a Validator. Empty or zero arguments fall back to
+// DefaultSchemes and DefaultMaxURLLength.
+func NewValidator(schemes string, maxLength int, denyPrivate bool) *Validator {
+	if strings.TrimSpace(schemes) == "" {
+		schemes = DefaultSchemes
+	}
+
+	if maxLength <= 0 {
+		maxLength = DefaultMaxURLLength
+	}
+
+	v := &Validator{schemes: make(map[string]bool), maxLength: maxLength, denyPrivate: denyPrivate}
+	for _, scheme := range strings.Split(schemes, ",") {
+		if scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme != "" {
+			v.schemes[scheme] = true
+		}
+	}
+
+	return v
+}
+
+// Validate returns a *ValidationError if the URL violates the URL policy.
+func (v *Validator) Validate(rawURL string) error {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return &ValidationError{Reason: ReasonEmpty, Message: "URL is empty"}
+	}
+
+	if len(rawURL) > v.maxLength {
+		return &ValidationError{Reason: ReasonTooLong,
+			Message: fmt.Sprintf("URL is longer than %d characters", v.maxLength)}
+	}
+
+	if strings.IndexFunc(rawURL, unicode.IsSpace) >= 0 {
+		return &ValidationError{Reason: ReasonMalformed, Message: "URL contains whitespace"}
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return &ValidationError{Reason: ReasonMalformed, Message: "URL cannot be parsed"}
+	}
+
+	if u.Scheme == "" || u.Host == "" || u.Hostname() == "" {
+		return &ValidationError{Reason: ReasonNotAbsolute, Message: "URL must be absolute and contain a host"}
+	}
+
+	if !v.schemes[strings.ToLower(u.Scheme)] {
+		return &ValidationError{Reason: ReasonScheme,
+			Message: fmt.Sprintf("URL scheme %q is not allowed", u.Scheme)}
+	}
+
+	if v.denyPrivate && isPrivateHost(u.Hostname()) {
+		return &ValidationError{Reason: ReasonPrivateHost, Message: "URL points to a private or loopback host"}
+	}
+
+	return nil
+}
+
+// isPrivateHost reports whether the host is a loopback name or a private,
+// loopback, link-local or unspecified IP address. Host names are not resolved.
+func isPrivateHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	return ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsUnspecified()
+}
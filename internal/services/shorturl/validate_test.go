@@ -0,0 +1,54 @@
+package shorturl
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name        string
+		schemes     string
+		denyPrivate bool
+		url         string
+		reason      string
+	}{
+		{name: "valid", url: "https://practicum.yandex.ru/"},
+		{name: "empty", url: "   ", reason: ReasonEmpty},
+		{name: "javascript", url: "javascript:alert(1)", reason: ReasonNotAbsolute},
+		{name: "random text", url: "some random text", reason: ReasonMalformed},
+		{name: "relative", url: "/path/only", reason: ReasonNotAbsolute},
+		{name: "scheme not allowed", url: "ftp://example.com/file", reason: ReasonScheme},
+		{name: "scheme allowed", schemes: "https,ftp", url: "ftp://example.com/file"},
+		{name: "too long", url: "https://example.com/" + strings.Repeat("a", DefaultMaxURLLength), reason: ReasonTooLong},
+		{name: "private allowed", url: "http://127.0.0.1/"},
+		{name: "loopback", denyPrivate: true, url: "http://127.0.0.1/", reason: ReasonPrivateHost},
+		{name: "private", denyPrivate: true, url: "http://10.0.0.1:8080/", reason: ReasonPrivateHost},
+		{name: "localhost", denyPrivate: true, url: "http://LOCALHOST/", reason: ReasonPrivateHost},
+		{name: "ipv6 loopback", denyPrivate: true, url: "http://[::1]/", reason: ReasonPrivateHost},
+		{name: "public", denyPrivate: true, url: "http://8.8.8.8/"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewValidator(tc.schemes, 0, tc.denyPrivate).Validate(tc.url)
+
+			if tc.reason == "" {
+				if err != nil {
+					t.Errorf("Validate(%q) error = %v; want nil", tc.url, err)
+				}
+				return
+			}
+
+			var ve *ValidationError
+			if !errors.As(err, &ve) || !errors.Is(err, ErrInvalidURL) {
+				t.Fatalf("Validate(%q) error = %v; want %v", tc.url, err, ErrInvalidURL)
+			}
+
+			if ve.Reason != tc.reason {
+				t.Errorf("Validate(%q) reason = %q; want %q", tc.url, ve.Reason, tc.reason)
+			}
+		})
+	}
+}
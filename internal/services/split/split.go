@@ -0,0 +1,60 @@
+// Package split spreads the traffic of a short link across its weighted variants.
+// The variants are numbered from one, zero means no variant.
+package split
+
+import (
+	"math/rand"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+const (
+	// MaxVariants is the number of variants a link may have.
+	MaxVariants = 16
+	// MaxWeight is the largest weight of a variant.
+	MaxWeight = 10000
+)
+
+// Total returns the sum of the weights of the variants.
+func Total(variants []models.Variant) int {
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+
+	return total
+}
+
+// Pick returns the number of the variant the point falls on when the variants are
+// laid out one after another by their weights, starting from zero. It returns zero
+// if the point is out of range.
+func Pick(variants []models.Variant, point int) int {
+	if point < 0 {
+		return 0
+	}
+
+	for i, v := range variants {
+		if point < v.Weight {
+			return i + 1
+		}
+		point -= v.Weight
+	}
+
+	return 0
+}
+
+// Choose returns the number of a variant chosen at random in proportion to the weights.
+// It returns zero if there are no variants.
+func Choose(variants []models.Variant) int {
+	total := Total(variants)
+	if total <= 0 {
+		return 0
+	}
+
+	return Pick(variants, rand.Intn(total))
+}
+
+// Valid reports whether the number refers to one of the variants.
+func Valid(variants []models.Variant, n int) bool {
+	return n >= 1 && n <= len(variants)
+}
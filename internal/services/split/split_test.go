@@ -0,0 +1,57 @@
+package split
+
+import (
+	"testing"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+func TestPick(t *testing.T) {
+	variants := []models.Variant{
+		{URL: "https://example.com/a", Weight: 70},
+		{URL: "https://example.com/b", Weight: 30},
+	}
+
+	testCases := []struct {
+		point int
+		want  int
+	}{
+		{point: -1, want: 0},
+		{point: 0, want: 1},
+		{point: 69, want: 1},
+		{point: 70, want: 2},
+		{point: 99, want: 2},
+		{point: 100, want: 0},
+	}
+
+	for _, tc := range testCases {
+		if got := Pick(variants, tc.point); got != tc.want {
+			t.Errorf("Pick(%d) = %d; want %d", tc.point, got, tc.want)
+		}
+	}
+}
+
+func TestChoose(t *testing.T) {
+	if got := Choose(nil); got != 0 {
+		t.Errorf("Choose(nil) = %d; want 0", got)
+	}
+
+	variants := []models.Variant{
+		{URL: "https://example.com/a", Weight: 3},
+		{URL: "https://example.com/b", Weight: 1},
+	}
+
+	const n = 10000
+	counts := make([]int, len(variants)+1)
+	for i := 0; i < n; i++ {
+		counts[Choose(variants)]++
+	}
+
+	if counts[0] != 0 {
+		t.Errorf("Choose() returned no variant %d times", counts[0])
+	}
+	// the share of the first variant is 75%, far from the bounds by chance
+	if counts[1] < n*70/100 || counts[1] > n*80/100 {
+		t.Errorf("Choose() picked the first variant %d times of %d; want about 75%%", counts[1], n)
+	}
+}
@@ -0,0 +1,67 @@
+// Package tags organizes the short links of a user with free-form labels.
+// Tags are compared case-insensitively and stored in their normalized form.
+package tags
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	// MaxTags is the number of tags a link may have.
+	MaxTags = 20
+	// MaxLength is the length of the longest tag in characters.
+	MaxLength = 32
+)
+
+// Normalize returns the tag trimmed and in lower case.
+func Normalize(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// Valid reports whether the normalized tag is made of letters, digits, dashes,
+// underscores and dots, and is at most MaxLength characters long.
+func Valid(tag string) bool {
+	if tag == "" || len([]rune(tag)) > MaxLength {
+		return false
+	}
+	for _, c := range tag {
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '-' && c != '_' && c != '.' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Unique returns the tags without repetitions, keeping the first occurrence of each.
+func Unique(tags []string) []string {
+	var unique []string
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		if !seen[tag] {
+			seen[tag] = true
+			unique = append(unique, tag)
+		}
+	}
+
+	return unique
+}
+
+// HasAll reports whether the tags of a link include every tag of the filter.
+func HasAll(tags []string, filter []string) bool {
+	for _, f := range filter {
+		found := false
+		for _, tag := range tags {
+			if tag == f {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
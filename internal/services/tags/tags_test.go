@@ -0,0 +1,61 @@
+package tags
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValid(t *testing.T) {
+	testCases := []struct {
+		tag  string
+		want bool
+	}{
+		{tag: "marketing", want: true},
+		{tag: "q3-2026", want: true},
+		{tag: "v1.2_beta", want: true},
+		{tag: "реклама", want: true},
+		{tag: "", want: false},
+		{tag: "two words", want: false},
+		{tag: "a,b", want: false},
+		{tag: "abcdefghijklmnopqrstuvwxyz0123456", want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := Valid(tc.tag); got != tc.want {
+			t.Errorf("Valid(%q) = %v; want %v", tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := Normalize("  Marketing "); got != "marketing" {
+		t.Errorf("Normalize() = %q; want %q", got, "marketing")
+	}
+}
+
+func TestUnique(t *testing.T) {
+	got := Unique([]string{"b", "a", "b", "c", "a"})
+	if want := []string{"b", "a", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Unique() = %v; want %v", got, want)
+	}
+}
+
+func TestHasAll(t *testing.T) {
+	tags := []string{"marketing", "q3"}
+
+	testCases := []struct {
+		filter []string
+		want   bool
+	}{
+		{filter: nil, want: true},
+		{filter: []string{"q3"}, want: true},
+		{filter: []string{"q3", "marketing"}, want: true},
+		{filter: []string{"q3", "sales"}, want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := HasAll(tags, tc.filter); got != tc.want {
+			t.Errorf("HasAll(%v) = %v; want %v", tc.filter, got, tc.want)
+		}
+	}
+}
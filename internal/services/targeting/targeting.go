@@ -0,0 +1,138 @@
+// Package targeting provides the targeting rules of short links: detection of the
+// client platform and matching of the clients against the rules of a link.
+package targeting
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+// Platforms of the clients detected from their User-Agent.
+const (
+	PlatformIOS     = "ios"
+	PlatformAndroid = "android"
+	PlatformWindows = "windows"
+	PlatformMacOS   = "macos"
+	PlatformLinux   = "linux"
+)
+
+// MaxRules is the number of targeting rules a link may have.
+const MaxRules = 16
+
+// Client describes the client opening a short link.
+type Client struct {
+	UserAgent      string
+	AcceptLanguage string
+	Referrer       string
+	// Country is the ISO 3166-1 alpha-2 code of the country of the client IP address,
+	// empty if unknown.
+	Country string
+}
+
+// ValidPlatform reports whether the platform can be detected from a User-Agent.
+func ValidPlatform(platform string) bool {
+	switch platform {
+	case PlatformIOS, PlatformAndroid, PlatformWindows, PlatformMacOS, PlatformLinux:
+		return true
+	}
+
+	return false
+}
+
+// ValidCountry reports whether the country is formed as an ISO 3166-1 alpha-2 code.
+func ValidCountry(country string) bool {
+	if len(country) != 2 {
+		return false
+	}
+	for _, c := range country {
+		if (c < 'A' || c > 'Z') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Platform detects the platform of the client from its User-Agent.
+// It returns an empty string if the platform is unknown.
+func Platform(userAgent string) string {
+	switch {
+	// iOS browsers claim to be "like Mac OS X"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"),
+		strings.Contains(userAgent, "iPod"):
+		return PlatformIOS
+	// Android browsers claim to run on Linux
+	case strings.Contains(userAgent, "Android"):
+		return PlatformAndroid
+	case strings.Contains(userAgent, "Windows"):
+		return PlatformWindows
+	case strings.Contains(userAgent, "Macintosh"), strings.Contains(userAgent, "Mac OS X"):
+		return PlatformMacOS
+	case strings.Contains(userAgent, "Linux"), strings.Contains(userAgent, "X11"):
+		return PlatformLinux
+	}
+
+	return ""
+}
+
+// Match returns the URL of the first rule the client matches all conditions of.
+// It reports false if the client matches none of the rules.
+func Match(rules []models.Rule, c Client) (string, bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+
+	platform := Platform(c.UserAgent)
+	for _, rule := range rules {
+		if rule.Platform != "" && rule.Platform != platform {
+			continue
+		}
+		if rule.Language != "" && !acceptsLanguage(c.AcceptLanguage, rule.Language) {
+			continue
+		}
+		if rule.Referrer != "" && !fromHost(c.Referrer, rule.Referrer) {
+			continue
+		}
+		if rule.Country != "" && !strings.EqualFold(rule.Country, c.Country) {
+			continue
+		}
+
+		return rule.URL, true
+	}
+
+	return "", false
+}
+
+// acceptsLanguage reports whether the Accept-Language header accepts the language.
+// A language matches the tags it is a prefix of, e.g. "de" matches "de-AT".
+// The tags with zero quality are not accepted.
+func acceptsLanguage(header string, language string) bool {
+	for _, item := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(item), ";")
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok && strings.Trim(q, "0.") == "" {
+			continue
+		}
+
+		if strings.EqualFold(tag, language) ||
+			len(tag) > len(language) && tag[len(language)] == '-' && strings.EqualFold(tag[:len(language)], language) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// fromHost reports whether the referrer URL points to the host or its subdomains.
+func fromHost(referrer string, host string) bool {
+	u, err := url.Parse(referrer)
+	if err != nil {
+		return false
+	}
+
+	h := strings.ToLower(u.Hostname())
+	host = strings.ToLower(host)
+
+	return h == host || strings.HasSuffix(h, "."+host)
+}
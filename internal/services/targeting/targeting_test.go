@@ -0,0 +1,77 @@
+package targeting
+
+import (
+	"testing"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+const (
+	iPhoneUA  = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"
+	androidUA = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 Chrome/120.0 Mobile Safari/537.36"
+	windowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"
+	macUA     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_0) AppleWebKit/605.1.15 Version/17.0 Safari/605.1.15"
+	linuxUA   = "Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0"
+)
+
+func TestPlatform(t *testing.T) {
+	testCases := []struct {
+		userAgent string
+		want      string
+	}{
+		{userAgent: iPhoneUA, want: PlatformIOS},
+		{userAgent: androidUA, want: PlatformAndroid},
+		{userAgent: windowsUA, want: PlatformWindows},
+		{userAgent: macUA, want: PlatformMacOS},
+		{userAgent: linuxUA, want: PlatformLinux},
+		{userAgent: "curl/8.4.0", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := Platform(tc.userAgent); got != tc.want {
+			t.Errorf("Platform(%q) = %q; want %q", tc.userAgent, got, tc.want)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	rules := []models.Rule{
+		{Platform: PlatformIOS, URL: "https://apps.apple.com/app/id1"},
+		{Platform: PlatformAndroid, URL: "https://play.google.com/store/apps/details?id=app"},
+		{Language: "de", Referrer: "news.example", URL: "https://example.com/de/news"},
+		{Language: "pt-BR", URL: "https://example.com/br"},
+		{Country: "CH", URL: "https://example.com/ch"},
+	}
+
+	testCases := []struct {
+		name   string
+		client Client
+		want   string
+		ok     bool
+	}{
+		{name: "ios", client: Client{UserAgent: iPhoneUA}, want: "https://apps.apple.com/app/id1", ok: true},
+		{name: "android", client: Client{UserAgent: androidUA},
+			want: "https://play.google.com/store/apps/details?id=app", ok: true},
+		{name: "first rule wins", client: Client{UserAgent: iPhoneUA, AcceptLanguage: "pt-BR"},
+			want: "https://apps.apple.com/app/id1", ok: true},
+		{name: "all conditions", client: Client{UserAgent: windowsUA, AcceptLanguage: "de-AT,de;q=0.9,en;q=0.5",
+			Referrer: "https://www.news.example/today"}, want: "https://example.com/de/news", ok: true},
+		{name: "missing condition", client: Client{UserAgent: windowsUA, AcceptLanguage: "de-AT"}},
+		{name: "other host", client: Client{AcceptLanguage: "de", Referrer: "https://fakenews.example/"}},
+		{name: "zero quality", client: Client{AcceptLanguage: "en, pt-BR;q=0"}},
+		{name: "region", client: Client{AcceptLanguage: "en, pt-br;q=0.8"}, want: "https://example.com/br", ok: true},
+		{name: "other region", client: Client{AcceptLanguage: "pt-PT"}},
+		{name: "country", client: Client{AcceptLanguage: "en", Country: "ch"}, want: "https://example.com/ch", ok: true},
+		{name: "other country", client: Client{AcceptLanguage: "en", Country: "AT"}},
+		{name: "web", client: Client{UserAgent: macUA, AcceptLanguage: "en-US"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := Match(rules, tc.client)
+			if got != tc.want || ok != tc.ok {
+				t.Errorf("Match() = %q, %v; want %q, %v", got, ok, tc.want, tc.ok)
+			}
+		})
+	}
+}
@@ -12,6 +12,48 @@ type MockKeeper struct {
 	mock.Mock
 }
 
+// AddClick provides a mock function with given fields: _a0, _a1
+func (_m *MockKeeper) AddClick(_a0 string, _a1 models.DataURL) (models.DataURL, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 models.DataURL
+	if rf, ok := ret.Get(0).(func(string, models.DataURL) models.DataURL); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(models.DataURL)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.DataURL) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AddVariantClick provides a mock function with given fields: _a0, _a1, _a2
+func (_m *MockKeeper) AddVariantClick(_a0 string, _a1 models.DataURL, _a2 int) (models.DataURL, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 models.DataURL
+	if rf, ok := ret.Get(0).(func(string, models.DataURL, int) models.DataURL); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		r0 = ret.Get(0).(models.DataURL)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.DataURL, int) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Close provides a mock function with given fields:
 func (_m *MockKeeper) Close() bool {
 	ret := _m.Called()
@@ -129,6 +171,46 @@ func (_m *MockKeeper) Ping() bool {
 	return r0
 }
 
+// Purge provides a mock function with given fields: _a0
+func (_m *MockKeeper) Purge(_a0 ...string) error {
+	_va := make([]interface{}, len(_a0))
+	for _i := range _a0 {
+		_va[_i] = _a0[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...string) error); ok {
+		r0 = rf(_a0...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RestoreBatch provides a mock function with given fields: _a0
+func (_m *MockKeeper) RestoreBatch(_a0 ...models.DeleteURL) error {
+	_va := make([]interface{}, len(_a0))
+	for _i := range _a0 {
+		_va[_i] = _a0[_i]
+	}
+	var _ca []interface{}
+	_ca = append(_ca, _va...)
+	ret := _m.Called(_ca...)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(...models.DeleteURL) error); ok {
+		r0 = rf(_a0...)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Save provides a mock function with given fields: _a0, _a1
 func (_m *MockKeeper) Save(_a0 string, _a1 models.DataURL) (models.DataURL, error) {
 	ret := _m.Called(_a0, _a1)
@@ -150,6 +232,27 @@ func (_m *MockKeeper) Save(_a0 string, _a1 models.DataURL) (models.DataURL, erro
 	return r0, r1
 }
 
+// SaveAlias provides a mock function with given fields: _a0, _a1
+func (_m *MockKeeper) SaveAlias(_a0 string, _a1 models.DataURL) (models.DataURL, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 models.DataURL
+	if rf, ok := ret.Get(0).(func(string, models.DataURL) models.DataURL); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(models.DataURL)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.DataURL) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // SaveBatch provides a mock function with given fields: _a0
 func (_m *MockKeeper) SaveBatch(_a0 map[string]models.DataURL) error {
 	ret := _m.Called(_a0)
@@ -185,6 +288,27 @@ func (_m *MockKeeper) SaveUser(_a0 string, _a1 models.DataUser) (models.DataUser
 	return r0, r1
 }
 
+// Update provides a mock function with given fields: _a0, _a1
+func (_m *MockKeeper) Update(_a0 string, _a1 models.DataURL) (models.DataURL, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 models.DataURL
+	if rf, ok := ret.Get(0).(func(string, models.DataURL) models.DataURL); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(models.DataURL)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string, models.DataURL) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // UpdateBatch provides a mock function with given fields: _a0
 func (_m *MockKeeper) UpdateBatch(_a0 ...models.DeleteURL) error {
 	_va := make([]interface{}, len(_a0))
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/tags"
+)
+
+// Sort orders of the URLs of a user.
+const (
+	// SortCreated orders the URLs by their creation time, the URLs created
+	// before the creation time was recorded come first.
+	SortCreated = "created"
+	// SortKey orders the URLs by their short keys.
+	SortKey = "key"
+)
+
+// Sizes of the pages of the URLs of a user.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// ErrInvalidPageToken indicates that the page token is malformed or was issued
+// for another sort order.
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// URLQuery selects and orders the URLs of a user and splits them into pages.
+// The zero value selects the first page of all the URLs sorted by creation time.
+type URLQuery struct {
+	// Tags keep the URLs tagged with all of them.
+	Tags []string
+	// Search keeps the URLs whose key or original URL contains it, regardless of case.
+	Search string
+	// Deleted keeps the deleted URLs if true and the live ones if false.
+	Deleted *bool
+	// CreatedFrom and CreatedTo keep the URLs created in the range, the end excluded.
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Sort is the sort order: SortCreated (default) or SortKey.
+	Sort string
+	// Desc reverses the sort order.
+	Desc bool
+	// PageSize is the number of URLs of a page, zero means DefaultPageSize.
+	PageSize int
+	// PageToken is the token of the page returned with the previous one, empty for the first page.
+	PageToken string
+}
+
+// match reports whether the URL stored under the key passes the filters of the query.
+func (q URLQuery) match(k string, v models.DataURL) bool {
+	if q.Deleted != nil && v.DeletedFlag != *q.Deleted {
+		return false
+	}
+
+	if q.CreatedFrom != nil && (v.CreatedAt == nil || v.CreatedAt.Before(*q.CreatedFrom)) {
+		return false
+	}
+	if q.CreatedTo != nil && (v.CreatedAt == nil || !v.CreatedAt.Before(*q.CreatedTo)) {
+		return false
+	}
+
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(k), search) && !strings.Contains(strings.ToLower(v.OriginalURL), search) {
+			return false
+		}
+	}
+
+	return tags.HasAll(v.Tags, q.Tags)
+}
+
+// cursor is the position of the last URL of a page, encoded in the page token
+// together with the sort order it is valid for.
+type cursor struct {
+	Sort    string `json:"s"`
+	Desc    bool   `json:"d,omitempty"`
+	Created int64  `json:"c,omitempty"`
+	Key     string `json:"k"`
+}
+
+// position returns the cursor of the URL stored under the key in the sort order.
+func position(sortBy string, desc bool, k string, v models.DataURL) cursor {
+	c := cursor{Sort: sortBy, Desc: desc, Key: k}
+	if sortBy == SortCreated && v.CreatedAt != nil {
+		c.Created = v.CreatedAt.UnixNano()
+	}
+
+	return c
+}
+
+// compare orders the cursors ascending: by creation time, then by key.
+func (c cursor) compare(o cursor) int {
+	switch {
+	case c.Created < o.Created:
+		return -1
+	case c.Created > o.Created:
+		return 1
+	}
+
+	return strings.Compare(c.Key, o.Key)
+}
+
+// token encodes the cursor as an opaque page token.
+func (c cursor) token() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// parseToken decodes the page token. It returns ErrInvalidPageToken if the token is
+// malformed or was issued for another sort order.
+func parseToken(token string, sortBy string, desc bool) (cursor, error) {
+	var c cursor
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return c, ErrInvalidPageToken
+	}
+	if err = json.Unmarshal(b, &c); err != nil || c.Sort != sortBy || c.Desc != desc {
+		return c, ErrInvalidPageToken
+	}
+
+	return c, nil
+}
+
+// page sorts the entries in the order of the query, keeps the ones following the
+// page token and returns the first page of them along with the token of the next page,
+// empty if it is the last one.
+func (q URLQuery) page(entries StorageURL) ([]models.DataURLite, string, error) {
+	sortBy := q.Sort
+	if sortBy == "" {
+		sortBy = SortCreated
+	}
+
+	size := q.PageSize
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	type entry struct {
+		pos cursor
+		v   models.DataURL
+	}
+
+	var after *cursor
+	if q.PageToken != "" {
+		c, err := parseToken(q.PageToken, sortBy, q.Desc)
+		if err != nil {
+			return nil, "", err
+		}
+		after = &c
+	}
+
+	list := make([]entry, 0, len(entries))
+	for k, v := range entries {
+		pos := position(sortBy, q.Desc, k, v)
+		if after != nil {
+			cmp := pos.compare(*after)
+			if q.Desc {
+				cmp = -cmp
+			}
+			if cmp <= 0 {
+				continue
+			}
+		}
+		list = append(list, entry{pos: pos, v: v})
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if q.Desc {
+			return list[i].pos.compare(list[j].pos) > 0
+		}
+		return list[i].pos.compare(list[j].pos) < 0
+	})
+
+	var next string
+	if len(list) > size {
+		list = list[:size]
+		next = list[size-1].pos.token()
+	}
+
+	data := make([]models.DataURLite, 0, len(list))
+	for _, e := range list {
+		data = append(data, lite(e.v))
+	}
+
+	return data, next, nil
+}
+
+// lite returns the listing of the URL.
+func lite(v models.DataURL) models.DataURLite {
+	return models.DataURLite{
+		OriginalURL: v.OriginalURL, ShortURL: v.ShortURL,
+		Deleted: v.DeletedFlag, CreatedAt: v.CreatedAt,
+		LinkOptions: models.LinkOptions{Variants: v.Variants, Tags: v.Tags, Note: v.Note, Domain: v.Domain}}
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/hll"
+)
+
+// Intervals of the buckets of the click statistics of a URL.
+const (
+	// IntervalHour buckets the clicks by the hour.
+	IntervalHour = "hour"
+	// IntervalDay buckets the clicks by the day, in UTC.
+	IntervalDay = "day"
+)
+
+// Sizes of the click statistics of a URL.
+const (
+	// DefaultStatsTop is the number of the top referrers, countries and user agents.
+	DefaultStatsTop = 10
+	MaxStatsTop     = 100
+	// MaxStatsBuckets is the number of the buckets of a range at most.
+	MaxStatsBuckets = 31 * 24
+)
+
+// StatsQuery selects the clicks of a URL and aggregates them into buckets.
+type StatsQuery struct {
+	// From and To are the range of the clicks, the end excluded, aligned to the interval.
+	From time.Time
+	To   time.Time
+	// Interval is the length of the buckets: IntervalHour or IntervalDay.
+	Interval string
+	// Top is the number of the top referrers, countries and user agents, zero means DefaultStatsTop.
+	Top int
+}
+
+// Step returns the length of the buckets of the query.
+func (q StatsQuery) Step() time.Duration {
+	if q.Interval == IntervalHour {
+		return time.Hour
+	}
+
+	return 24 * time.Hour
+}
+
+// TopLimit returns the number of the top values of the query.
+func (q StatsQuery) TopLimit() int {
+	if q.Top <= 0 {
+		return DefaultStatsTop
+	}
+	if q.Top > MaxStatsTop {
+		return MaxStatsTop
+	}
+
+	return q.Top
+}
+
+// fill sets the range of the query to the statistics and adds the buckets without clicks,
+// so that every bucket of the range is listed, in order.
+func (q StatsQuery) fill(stats *models.URLStats) {
+	stats.From, stats.To, stats.Interval = q.From.UTC(), q.To.UTC(), q.Interval
+
+	counted := make(map[int64]models.StatsBucket, len(stats.Buckets))
+	for _, b := range stats.Buckets {
+		counted[b.Start.Unix()] = b
+	}
+
+	step := q.Step()
+	buckets := make([]models.StatsBucket, 0, q.To.Sub(q.From)/step)
+	for t := stats.From; t.Before(stats.To); t = t.Add(step) {
+		b := counted[t.Unix()]
+		b.Start = t
+		buckets = append(buckets, b)
+	}
+	stats.Buckets = buckets
+}
+
+// EstimateUnique sets the unique visitors of the statistics and of their buckets, estimated
+// by the unions of the sketches of the visitors of the hours, by their start, in the range of
+// the query. The estimates of the listed buckets are at most their clicks, the buckets of
+// the hours with visitors are listed if missing.
+func EstimateUnique(q StatsQuery, stats *models.URLStats, hours map[time.Time]*hll.Sketch) {
+	step := q.Step()
+	total := hll.New()
+	byBucket := make(map[int64]*hll.Sketch)
+	for start, v := range hours {
+		if start.Before(q.From) || !start.Before(q.To) {
+			continue
+		}
+
+		total.Merge(v)
+		t := start.Truncate(step).Unix()
+		u, ok := byBucket[t]
+		if !ok {
+			u = hll.New()
+			byBucket[t] = u
+		}
+		u.Merge(v)
+	}
+
+	for i := range stats.Buckets {
+		b := &stats.Buckets[i]
+		if u, ok := byBucket[b.Start.Unix()]; ok {
+			b.Unique = min(int(u.Estimate()), b.Clicks)
+			delete(byBucket, b.Start.Unix())
+		}
+	}
+	for t, u := range byBucket {
+		stats.Buckets = append(stats.Buckets, models.StatsBucket{Start: time.Unix(t, 0).UTC(), Unique: int(u.Estimate())})
+	}
+
+	stats.Unique = min(int(total.Estimate()), stats.Clicks)
+	stats.UniqueError = hll.StdError()
+}
+
+// clickBucket is the rollup of the clicks of a URL in an hour.
+type clickBucket struct {
+	clicks    int
+	visitors  *hll.Sketch
+	referrers map[string]int
+	countries map[string]int
+	agents    map[string]int
+}
+
+// clickRollup is the rollup of the clicks of a URL by the Unix time of their hours.
+type clickRollup map[int64]*clickBucket
+
+// bucket returns the bucket of the hour starting at the Unix time.
+func (r clickRollup) bucket(hour int64) *clickBucket {
+	b, ok := r[hour]
+	if !ok {
+		b = &clickBucket{
+			visitors:  hll.New(),
+			referrers: make(map[string]int),
+			countries: make(map[string]int),
+			agents:    make(map[string]int),
+		}
+		r[hour] = b
+	}
+
+	return b
+}
+
+// count counts the click in the bucket of its hour, without its visitor.
+func (r clickRollup) count(e models.ClickEvent) *clickBucket {
+	b := r.bucket(e.Time.Truncate(time.Hour).Unix())
+	b.clicks++
+	b.referrers[e.Referrer]++
+	b.countries[e.Country]++
+	b.agents[e.UserAgent]++
+
+	return b
+}
+
+// add counts the click and its visitor in the bucket of its hour.
+func (r clickRollup) add(e models.ClickEvent) *clickBucket {
+	b := r.count(e)
+	b.visitors.Add(e.Visitor())
+
+	return b
+}
+
+// merge merges the sketch into the visitors of the hour starting at the time.
+func (r clickRollup) merge(start time.Time, visitors *hll.Sketch) {
+	r.bucket(start.Truncate(time.Hour).Unix()).visitors.Merge(visitors)
+}
+
+// stats aggregates the clicks in the range of the query. The buckets without clicks are
+// not listed.
+func (r clickRollup) stats(q StatsQuery) models.URLStats {
+	var stats models.URLStats
+
+	step := q.Step()
+	buckets := make(map[time.Time]int)
+	hours := make(map[time.Time]*hll.Sketch)
+	referrers := make(map[string]int)
+	countries := make(map[string]int)
+	agents := make(map[string]int)
+
+	for hour, b := range r {
+		t := time.Unix(hour, 0).UTC()
+		if t.Before(q.From) || !t.Before(q.To) {
+			continue
+		}
+
+		stats.Clicks += b.clicks
+		buckets[t.Truncate(step)] += b.clicks
+		hours[t] = b.visitors
+		for v, n := range b.referrers {
+			referrers[v] += n
+		}
+		for v, n := range b.countries {
+			countries[v] += n
+		}
+		for v, n := range b.agents {
+			agents[v] += n
+		}
+	}
+
+	for start, clicks := range buckets {
+		stats.Buckets = append(stats.Buckets, models.StatsBucket{Start: start, Clicks: clicks})
+	}
+	EstimateUnique(q, &stats, hours)
+	stats.Referrers = topCounts(referrers, q.TopLimit())
+	stats.Countries = topCounts(countries, q.TopLimit())
+	stats.UserAgents = topCounts(agents, q.TopLimit())
+
+	return stats
+}
+
+// topCounts returns up to n values with the most clicks, ties ordered by value.
+// The empty value, e.g. of the clicks without a referrer, is not listed.
+func topCounts(counts map[string]int, n int) []models.StatsCount {
+	list := make([]models.StatsCount, 0, len(counts))
+	for v, c := range counts {
+		if v != "" {
+			list = append(list, models.StatsCount{Value: v, Clicks: c})
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Clicks != list[j].Clicks {
+			return list[i].Clicks > list[j].Clicks
+		}
+		return list[i].Value < list[j].Value
+	})
+
+	if len(list) > n {
+		list = list[:n]
+	}
+
+	return list
+}
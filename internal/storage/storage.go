@@ -4,10 +4,16 @@ package storage
 
 import (
 	"errors"
+	"fmt"
+	"path"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/wurt83ow/tinyurl/internal/models"
+	"github.com/wurt83ow/tinyurl/internal/services/hll"
+	"github.com/wurt83ow/tinyurl/internal/services/search"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -15,9 +21,100 @@ import (
 // ErrConflict indicates a data conflict in the store.
 var ErrConflict = errors.New("data conflict")
 
+// ErrCollision indicates that a key is already bound to a different original URL.
+var ErrCollision = errors.New("key collision")
+
+// ErrNotFound indicates that there is no live entry under the key.
+var ErrNotFound = errors.New("not found")
+
+// ErrNotOwner indicates that the entry belongs to another user.
+var ErrNotOwner = errors.New("not the owner")
+
+// ErrGone indicates that the entry under the key has been deleted and waits for the purge.
+var ErrGone = errors.New("gone")
+
+// ErrClicksExhausted indicates that a click-limited link has served all of its redirects.
+var ErrClicksExhausted = errors.New("click limit reached")
+
+// CollisionError reports the keys of a batch that are already bound to different original URLs.
+type CollisionError struct {
+	Keys []string
+}
+
+// Error implements the error interface.
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrCollision, strings.Join(e.Keys, ", "))
+}
+
+// Unwrap returns ErrCollision, so that errors.Is(err, ErrCollision) reports true.
+func (e *CollisionError) Unwrap() error {
+	return ErrCollision
+}
+
+// Ownership scopes of the original URLs.
+const (
+	// ScopeGlobal makes an original URL unique across all users, the first
+	// user to shorten it owns the link.
+	ScopeGlobal = "global"
+	// ScopeUser makes an original URL unique per user, so that every user
+	// owns their own copy of the link.
+	ScopeUser = "user"
+)
+
+// ErrUnknownScope indicates that the ownership scope is not supported.
+var ErrUnknownScope = errors.New("unknown ownership scope")
+
+// ParseScope checks the ownership scope. An empty scope falls back to ScopeGlobal.
+func ParseScope(scope string) (string, error) {
+	switch scope {
+	case "", ScopeGlobal:
+		return ScopeGlobal, nil
+	case ScopeUser:
+		return ScopeUser, nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownScope, scope)
+}
+
+// Owner returns the owner an original URL is unique for in the scope:
+// the user in ScopeUser and nobody in ScopeGlobal.
+func Owner(scope string, userID string) string {
+	if scope == ScopeUser {
+		return userID
+	}
+
+	return ""
+}
+
 // StorageURL represents a mapping of string keys to DataURL values.
+// The URLs are stored under the keys returned by DomainKey.
 type StorageURL = map[string]models.DataURL
 
+// DomainKey returns the storage key of the short key on the domain: the short key
+// itself on the default domain, named by the empty string, and "domain/key" on the
+// other ones, so that the same short key can be bound on every domain.
+func DomainKey(domain string, key string) string {
+	if domain == "" {
+		return key
+	}
+
+	return domain + "/" + key
+}
+
+// SplitKey splits the storage key into the domain and the short key.
+func SplitKey(k string) (domain string, key string) {
+	if i := strings.LastIndex(k, "/"); i >= 0 {
+		return k[:i], k[i+1:]
+	}
+
+	return "", k
+}
+
+// DataKey returns the storage key of the URL: the last segment of its short URL on its domain.
+func DataKey(v models.DataURL) string {
+	return DomainKey(v.Domain, path.Base(v.ShortURL))
+}
+
 // StorageUser represents a mapping of string keys to DataUser values.
 type StorageUser = map[string]models.DataUser
 
@@ -32,8 +129,28 @@ type MemoryStorage struct {
 	users  StorageUser
 	keeper Keeper
 	log    Log
+	scope  string
 	dmx    sync.RWMutex
 	umx    sync.RWMutex
+	// byUser indexes the keys of the URLs by the id of their users, byText indexes
+	// the searchable texts of the URLs of every user and byOwned the keys of the URLs
+	// by their original URLs owned in the scope, all guarded by dmx
+	byUser  map[string]map[string]struct{}
+	byText  map[string]*search.Index
+	byOwned map[ownedURL]string
+	// clicks are the rollups of the clicks of the URLs by key, unless the keeper
+	// aggregates them itself, guarded by cmx
+	clicks map[string]clickRollup
+	cmx    sync.Mutex
+	// seq is the sequence of the short keys, unless the keeper persists it
+	seq atomic.Uint64
+}
+
+// ownedURL identifies an original URL on a domain owned in the scope, see Owner.
+type ownedURL struct {
+	owner  string
+	domain string
+	url    string
 }
 
 // Keeper is an interface representing methods for loading, saving, and updating data in storage.
@@ -43,15 +160,62 @@ type Keeper interface {
 	GetUsersCount() (int, error)
 	GetURLsCount() (int, error)
 	Save(string, models.DataURL) (models.DataURL, error)
+	SaveAlias(string, models.DataURL) (models.DataURL, error)
 	SaveUser(string, models.DataUser) (models.DataUser, error)
 	SaveBatch(StorageURL) error
+	Update(string, models.DataURL) (models.DataURL, error)
 	UpdateBatch(...models.DeleteURL) error
+	RestoreBatch(...models.DeleteURL) error
+	Purge(...string) error
+	AddClick(string, models.DataURL) (models.DataURL, error)
+	AddVariantClick(string, models.DataURL, int) (models.DataURL, error)
 	Ping() bool
 	Close() bool
 }
 
+// Searcher is implemented by the keepers able to search the URLs of a user themselves.
+// SearchKeys returns the keys of the URLs of the user whose texts contain all of the
+// lower-case terms, as defined by the search package, newest first.
+type Searcher interface {
+	SearchKeys(userID string, terms []string, limit int) ([]string, error)
+}
+
+// ClickKeeper is implemented by the keepers able to record the click events of the URLs.
+type ClickKeeper interface {
+	SaveClicks(events ...models.ClickEvent) error
+}
+
+// ClickLoader is implemented by the keepers able to read the recorded click events back.
+// LoadClicks calls fn for every recorded event, oldest first.
+type ClickLoader interface {
+	LoadClicks(fn func(models.ClickEvent)) error
+}
+
+// ClickStatsKeeper is implemented by the keepers able to aggregate the recorded clicks
+// themselves. ClickStats returns the statistics of the clicks of the URL stored under
+// the key in the range of the query; the buckets without clicks may be left out.
+type ClickStatsKeeper interface {
+	ClickStats(key string, q StatsQuery) (models.URLStats, error)
+}
+
+// SketchKeeper is implemented by the keepers able to persist the HyperLogLog sketches of
+// the visitors of the URLs by hour, see the hll package. SaveSketches merges the sketches
+// into the stored ones and LoadSketches calls fn for the stored sketches.
+type SketchKeeper interface {
+	SaveSketches(sketches ...models.ClickSketch) error
+	LoadSketches(fn func(models.ClickSketch)) error
+}
+
+// SequenceKeeper is implemented by the keepers able to persist the sequence the counter
+// based short keys are generated from. NextSequence returns the next number of the
+// sequence, never issued before, even once the URLs of the issued keys are purged.
+type SequenceKeeper interface {
+	NextSequence() (uint64, error)
+}
+
 // NewMemoryStorage creates a new MemoryStorage instance with the provided Keeper and logger.
-func NewMemoryStorage(keeper Keeper, log Log) *MemoryStorage {
+// The scope defines whether an original URL is unique across all users or per user.
+func NewMemoryStorage(keeper Keeper, log Log, scope string) *MemoryStorage {
 	data := make(StorageURL)
 	users := make(StorageUser)
 
@@ -68,12 +232,162 @@ func NewMemoryStorage(keeper Keeper, log Log) *MemoryStorage {
 		}
 	}
 
-	return &MemoryStorage{
-		data:   data,
+	s := &MemoryStorage{
+		data:   make(StorageURL, len(data)),
 		users:  users,
 		keeper: keeper,
 		log:    log,
+		scope:  scope,
+		clicks: make(map[string]clickRollup),
+	}
+	for k, v := range data {
+		s.put(k, v)
+	}
+	s.seq.Store(uint64(len(data)))
+
+	// roll the recorded clicks of the stored URLs up, unless the keeper aggregates them
+	if cl, ok := keeper.(ClickLoader); ok && !s.keeperStats() {
+		s.loadClicks(cl)
+	}
+
+	return s
+}
+
+// loadClicks rolls the clicks of the stored URLs recorded by the keeper up. The visitors
+// are merged from the stored sketches if the keeper is a SketchKeeper.
+func (s *MemoryStorage) loadClicks(cl ClickLoader) {
+	sk, sketches := s.keeper.(SketchKeeper)
+
+	err := cl.LoadClicks(func(e models.ClickEvent) {
+		if _, exists := s.data[e.Key]; !exists {
+			return
+		}
+		if sketches {
+			s.rollupOf(e.Key).count(e)
+		} else {
+			s.rollupOf(e.Key).add(e)
+		}
+	})
+	if err != nil {
+		s.log.Info("cannot load click data: ", zap.Error(err))
+	}
+
+	if !sketches {
+		return
+	}
+
+	err = sk.LoadSketches(func(cs models.ClickSketch) {
+		if _, exists := s.data[cs.Key]; !exists {
+			return
+		}
+		visitors, err := hll.Parse(cs.Sketch)
+		if err != nil {
+			s.log.Info("cannot parse sketch: ", zap.String("key", cs.Key), zap.Error(err))
+			return
+		}
+		s.rollupOf(cs.Key).merge(cs.Start, visitors)
+	})
+	if err != nil {
+		s.log.Info("cannot load sketch data: ", zap.Error(err))
+	}
+}
+
+// keeperStats reports whether the keeper aggregates the clicks itself.
+func (s *MemoryStorage) keeperStats() bool {
+	_, ok := s.keeper.(ClickStatsKeeper)
+	return ok
+}
+
+// rollupOf returns the rollup of the clicks of the URL stored under the key.
+// The caller holds the lock of the clicks.
+func (s *MemoryStorage) rollupOf(k string) clickRollup {
+	r, ok := s.clicks[k]
+	if !ok {
+		r = make(clickRollup)
+		s.clicks[k] = r
+	}
+
+	return r
+}
+
+// put stores the URL under the key and indexes it.
+// The caller holds the lock of the data.
+func (s *MemoryStorage) put(k string, v models.DataURL) {
+	if cur, exists := s.data[k]; exists {
+		s.unindex(k, cur)
+	}
+	s.index(k, v)
+
+	s.data[k] = v
+}
+
+// remove deletes the URL stored under the key along with its index entries.
+// The caller holds the lock of the data.
+func (s *MemoryStorage) remove(k string) {
+	if cur, exists := s.data[k]; exists {
+		s.unindex(k, cur)
+	}
+
+	delete(s.data, k)
+}
+
+// index adds the URL stored under the key to the index of the keys by user, to the
+// index of the keys by the original URLs owned in the scope and to the search index
+// of its user.
+func (s *MemoryStorage) index(k string, v models.DataURL) {
+	if s.byUser == nil {
+		s.byUser = make(map[string]map[string]struct{})
+		s.byText = make(map[string]*search.Index)
+		s.byOwned = make(map[ownedURL]string)
+	}
+
+	if _, ok := s.byOwned[s.owned(v)]; !ok {
+		s.byOwned[s.owned(v)] = k
+	}
+
+	keys, ok := s.byUser[v.UserID]
+	if !ok {
+		keys = make(map[string]struct{})
+		s.byUser[v.UserID] = keys
+	}
+	keys[k] = struct{}{}
+
+	ix, ok := s.byText[v.UserID]
+	if !ok {
+		ix = search.NewIndex()
+		s.byText[v.UserID] = ix
 	}
+	ix.Add(k, search.Document(k, v))
+}
+
+// unindex removes the URL stored under the key, as it was indexed, from the indexes.
+func (s *MemoryStorage) unindex(k string, v models.DataURL) {
+	if s.byOwned[s.owned(v)] == k {
+		delete(s.byOwned, s.owned(v))
+	}
+
+	delete(s.byUser[v.UserID], k)
+	if len(s.byUser[v.UserID]) == 0 {
+		delete(s.byUser, v.UserID)
+	}
+
+	if ix, ok := s.byText[v.UserID]; ok {
+		ix.Remove(k, search.Document(k, v))
+		if ix.Len() == 0 {
+			delete(s.byText, v.UserID)
+		}
+	}
+}
+
+// owned returns the original URL of v on its domain as owned in the scope.
+func (s *MemoryStorage) owned(v models.DataURL) ownedURL {
+	return ownedURL{owner: Owner(s.scope, v.UserID), domain: v.Domain, url: v.OriginalURL}
+}
+
+// collides reports whether the stored entry cur prevents saving v under the same key:
+// the key is bound to a different original URL or, in ScopeUser, to another user.
+func (s *MemoryStorage) collides(cur models.DataURL, v models.DataURL) bool {
+	return cur.OriginalURL != v.OriginalURL || Owner(s.scope, cur.UserID) != Owner(s.scope, v.UserID)
 }
 
 // GetUsersCount Gets the number of users from Keeper.
@@ -86,17 +400,60 @@ func (s *MemoryStorage) GetURLsCount() (int, error) {
 	return s.keeper.GetURLsCount()
 }
 
+// NextSequence returns the next number of the sequence of the short keys, see
+// shorturl.Sequence. The sequence is persisted by the keeper if it is a SequenceKeeper,
+// otherwise it continues from the number of the loaded URLs.
+func (s *MemoryStorage) NextSequence() (uint64, error) {
+	if sk, ok := s.keeper.(SequenceKeeper); ok {
+		return sk.NextSequence()
+	}
+
+	return s.seq.Add(1), nil
+}
+
 // InsertURL inserts a new DataURL into the storage with the specified key.
+// It returns ErrConflict together with the existing entry if the original URL is
+// already owned on its domain in the scope, whatever the key, and ErrCollision together
+// with the existing entry if the key is already bound to a different original URL or,
+// in ScopeUser, to another user.
 func (s *MemoryStorage) InsertURL(k string, v models.DataURL) (models.DataURL, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	if owner, exists := s.byOwned[s.owned(v)]; exists {
+		return s.data[owner], ErrConflict
+	}
+
+	if cur, exists := s.data[k]; exists && s.collides(cur, v) {
+		return cur, ErrCollision
+	}
+
 	nv, err := s.SaveURL(k, v)
 	if err != nil {
 		return nv, err
 	}
 
+	s.put(k, nv)
+
+	return nv, nil
+}
+
+// InsertAlias reserves the custom alias k for the DataURL. It returns ErrConflict
+// together with the existing entry if the alias is already taken.
+func (s *MemoryStorage) InsertAlias(k string, v models.DataURL) (models.DataURL, error) {
 	s.dmx.Lock()
 	defer s.dmx.Unlock()
 
-	s.data[k] = nv
+	if cur, exists := s.data[k]; exists {
+		return cur, ErrConflict
+	}
+
+	nv, err := s.SaveAlias(k, v)
+	if err != nil {
+		return nv, err
+	}
+
+	s.put(k, nv)
 
 	return nv, nil
 }
@@ -117,14 +474,57 @@ func (s *MemoryStorage) InsertUser(k string, v models.DataUser) (models.DataUser
 }
 
 // InsertBatch inserts a batch of DataURL values into the storage.
+// If some keys are already bound to different original URLs or, in ScopeUser,
+// to other users, nothing is inserted and a *CollisionError listing these keys is returned.
+//
+// The values whose original URL is already owned on their domain in the scope, by a stored
+// entry or by another value of the batch, are not inserted: like InsertURL returns the
+// existing entry, they are replaced in the batch by the owning entries.
 func (s *MemoryStorage) InsertBatch(stg StorageURL) error {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	insert := make(StorageURL, len(stg))
+	owners := make(map[ownedURL]struct{}, len(stg))
+	var owned []string
 	for k, v := range stg {
-		s.data[k] = v
+		o := s.owned(v)
+		if _, exists := s.byOwned[o]; exists {
+			owned = append(owned, k)
+			continue
+		}
+		if _, exists := owners[o]; exists {
+			owned = append(owned, k)
+			continue
+		}
+		owners[o] = struct{}{}
+		insert[k] = v
 	}
 
-	err := s.SaveBatch(stg)
-	if err != nil {
-		return err
+	var collisions []string
+	for k, v := range insert {
+		if cur, exists := s.data[k]; exists && s.collides(cur, v) {
+			collisions = append(collisions, k)
+		}
+	}
+
+	if len(collisions) != 0 {
+		return &CollisionError{Keys: collisions}
+	}
+
+	if len(insert) != 0 {
+		err := s.SaveBatch(insert)
+		if err != nil {
+			return err
+		}
+	}
+
+	for k, v := range insert {
+		s.put(k, v)
+	}
+
+	for _, k := range owned {
+		stg[k] = s.data[s.byOwned[s.owned(stg[k])]]
 	}
 
 	return nil
@@ -144,6 +544,157 @@ func (s *MemoryStorage) GetURL(k string) (models.DataURL, error) {
 	return v, nil
 }
 
+// UpdateURL changes the original URL of the entry stored under the key, keeping the key.
+// It returns ErrNotFound if there is no live entry under the key, ErrNotOwner if the
+// entry belongs to another user and ErrConflict together with the existing entry if the
+// keeper finds the original URL already shortened in the scope.
+func (s *MemoryStorage) UpdateURL(k string, userID string, originalURL string) (models.DataURL, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	v, exists := s.data[k]
+	if !exists || v.DeletedFlag {
+		return models.DataURL{}, ErrNotFound
+	}
+
+	if v.UserID != userID {
+		return models.DataURL{}, ErrNotOwner
+	}
+
+	v.OriginalURL = originalURL
+	if s.keeper != nil {
+		nv, err := s.keeper.Update(k, v)
+		if err != nil {
+			return nv, err
+		}
+		v = nv
+	}
+
+	s.put(k, v)
+
+	return v, nil
+}
+
+// UpdateTags replaces the tags of the entry stored under the key.
+// Like UpdateURL, it returns ErrNotFound if there is no live entry under the key
+// and ErrNotOwner if the entry belongs to another user.
+func (s *MemoryStorage) UpdateTags(k string, userID string, list []string) (models.DataURL, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	v, exists := s.data[k]
+	if !exists || v.DeletedFlag {
+		return models.DataURL{}, ErrNotFound
+	}
+
+	if v.UserID != userID {
+		return models.DataURL{}, ErrNotOwner
+	}
+
+	v.Tags = list
+	if s.keeper != nil {
+		nv, err := s.keeper.Update(k, v)
+		if err != nil {
+			return nv, err
+		}
+		v = nv
+	}
+
+	s.put(k, v)
+
+	return v, nil
+}
+
+// UseClick counts a redirect of the URL stored under the key and returns its data.
+// Only click-limited links are counted. The click is persisted by the keeper while
+// the storage is locked, so that two concurrent redirects cannot both take the last click.
+// It returns ErrClicksExhausted if the link has served all of its redirects.
+func (s *MemoryStorage) UseClick(k string) (models.DataURL, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	v, exists := s.data[k]
+	if !exists {
+		return models.DataURL{}, errors.New("value with such key doesn't exist")
+	}
+
+	if v.MaxClicks == 0 {
+		return v, nil
+	}
+
+	if v.ClicksExhausted() {
+		return v, ErrClicksExhausted
+	}
+
+	if s.keeper == nil {
+		v.Clicks++
+		s.data[k] = v
+
+		return v, nil
+	}
+
+	nv, err := s.keeper.AddClick(k, v)
+	if err != nil && !errors.Is(err, ErrClicksExhausted) {
+		return v, err
+	}
+
+	s.data[k] = nv
+
+	return nv, err
+}
+
+// AddVariantClick counts a redirect to the variant, numbered from one, of the split URL
+// stored under the key. It returns ErrNotFound if there is no such entry or variant.
+// The click is counted in memory and the storage is unlocked while the keeper persists it,
+// so that the redirects do not wait for each other. The count of the keeper, if greater,
+// is taken afterwards and the click is uncounted if the keeper fails.
+func (s *MemoryStorage) AddVariantClick(k string, variant int) error {
+	v, err := s.countVariantClick(k, variant, 1)
+	if err != nil || s.keeper == nil {
+		return err
+	}
+
+	nv, err := s.keeper.AddVariantClick(k, v, variant)
+	if err != nil {
+		_, _ = s.countVariantClick(k, variant, -1)
+		return err
+	}
+
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	if cur, exists := s.data[k]; exists && variant <= len(cur.Variants) &&
+		nv.Variants[variant-1].Clicks > cur.Variants[variant-1].Clicks {
+		cur.Variants = append([]models.Variant(nil), cur.Variants...)
+		cur.Variants[variant-1].Clicks = nv.Variants[variant-1].Clicks
+		s.data[k] = cur
+	}
+
+	return nil
+}
+
+// countVariantClick adds n clicks to the variant, numbered from one, of the split URL stored
+// under the key and returns the updated entry.
+func (s *MemoryStorage) countVariantClick(k string, variant int, n int) (models.DataURL, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	v, exists := s.data[k]
+	if !exists || variant < 1 || variant > len(v.Variants) {
+		return models.DataURL{}, ErrNotFound
+	}
+
+	// the variants are shared with the copies of the entry handed out before
+	v.Variants = append([]models.Variant(nil), v.Variants...)
+	v.Variants[variant-1].Clicks += n
+	s.data[k] = v
+
+	// the keeper updates the variants of the returned entry unlocked
+	v.Variants = append([]models.Variant(nil), v.Variants...)
+
+	return v, nil
+}
+
 // GetUser retrieves a DataUser from the storage with the specified key.
 func (s *MemoryStorage) GetUser(k string) (models.DataUser, error) {
 	s.umx.RLock()
@@ -157,20 +708,182 @@ func (s *MemoryStorage) GetUser(k string) (models.DataUser, error) {
 	return v, nil
 }
 
-// GetUserURLs retrieves a slice of DataURLite for a specific user from the storage.
-func (s *MemoryStorage) GetUserURLs(userID string) []models.DataURLite {
-	var data []models.DataURLite
+// GetUserByID retrieves the DataUser with the specified user id from the storage.
+// It returns ErrNotFound if there is no such user.
+func (s *MemoryStorage) GetUserByID(id string) (models.DataUser, error) {
+	s.umx.RLock()
+	defer s.umx.RUnlock()
+
+	for _, u := range s.users {
+		if u.UUID == id {
+			return u, nil
+		}
+	}
+
+	return models.DataUser{}, ErrNotFound
+}
+
+// GetUserURLs retrieves a page of the URLs of a user selected and ordered by the query,
+// along with the token of the next page, empty for the last page. Only the URLs of the user
+// are scanned, they are looked up in the index of the keys by user.
+// It returns ErrInvalidPageToken if the page token of the query is not valid.
+func (s *MemoryStorage) GetUserURLs(userID string, q URLQuery) ([]models.DataURLite, string, error) {
+	s.dmx.RLock()
+	defer s.dmx.RUnlock()
+
+	entries := make(StorageURL, len(s.byUser[userID]))
+	for k := range s.byUser[userID] {
+		if v := s.data[k]; q.match(k, v) {
+			entries[k] = v
+		}
+	}
+
+	return q.page(entries)
+}
+
+// SearchUserURLs retrieves up to limit URLs of a user whose key, original URL, host, tags
+// or note contain all of the terms of the query, regardless of case, newest first.
+// The search is run by the keeper if it is a Searcher, and by the search index of
+// the user otherwise. A zero limit means DefaultPageSize, the limit is at most MaxPageSize.
+func (s *MemoryStorage) SearchUserURLs(userID string, query string, limit int) ([]models.DataURLite, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return nil, nil
+	}
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+
+	if sk, ok := s.keeper.(Searcher); ok {
+		keys, err := sk.SearchKeys(userID, terms, limit)
+		if err != nil {
+			return nil, err
+		}
+
+		s.dmx.RLock()
+		defer s.dmx.RUnlock()
+
+		data := make([]models.DataURLite, 0, len(keys))
+		for _, k := range keys {
+			if v, exists := s.data[k]; exists && v.UserID == userID {
+				data = append(data, lite(v))
+			}
+		}
+		return data, nil
+	}
 
 	s.dmx.RLock()
 	defer s.dmx.RUnlock()
-	for _, u := range s.data {
-		if u.UserID == userID {
-			data = append(data, models.DataURLite{
-				OriginalURL: u.OriginalURL, ShortURL: u.ShortURL})
+
+	ix, ok := s.byText[userID]
+	if !ok {
+		return nil, nil
+	}
+
+	// the matching URLs are sorted newest first, like a page in the descending creation order
+	found := make(StorageURL)
+	for _, k := range ix.Candidates(terms) {
+		if v := s.data[k]; search.Match(search.Document(k, v), terms) {
+			found[k] = v
 		}
 	}
 
-	return data
+	data, _, err := URLQuery{Desc: true, PageSize: limit}.page(found)
+
+	return data, err
+}
+
+// SaveClicks saves the click events with the keeper if it is a ClickKeeper. Unless the keeper
+// is a ClickStatsKeeper, the events are also rolled up by hour for the statistics of the URLs,
+// and the updated sketches of the visitors of the hours are saved with the keeper if it is
+// a SketchKeeper.
+func (s *MemoryStorage) SaveClicks(events ...models.ClickEvent) error {
+	var errs []error
+
+	if !s.keeperStats() {
+		sk, ok := s.keeper.(SketchKeeper)
+		if sketches := s.rollupClicks(ok, events...); len(sketches) != 0 {
+			errs = append(errs, sk.SaveSketches(sketches...))
+		}
+	}
+
+	if ck, ok := s.keeper.(ClickKeeper); ok {
+		errs = append(errs, ck.SaveClicks(events...))
+	}
+
+	return errors.Join(errs...)
+}
+
+// rollupClicks rolls the click events up by hour. If asked, it returns the serialized
+// sketches of the visitors of the hours of the events.
+func (s *MemoryStorage) rollupClicks(sketches bool, events ...models.ClickEvent) []models.ClickSketch {
+	type hour struct {
+		key   string
+		start time.Time
+	}
+
+	s.cmx.Lock()
+	defer s.cmx.Unlock()
+
+	updated := make(map[hour]*hll.Sketch)
+	for _, e := range events {
+		b := s.rollupOf(e.Key).add(e)
+		if sketches {
+			updated[hour{key: e.Key, start: e.Time.UTC().Truncate(time.Hour)}] = b.visitors
+		}
+	}
+
+	list := make([]models.ClickSketch, 0, len(updated))
+	for h, visitors := range updated {
+		b, err := visitors.MarshalBinary()
+		if err != nil {
+			s.log.Info("cannot serialize sketch: ", zap.String("key", h.key), zap.Error(err))
+			continue
+		}
+		list = append(list, models.ClickSketch{Key: h.key, Start: h.start, Sketch: b})
+	}
+
+	return list
+}
+
+// GetURLStats returns the statistics of the clicks of the URL stored under the key in the
+// range of the query, with a bucket for every interval of the range. The statistics are
+// aggregated by the keeper if it is a ClickStatsKeeper, and from the rollups of the clicks
+// otherwise. It returns ErrNotFound if there is no entry under the key, ErrNotOwner if the
+// entry belongs to another user and ErrGone if the entry has been deleted, the clicks of a
+// deleted URL are kept until it is purged but are not reported.
+func (s *MemoryStorage) GetURLStats(k string, userID string, q StatsQuery) (models.URLStats, error) {
+	s.dmx.RLock()
+	v, exists := s.data[k]
+	s.dmx.RUnlock()
+
+	if !exists {
+		return models.URLStats{}, ErrNotFound
+	}
+	if v.UserID != userID {
+		return models.URLStats{}, ErrNotOwner
+	}
+	if v.DeletedFlag {
+		return models.URLStats{}, ErrGone
+	}
+
+	var stats models.URLStats
+	if sk, ok := s.keeper.(ClickStatsKeeper); ok {
+		var err error
+		if stats, err = sk.ClickStats(k, q); err != nil {
+			return models.URLStats{}, err
+		}
+	} else {
+		s.cmx.Lock()
+		stats = s.clicks[k].stats(q)
+		s.cmx.Unlock()
+	}
+	q.fill(&stats)
+
+	return stats, nil
 }
 
 // SaveURL saves a DataURL to the storage using the provided key.
@@ -182,27 +895,39 @@ func (s *MemoryStorage) SaveURL(k string, v models.DataURL) (models.DataURL, err
 	return s.keeper.Save(k, v)
 }
 
-// DeleteURLs deletes URLs from the storage based on the provided delete URLs.
-func (s *MemoryStorage) DeleteURLs(delUrls ...models.DeleteURL) error {
+// SaveAlias saves a DataURL with a custom alias to the storage using the provided key.
+func (s *MemoryStorage) SaveAlias(k string, v models.DataURL) (models.DataURL, error) {
 	if s.keeper == nil {
-		return nil
+		return v, nil
 	}
 
-	err := s.keeper.UpdateBatch(delUrls...)
-	if err != nil {
-		return err
+	return s.keeper.SaveAlias(k, v)
+}
+
+// DeleteURLs deletes URLs from the storage based on the provided delete URLs.
+// The URLs are given by their storage keys, only the URLs owned by the user of a request are deleted.
+func (s *MemoryStorage) DeleteURLs(delUrls ...models.DeleteURL) error {
+	if s.keeper != nil {
+		err := s.keeper.UpdateBatch(delUrls...)
+		if err != nil {
+			return err
+		}
 	}
 
-	s.dmx.RLock()
-	defer s.dmx.RUnlock()
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
 
+	now := time.Now().UTC()
 	for _, u := range delUrls {
 		for _, k := range u.ShortURLs {
-			cs := s.data[k]
+			cs, exists := s.data[k]
 
-			if cs.UserID == u.UserID && strings.Contains(cs.ShortURL, k) {
-				s.data[k] = models.DataURL{UUID: cs.UUID, ShortURL: cs.ShortURL,
-					OriginalURL: cs.OriginalURL, UserID: cs.UserID, DeletedFlag: true}
+			if exists && cs.UserID == u.UserID {
+				cs.DeletedFlag = true
+				if cs.DeletedAt == nil {
+					cs.DeletedAt = &now
+				}
+				s.data[k] = cs
 			}
 		}
 	}
@@ -210,6 +935,103 @@ func (s *MemoryStorage) DeleteURLs(delUrls ...models.DeleteURL) error {
 	return nil
 }
 
+// RestoreURLs clears the deleted flag of the URLs based on the provided restore URLs.
+// Like DeleteURLs, only the URLs of the storage keys owned by the user of a request are restored.
+func (s *MemoryStorage) RestoreURLs(resUrls ...models.DeleteURL) error {
+	if s.keeper != nil {
+		err := s.keeper.RestoreBatch(resUrls...)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	for _, u := range resUrls {
+		for _, k := range u.ShortURLs {
+			cs, exists := s.data[k]
+
+			if exists && cs.UserID == u.UserID && cs.DeletedFlag {
+				cs.DeletedFlag = false
+				cs.DeletedAt = nil
+				s.data[k] = cs
+			}
+		}
+	}
+
+	return nil
+}
+
+// PurgeURLs physically removes the URLs deleted before the time before.
+// It returns the number of the purged URLs.
+func (s *MemoryStorage) PurgeURLs(before time.Time) (int, error) {
+	s.dmx.Lock()
+	defer s.dmx.Unlock()
+
+	var keys, shortURLs []string
+	for k, v := range s.data {
+		if v.DeletedFlag && v.DeletedAt != nil && v.DeletedAt.Before(before) {
+			keys = append(keys, k)
+			shortURLs = append(shortURLs, v.ShortURL)
+		}
+	}
+
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	if s.keeper != nil {
+		if err := s.keeper.Purge(shortURLs...); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, k := range keys {
+		s.remove(k)
+	}
+
+	// the clicks of the purged URLs are not counted for the URLs reusing their keys
+	s.cmx.Lock()
+	for _, k := range keys {
+		delete(s.clicks, k)
+	}
+	s.cmx.Unlock()
+
+	return len(keys), nil
+}
+
+// ExpireURLs marks the URLs that have expired at the time now as deleted.
+// It returns the number of the expired URLs.
+func (s *MemoryStorage) ExpireURLs(now time.Time) (int, error) {
+	byUser := make(map[string][]string)
+	count := 0
+
+	s.dmx.RLock()
+	for k, v := range s.data {
+		if !v.DeletedFlag && v.Expired(now) {
+			byUser[v.UserID] = append(byUser[v.UserID], k)
+			count++
+		}
+	}
+	s.dmx.RUnlock()
+
+	if count == 0 {
+		return 0, nil
+	}
+
+	delUrls := make([]models.DeleteURL, 0, len(byUser))
+	for userID, keys := range byUser {
+		delUrls = append(delUrls, models.DeleteURL{UserID: userID, ShortURLs: keys})
+	}
+
+	if err := s.DeleteURLs(delUrls...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 // SaveUser saves a DataUser to the storage using the provided key.
 func (s *MemoryStorage) SaveUser(k string, v models.DataUser) (models.DataUser, error) {
 	if s.keeper == nil {
@@ -1,9 +1,16 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/mock"
 	"github.com/wurt83ow/tinyurl/internal/config"
 	"github.com/wurt83ow/tinyurl/internal/logger"
 	models "github.com/wurt83ow/tinyurl/internal/models"
@@ -43,7 +50,7 @@ func TestGetBaseConnection(t *testing.T) {
 	test := beforeEach(t)
 	test.keeper.On("Ping").Return(true)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	got := memStorage.GetBaseConnection()
 	fmt.Println(got)
 	if !got {
@@ -51,7 +58,7 @@ func TestGetBaseConnection(t *testing.T) {
 	}
 
 	test.keeper.On("Ping").Return(false)
-	memStorage = NewMemoryStorage(nil, test.nLogger)
+	memStorage = NewMemoryStorage(nil, test.nLogger, ScopeGlobal)
 	got = memStorage.GetBaseConnection()
 	fmt.Println(got)
 	if got {
@@ -65,14 +72,14 @@ func TestSaveBatch(t *testing.T) {
 	test := beforeEach(t)
 	test.keeper.On("SaveBatch", data).Return(nil)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	got := memStorage.SaveBatch(data)
 	if got != nil {
 		t.Errorf("SaveBatch return %v; want nil", got)
 	}
 
 	test.keeper.On("SaveBatch", data).Return(nil)
-	memStorage = NewMemoryStorage(nil, test.nLogger)
+	memStorage = NewMemoryStorage(nil, test.nLogger, ScopeGlobal)
 	got = memStorage.SaveBatch(data)
 	if got != nil {
 		t.Errorf("SaveBatch return %v; want nil", got)
@@ -84,14 +91,14 @@ func TestSaveUser(t *testing.T) {
 	test := beforeEach(t)
 	test.keeper.On("SaveUser", "some_key", data).Return(data, nil)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	_, err := memStorage.SaveUser("some_key", data)
 
 	if err != nil {
 		t.Errorf("SaveUser return error %v", err)
 	}
 
-	memStorage = NewMemoryStorage(nil, test.nLogger)
+	memStorage = NewMemoryStorage(nil, test.nLogger, ScopeGlobal)
 	_, err = memStorage.SaveUser("some_key", data)
 
 	if err != nil {
@@ -103,14 +110,14 @@ func TestGetURL(t *testing.T) {
 
 	test := beforeEach(t)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	_, err := memStorage.GetURL("some_key")
 
 	if err != nil {
 		t.Errorf("GetURL return error %v", err)
 	}
 
-	memStorage = NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage = NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	data, err := memStorage.GetURL("fake_key")
 
 	if err == nil {
@@ -122,14 +129,14 @@ func TestGetUser(t *testing.T) {
 
 	test := beforeEach(t)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	_, err := memStorage.GetUser("some_key")
 
 	if err != nil {
 		t.Errorf("GetUser return error %v", err)
 	}
 
-	memStorage = NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage = NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	data, err := memStorage.GetUser("fake_key")
 
 	if err == nil {
@@ -137,6 +144,22 @@ func TestGetUser(t *testing.T) {
 	}
 }
 
+func TestGetUserByID(t *testing.T) {
+
+	test := beforeEach(t)
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	data, err := memStorage.GetUserByID("some_user_UUID")
+
+	if err != nil || data.UUID != "some_user_UUID" {
+		t.Errorf("GetUserByID return %v, %v; want some_user_UUID", data, err)
+	}
+
+	if data, err = memStorage.GetUserByID("fake_UUID"); err != ErrNotFound {
+		t.Errorf("GetUserByID return %v, %v; want %v", data, err, ErrNotFound)
+	}
+}
+
 func TestInsertURL(t *testing.T) {
 
 	test := beforeEach(t)
@@ -145,7 +168,7 @@ func TestInsertURL(t *testing.T) {
 		OriginalURL: "some_origin"}
 
 	test.keeper.On("Save", "insert_key", data).Return(data, nil)
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	data, err := memStorage.InsertURL("insert_key", data)
 
 	if err != nil || data.UUID != "UUID_insertURL" {
@@ -153,6 +176,98 @@ func TestInsertURL(t *testing.T) {
 	}
 }
 
+func TestInsertAlias(t *testing.T) {
+
+	test := beforeEach(t)
+	data := models.DataURL{
+		ShortURL: "http://localhost:8080/spring-sale", OriginalURL: "https://example.com/sale"}
+
+	test.keeper.On("SaveAlias", "spring-sale", data).Return(data, nil).Once()
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+
+	if _, err := memStorage.InsertAlias("spring-sale", data); err != nil {
+		t.Errorf("InsertAlias return error %v", err)
+	}
+
+	// the alias is already reserved, the keeper must not be called again
+	if _, err := memStorage.InsertAlias("spring-sale", data); err != ErrConflict {
+		t.Errorf("InsertAlias return error %v; want %v", err, ErrConflict)
+	}
+}
+
+func TestInsertURLCollision(t *testing.T) {
+
+	test := beforeEach(t)
+	data := models.DataURL{
+		ShortURL: "http://localhost:8080/some_key", OriginalURL: "https://www.bing.com"}
+
+	// "some_key" is bound to https://www.google.com, the keeper must not be called
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	cur, err := memStorage.InsertURL("some_key", data)
+
+	if err != ErrCollision || cur.OriginalURL != "https://www.google.com" {
+		t.Errorf("InsertURL return %v, %v; want %v", cur.OriginalURL, err, ErrCollision)
+	}
+}
+
+func TestInsertURLUserScope(t *testing.T) {
+
+	test := beforeEach(t)
+	data := models.DataURL{
+		ShortURL: "http://localhost:8080/some_key", OriginalURL: "https://www.google.com",
+		UserID: "other_user_UUID"}
+
+	// in the user scope "some_key" belongs to another user, the keeper must not be called
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeUser)
+	if _, err := memStorage.InsertURL("some_key", data); err != ErrCollision {
+		t.Errorf("InsertURL return error %v; want %v", err, ErrCollision)
+	}
+
+	// in the global scope the link is shared, the keeper must not be called either
+	memStorage = NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	if cur, err := memStorage.InsertURL("some_key", data); err != ErrConflict || cur.UserID != "some_user_UUID" {
+		t.Errorf("InsertURL return %v, %v; want the entry of some_user_UUID, %v", cur, err, ErrConflict)
+	}
+}
+
+func TestInsertURLOwned(t *testing.T) {
+
+	test := beforeEach(t)
+	data := models.DataURL{
+		ShortURL: "http://localhost:8080/other_key", OriginalURL: "https://www.google.com",
+		UserID: "some_user_UUID"}
+
+	// the original URL is owned under another key, whatever the strategy of the keys
+	for _, memStorage := range []*MemoryStorage{
+		NewMemoryStorage(test.keeper, test.nLogger, ScopeUser),
+		NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal),
+	} {
+		if cur, err := memStorage.InsertURL("other_key", data); err != ErrConflict || cur.ShortURL != "http://localhost:8080/VajcMuGMY9h" {
+			t.Errorf("InsertURL return %v, %v; want the existing entry, %v", cur, err, ErrConflict)
+		}
+	}
+
+	// in the user scope another user owns a copy of the URL
+	data.UserID = "other_user_UUID"
+	test.keeper.On("Save", "other_key", data).Return(data, nil).Once()
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeUser)
+	if _, err := memStorage.InsertURL("other_key", data); err != nil {
+		t.Errorf("InsertURL return error %v", err)
+	}
+}
+
+func TestParseScope(t *testing.T) {
+	for scope, want := range map[string]string{"": ScopeGlobal, ScopeGlobal: ScopeGlobal, ScopeUser: ScopeUser} {
+		if got, err := ParseScope(scope); err != nil || got != want {
+			t.Errorf("ParseScope(%q) = %q, %v; want %q", scope, got, err, want)
+		}
+	}
+
+	if _, err := ParseScope("team"); !errors.Is(err, ErrUnknownScope) {
+		t.Errorf("ParseScope error = %v; want %v", err, ErrUnknownScope)
+	}
+}
+
 func TestInsertUser(t *testing.T) {
 
 	test := beforeEach(t)
@@ -160,7 +275,7 @@ func TestInsertUser(t *testing.T) {
 		Email: "test@gmail.com", Hash: []byte("some_hash"), Name: "some_name"}
 
 	test.keeper.On("SaveUser", "insert_key", data).Return(data, nil)
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	data, err := memStorage.InsertUser("insert_key", data)
 
 	if err != nil || data.UUID != "UUID_insertUSER" {
@@ -178,7 +293,7 @@ func TestInsertBatch(t *testing.T) {
 	data["batch_key"] = entry
 
 	test.keeper.On("SaveBatch", data).Return(nil)
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	err := memStorage.InsertBatch(data)
 
 	if err != nil {
@@ -187,41 +302,697 @@ func TestInsertBatch(t *testing.T) {
 
 }
 
+func TestInsertBatchCollision(t *testing.T) {
+
+	test := beforeEach(t)
+	data := StorageURL{
+		"batch_key": {OriginalURL: "some_origin"},
+		"some_key":  {OriginalURL: "https://www.bing.com"},
+	}
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	err := memStorage.InsertBatch(data)
+
+	var collision *CollisionError
+	if !errors.As(err, &collision) || !errors.Is(err, ErrCollision) {
+		t.Fatalf("InsertBatch return error %v; want %v", err, ErrCollision)
+	}
+
+	if len(collision.Keys) != 1 || collision.Keys[0] != "some_key" {
+		t.Errorf("InsertBatch collision keys %v; want [some_key]", collision.Keys)
+	}
+
+	// nothing of the batch must be inserted
+	if _, err := memStorage.GetURL("batch_key"); err == nil {
+		t.Errorf("InsertBatch inserted a part of the batch")
+	}
+}
+
+func TestInsertBatchOwned(t *testing.T) {
+	test := beforeEach(t)
+	data := StorageURL{
+		"batch_key":  {ShortURL: "http://localhost:8080/batch_key", OriginalURL: "https://www.google.com", UserID: "some_user_UUID"},
+		"first_key":  {ShortURL: "http://localhost:8080/first_key", OriginalURL: "https://www.bing.com", UserID: "some_user_UUID"},
+		"second_key": {ShortURL: "http://localhost:8080/second_key", OriginalURL: "https://www.bing.com", UserID: "some_user_UUID"},
+	}
+
+	// only one of the repeated URLs is saved, the stored one is not saved again
+	test.keeper.On("SaveBatch", mock.MatchedBy(func(got StorageURL) bool {
+		return len(got) == 1
+	})).Return(nil).Once()
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	if err := memStorage.InsertBatch(data); err != nil {
+		t.Fatalf("InsertBatch return error %v", err)
+	}
+
+	if got := data["batch_key"].ShortURL; got != "http://localhost:8080/VajcMuGMY9h" {
+		t.Errorf("InsertBatch return short URL %q for a stored URL; want the stored one", got)
+	}
+	if data["first_key"].ShortURL != data["second_key"].ShortURL {
+		t.Errorf("InsertBatch return short URLs %q and %q for the same URL; want one",
+			data["first_key"].ShortURL, data["second_key"].ShortURL)
+	}
+	if _, err := memStorage.GetURL("batch_key"); err == nil {
+		t.Errorf("InsertBatch inserted a stored URL under a new key")
+	}
+}
+
 func TestGetUserURLs(t *testing.T) {
 
 	test := beforeEach(t)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
-	data := memStorage.GetUserURLs("some_user_UUID")
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	data, _, _ := memStorage.GetUserURLs("some_user_UUID", URLQuery{})
 
 	if len(data) == 0 {
 		t.Errorf("GetUserURLs return 0 entry; want > 0 entry")
 	}
 
-	memStorage = NewMemoryStorage(test.keeper, test.nLogger)
-	data = memStorage.GetUserURLs("fake_key_user_UUID")
+	memStorage = NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	data, _, _ = memStorage.GetUserURLs("fake_key_user_UUID", URLQuery{})
 
 	if len(data) > 0 {
 		t.Errorf("GetUserURLs return > 0 entry; want 0 entry")
 	}
 }
 
+func TestGetUserURLsPages(t *testing.T) {
+	memStorage := NewMemoryStorage(nil, nil, ScopeGlobal)
+
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, k := range []string{"e", "b", "d", "a", "c"} {
+		created := base.Add(time.Duration(i) * time.Hour)
+		v := models.DataURL{ShortURL: "http://localhost:8080/" + k, OriginalURL: "https://example.com/" + k,
+			UserID: "owner", CreatedAt: &created}
+		if k == "d" {
+			v.DeletedFlag, v.DeletedAt = true, &created
+		}
+		if _, err := memStorage.InsertURL(k, v); err != nil {
+			t.Fatalf("InsertURL return error %v", err)
+		}
+	}
+	if _, err := memStorage.InsertURL("x", models.DataURL{ShortURL: "http://localhost:8080/x",
+		OriginalURL: "https://example.com/x", UserID: "stranger"}); err != nil {
+		t.Fatalf("InsertURL return error %v", err)
+	}
+
+	keys := func(data []models.DataURLite) string {
+		var b strings.Builder
+		for _, d := range data {
+			b.WriteString(path.Base(d.ShortURL))
+		}
+		return b.String()
+	}
+
+	// all pages of a query
+	pages := func(q URLQuery) []string {
+		var res []string
+		for {
+			data, next, err := memStorage.GetUserURLs("owner", q)
+			if err != nil {
+				t.Fatalf("GetUserURLs return error %v", err)
+			}
+			res = append(res, keys(data))
+			if next == "" {
+				return res
+			}
+			q.PageToken = next
+		}
+	}
+
+	live := false
+	from := base.Add(time.Hour)
+	to := base.Add(3 * time.Hour)
+
+	testCases := []struct {
+		name  string
+		query URLQuery
+		want  []string
+	}{
+		{name: "created", query: URLQuery{PageSize: 2}, want: []string{"eb", "da", "c"}},
+		{name: "created desc", query: URLQuery{PageSize: 2, Desc: true}, want: []string{"ca", "db", "e"}},
+		{name: "key", query: URLQuery{Sort: SortKey, PageSize: 3}, want: []string{"abc", "de"}},
+		{name: "key desc", query: URLQuery{Sort: SortKey, Desc: true}, want: []string{"edcba"}},
+		{name: "live", query: URLQuery{Sort: SortKey, Deleted: &live}, want: []string{"abce"}},
+		{name: "range", query: URLQuery{CreatedFrom: &from, CreatedTo: &to}, want: []string{"bd"}},
+		{name: "search", query: URLQuery{Search: "EXAMPLE.com/C"}, want: []string{"c"}},
+		{name: "none", query: URLQuery{Search: "missing"}, want: []string{""}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pages(tc.query); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("GetUserURLs pages = %q; want %q", got, tc.want)
+			}
+		})
+	}
+
+	// a token is only valid for the sort order it was issued for
+	_, next, _ := memStorage.GetUserURLs("owner", URLQuery{PageSize: 1})
+	if _, _, err := memStorage.GetUserURLs("owner", URLQuery{PageSize: 1, Sort: SortKey, PageToken: next}); err != ErrInvalidPageToken {
+		t.Errorf("GetUserURLs return error %v; want %v", err, ErrInvalidPageToken)
+	}
+	if _, _, err := memStorage.GetUserURLs("owner", URLQuery{PageToken: "!"}); err != ErrInvalidPageToken {
+		t.Errorf("GetUserURLs return error %v; want %v", err, ErrInvalidPageToken)
+	}
+
+	// purged URLs leave the index
+	if n, err := memStorage.PurgeURLs(time.Now()); err != nil || n != 1 {
+		t.Fatalf("PurgeURLs return %d, %v; want 1", n, err)
+	}
+	if got := pages(URLQuery{Sort: SortKey}); !reflect.DeepEqual(got, []string{"abce"}) {
+		t.Errorf("GetUserURLs pages after PurgeURLs = %q; want %q", got, []string{"abce"})
+	}
+}
+
 func TestSaveURL(t *testing.T) {
 	data := models.DataURL{}
 	test := beforeEach(t)
 	test.keeper.On("Save", "some_key", data).Return(data, nil)
 
-	memStorage := NewMemoryStorage(test.keeper, test.nLogger)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
 	_, err := memStorage.SaveURL("some_key", data)
 
 	if err != nil {
 		t.Errorf("SaveURL return error %v", err)
 	}
 
-	memStorage = NewMemoryStorage(nil, test.nLogger)
+	memStorage = NewMemoryStorage(nil, test.nLogger, ScopeGlobal)
 	_, err = memStorage.SaveURL("some_key", data)
 
 	if err != nil {
 		t.Errorf("SaveURL return error %v", err)
 	}
 }
+
+func TestExpireURLs(t *testing.T) {
+
+	test := beforeEach(t)
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	memStorage.data["expired"] = models.DataURL{ShortURL: "http://localhost:8080/expired",
+		OriginalURL: "https://www.bing.com", UserID: "some_user_UUID", ExpiresAt: &past}
+	memStorage.data["alive"] = models.DataURL{ShortURL: "http://localhost:8080/alive",
+		OriginalURL: "https://www.yahoo.com", UserID: "some_user_UUID", ExpiresAt: &future}
+
+	test.keeper.On("UpdateBatch", models.DeleteURL{UserID: "some_user_UUID",
+		ShortURLs: []string{"expired"}}).Return(nil).Once()
+
+	count, err := memStorage.ExpireURLs(now)
+	if err != nil || count != 1 {
+		t.Fatalf("ExpireURLs return %v, %v; want 1, nil", count, err)
+	}
+
+	if !memStorage.data["expired"].DeletedFlag || memStorage.data["alive"].DeletedFlag {
+		t.Errorf("ExpireURLs marked %v; want only the expired URL", memStorage.data)
+	}
+
+	// the expired URL is already marked, the keeper must not be called again
+	if count, err = memStorage.ExpireURLs(now); err != nil || count != 0 {
+		t.Errorf("ExpireURLs return %v, %v; want 0, nil", count, err)
+	}
+}
+
+func TestUseClick(t *testing.T) {
+
+	test := beforeEach(t)
+	limited := models.DataURL{ShortURL: "http://localhost:8080/limited",
+		OriginalURL: "https://www.bing.com", MaxClicks: 2, Clicks: 1}
+	last := limited
+	last.Clicks = 2
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	memStorage.data["limited"] = limited
+
+	// unlimited links are not counted, the keeper must not be called
+	if _, err := memStorage.UseClick("some_key"); err != nil {
+		t.Errorf("UseClick return error %v", err)
+	}
+
+	test.keeper.On("AddClick", "limited", limited).Return(last, nil).Once()
+
+	got, err := memStorage.UseClick("limited")
+	if err != nil || got.Clicks != 2 {
+		t.Fatalf("UseClick return %v, %v; want 2 clicks", got.Clicks, err)
+	}
+
+	// the last click has been taken, the keeper must not be called again
+	if _, err = memStorage.UseClick("limited"); err != ErrClicksExhausted {
+		t.Errorf("UseClick return error %v; want %v", err, ErrClicksExhausted)
+	}
+}
+
+func TestAddVariantClick(t *testing.T) {
+
+	test := beforeEach(t)
+	split := models.DataURL{ShortURL: "http://localhost:8080/split", OriginalURL: "https://www.bing.com",
+		Variants: []models.Variant{{URL: "https://www.bing.com/a", Weight: 70}, {URL: "https://www.bing.com/b", Weight: 30}}}
+	counted := split
+	counted.Variants = []models.Variant{{URL: "https://www.bing.com/a", Weight: 70},
+		{URL: "https://www.bing.com/b", Weight: 30, Clicks: 1}}
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	memStorage.data["split"] = split
+	before, _ := memStorage.GetURL("split")
+
+	// the keeper is given the counted entry
+	test.keeper.On("AddVariantClick", "split", counted, 2).Return(counted, nil).Once()
+
+	if err := memStorage.AddVariantClick("split", 2); err != nil {
+		t.Fatalf("AddVariantClick return error %v", err)
+	}
+
+	got, _ := memStorage.GetURL("split")
+	if got.Variants[1].Clicks != 1 {
+		t.Errorf("AddVariantClick counted %v; want 1 click of the second variant", got.Variants)
+	}
+
+	// the copies of the entry handed out before are not changed
+	if before.Variants[1].Clicks != 0 {
+		t.Errorf("AddVariantClick changed a copy of the entry: %v", before.Variants)
+	}
+
+	// the keeper must not be called for the unknown variants
+	for _, variant := range []int{0, 3} {
+		if err := memStorage.AddVariantClick("split", variant); err != ErrNotFound {
+			t.Errorf("AddVariantClick(%d) return error %v; want %v", variant, err, ErrNotFound)
+		}
+	}
+
+	// the greater count of the keeper is taken, a failed click is not counted
+	shared := counted
+	shared.Variants = []models.Variant{{URL: "https://www.bing.com/a", Weight: 70, Clicks: 5},
+		{URL: "https://www.bing.com/b", Weight: 30, Clicks: 1}}
+	test.keeper.On("AddVariantClick", "split", mock.Anything, 1).Return(shared, nil).Once()
+	test.keeper.On("AddVariantClick", "split", mock.Anything, 2).Return(counted, errors.New("disk full")).Once()
+
+	if err := memStorage.AddVariantClick("split", 1); err != nil {
+		t.Fatalf("AddVariantClick return error %v", err)
+	}
+	if err := memStorage.AddVariantClick("split", 2); err == nil {
+		t.Errorf("AddVariantClick return no error of the keeper")
+	}
+	if got, _ = memStorage.GetURL("split"); got.Variants[0].Clicks != 5 || got.Variants[1].Clicks != 1 {
+		t.Errorf("AddVariantClick counted %v; want 5 and 1 clicks", got.Variants)
+	}
+}
+
+func TestUseClickConcurrent(t *testing.T) {
+
+	test := beforeEach(t)
+	once := models.DataURL{ShortURL: "http://localhost:8080/once",
+		OriginalURL: "https://www.bing.com", MaxClicks: 1}
+	used := once
+	used.Clicks = 1
+
+	// the keeper must be asked for the last click only once
+	test.keeper.On("AddClick", "once", once).Return(used, nil).Once()
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	memStorage.data["once"] = once
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		success int
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := memStorage.UseClick("once"); err == nil {
+				mu.Lock()
+				success++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if success != 1 {
+		t.Errorf("UseClick succeeded %d times; want 1", success)
+	}
+}
+
+func TestUpdateURL(t *testing.T) {
+
+	test := beforeEach(t)
+	updated := models.DataURL{UUID: "some_UUID", ShortURL: "http://localhost:8080/VajcMuGMY9h",
+		OriginalURL: "https://www.bing.com", UserID: "some_user_UUID"}
+	test.keeper.On("Update", "some_key", updated).Return(updated, nil).Once()
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+
+	// only the owner may change the link, the keeper must not be called otherwise
+	if _, err := memStorage.UpdateURL("some_key", "other_user_UUID", "https://www.bing.com"); err != ErrNotOwner {
+		t.Errorf("UpdateURL return error %v; want %v", err, ErrNotOwner)
+	}
+
+	if _, err := memStorage.UpdateURL("missing_key", "some_user_UUID", "https://www.bing.com"); err != ErrNotFound {
+		t.Errorf("UpdateURL return error %v; want %v", err, ErrNotFound)
+	}
+
+	got, err := memStorage.UpdateURL("some_key", "some_user_UUID", "https://www.bing.com")
+	if err != nil || got.OriginalURL != updated.OriginalURL {
+		t.Fatalf("UpdateURL return %v, %v; want %v", got, err, updated)
+	}
+
+	if cur, _ := memStorage.GetURL("some_key"); cur.OriginalURL != "https://www.bing.com" {
+		t.Errorf("GetURL return %v after UpdateURL; want https://www.bing.com", cur.OriginalURL)
+	}
+}
+
+func TestUpdateTags(t *testing.T) {
+
+	test := beforeEach(t)
+	updated := models.DataURL{UUID: "some_UUID", ShortURL: "http://localhost:8080/VajcMuGMY9h",
+		OriginalURL: "https://www.google.com", UserID: "some_user_UUID", Tags: []string{"search", "q3"}}
+	test.keeper.On("Update", "some_key", updated).Return(updated, nil).Once()
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+
+	if _, err := memStorage.UpdateTags("some_key", "other_user_UUID", []string{"search"}); err != ErrNotOwner {
+		t.Errorf("UpdateTags return error %v; want %v", err, ErrNotOwner)
+	}
+
+	if _, err := memStorage.UpdateTags("some_key", "some_user_UUID", updated.Tags); err != nil {
+		t.Fatalf("UpdateTags return error %v", err)
+	}
+
+	// the links are filtered by all of the tags
+	if data, _, _ := memStorage.GetUserURLs("some_user_UUID", URLQuery{Tags: []string{"q3", "search"}}); len(data) != 1 ||
+		len(data[0].Tags) != 2 {
+		t.Errorf("GetUserURLs return %v; want the tagged entry", data)
+	}
+	if data, _, _ := memStorage.GetUserURLs("some_user_UUID", URLQuery{Tags: []string{"q3", "sales"}}); len(data) != 0 {
+		t.Errorf("GetUserURLs return %v; want no entry", data)
+	}
+}
+
+func TestDomainKey(t *testing.T) {
+	memStorage := NewMemoryStorage(nil, nil, ScopeGlobal)
+
+	// The same short key is bound on the default and on an additional domain
+	for _, v := range []models.DataURL{
+		{ShortURL: "http://localhost:8080/promo", OriginalURL: "https://example.com/default"},
+		{ShortURL: "https://go.example.com/promo", OriginalURL: "https://example.com/branded", Domain: "go.example.com"},
+	} {
+		if _, err := memStorage.InsertURL(DataKey(v), v); err != nil {
+			t.Fatalf("InsertURL return error %v", err)
+		}
+	}
+
+	for k, got := range map[string]string{
+		"promo":                DomainKey("", "promo"),
+		"go.example.com/promo": DomainKey("go.example.com", "promo"),
+	} {
+		if got != k {
+			t.Errorf("DomainKey() = %q; want %q", got, k)
+		}
+
+		domain, key := SplitKey(k)
+		if DomainKey(domain, key) != k {
+			t.Errorf("SplitKey(%q) = %q, %q", k, domain, key)
+		}
+	}
+
+	for k, want := range map[string]string{
+		"promo":                "https://example.com/default",
+		"go.example.com/promo": "https://example.com/branded",
+	} {
+		v, err := memStorage.GetURL(k)
+		if err != nil || v.OriginalURL != want {
+			t.Errorf("GetURL(%q) = %q, %v; want %q", k, v.OriginalURL, err, want)
+		}
+	}
+}
+
+func TestSearchUserURLs(t *testing.T) {
+	memStorage := NewMemoryStorage(nil, nil, ScopeGlobal)
+
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	for i, v := range []models.DataURL{
+		{OriginalURL: "https://shop.example.com/shoes", Tags: []string{"autumn"}},
+		{OriginalURL: "https://blog.example.com/", Note: "Shoes review"},
+		{OriginalURL: "https://example.org/"},
+	} {
+		k := fmt.Sprintf("key%d", i)
+		created := base.Add(time.Duration(i) * time.Hour)
+		v.ShortURL, v.UserID, v.CreatedAt = "http://localhost:8080/"+k, "owner", &created
+		if _, err := memStorage.InsertURL(k, v); err != nil {
+			t.Fatalf("InsertURL return error %v", err)
+		}
+	}
+
+	search := func(query string, limit int) string {
+		data, err := memStorage.SearchUserURLs("owner", query, limit)
+		if err != nil {
+			t.Fatalf("SearchUserURLs return error %v", err)
+		}
+		var b strings.Builder
+		for _, d := range data {
+			b.WriteString(path.Base(d.ShortURL))
+		}
+		return b.String()
+	}
+
+	testCases := []struct {
+		query string
+		limit int
+		want  string
+	}{
+		{query: "SHOES", want: "key1key0"},
+		{query: "shoes", limit: 1, want: "key1"},
+		{query: "shoes autumn", want: "key0"},
+		{query: "key2", want: "key2"},
+		{query: "example.org", want: "key2"},
+		{query: "e", want: "key2key1key0"},
+		{query: "boots", want: ""},
+		{query: " ", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := search(tc.query, tc.limit); got != tc.want {
+			t.Errorf("SearchUserURLs(%q) = %q; want %q", tc.query, got, tc.want)
+		}
+	}
+
+	// the index follows the changes of the URLs
+	if _, err := memStorage.UpdateURL("key2", "owner", "https://boots.example.net/"); err != nil {
+		t.Fatalf("UpdateURL return error %v", err)
+	}
+	if got := search("example.org", 0); got != "" {
+		t.Errorf("SearchUserURLs after UpdateURL = %q; want no URL", got)
+	}
+	if got := search("boots", 0); got != "key2" {
+		t.Errorf("SearchUserURLs after UpdateURL = %q; want key2", got)
+	}
+	if data, _ := memStorage.SearchUserURLs("stranger", "shoes", 0); len(data) != 0 {
+		t.Errorf("SearchUserURLs return %v for another user; want no URL", data)
+	}
+}
+
+func TestRestoreAndPurgeURLs(t *testing.T) {
+
+	test := beforeEach(t)
+	data := models.DataURL{UUID: "deleted_UUID", ShortURL: "http://localhost:8080/deleted",
+		OriginalURL: "https://www.bing.com", UserID: "some_user_UUID"}
+	del := models.DeleteURL{UserID: "some_user_UUID", ShortURLs: []string{"deleted"}}
+	test.keeper.On("Save", "deleted", data).Return(data, nil).Once()
+	test.keeper.On("UpdateBatch", del).Return(nil).Twice()
+	test.keeper.On("RestoreBatch", del).Return(nil).Once()
+	test.keeper.On("Purge", "http://localhost:8080/deleted").Return(nil).Once()
+
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	if _, err := memStorage.InsertURL("deleted", data); err != nil {
+		t.Fatalf("InsertURL return error %v", err)
+	}
+
+	if err := memStorage.DeleteURLs(del); err != nil {
+		t.Fatalf("DeleteURLs return error %v", err)
+	}
+
+	if err := memStorage.RestoreURLs(del); err != nil {
+		t.Fatalf("RestoreURLs return error %v", err)
+	}
+
+	if cur, _ := memStorage.GetURL("deleted"); cur.DeletedFlag || cur.DeletedAt != nil {
+		t.Errorf("GetURL return deleted %v at %v after RestoreURLs", cur.DeletedFlag, cur.DeletedAt)
+	}
+
+	if err := memStorage.DeleteURLs(del); err != nil {
+		t.Fatalf("DeleteURLs return error %v", err)
+	}
+
+	// the URL deleted within the grace period is kept
+	if n, err := memStorage.PurgeURLs(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("PurgeURLs return %d, %v; want 0, nil", n, err)
+	}
+
+	if n, err := memStorage.PurgeURLs(time.Now().Add(time.Second)); err != nil || n != 1 {
+		t.Errorf("PurgeURLs return %d, %v; want 1, nil", n, err)
+	}
+
+	if _, err := memStorage.GetURL("deleted"); err == nil {
+		t.Errorf("GetURL found the purged URL")
+	}
+}
+
+// clickKeeper is a keeper recording the click events.
+type clickKeeper struct {
+	*MockKeeper
+	events []models.ClickEvent
+}
+
+func (k *clickKeeper) SaveClicks(events ...models.ClickEvent) error {
+	k.events = append(k.events, events...)
+	return nil
+}
+
+func TestSaveClicks(t *testing.T) {
+	test := beforeEach(t)
+	events := []models.ClickEvent{{Key: "some_key"}, {Key: "go.example.com/some_key"}}
+
+	// the events are dropped if the keeper does not record the clicks
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+	if err := memStorage.SaveClicks(events...); err != nil {
+		t.Errorf("SaveClicks return error %v", err)
+	}
+
+	keeper := &clickKeeper{MockKeeper: test.keeper}
+	memStorage = NewMemoryStorage(keeper, test.nLogger, ScopeGlobal)
+	if err := memStorage.SaveClicks(events...); err != nil {
+		t.Errorf("SaveClicks return error %v", err)
+	}
+	if len(keeper.events) != 2 {
+		t.Errorf("keeper saved %d events; want 2", len(keeper.events))
+	}
+}
+
+func TestGetURLStats(t *testing.T) {
+	test := beforeEach(t)
+	memStorage := NewMemoryStorage(test.keeper, test.nLogger, ScopeGlobal)
+
+	day := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	err := memStorage.SaveClicks(
+		models.ClickEvent{Time: day.Add(time.Hour), Key: "some_key", Referrer: "a", UserAgent: "x", IP: "1"},
+		models.ClickEvent{Time: day.Add(2 * time.Hour), Key: "some_key", Referrer: "b", UserAgent: "x", IP: "1"},
+		models.ClickEvent{Time: day.Add(2 * time.Hour), Key: "some_key", Referrer: "b", UserID: "some_user_UUID"},
+		models.ClickEvent{Time: day.Add(50 * time.Hour), Key: "some_key"},
+		models.ClickEvent{Time: day.Add(time.Hour), Key: "other_key"},
+	)
+	if err != nil {
+		t.Fatalf("SaveClicks return error %v", err)
+	}
+
+	q := StatsQuery{From: day, To: day.Add(48 * time.Hour), Interval: IntervalDay, Top: 1}
+	stats, err := memStorage.GetURLStats("some_key", "some_user_UUID", q)
+	if err != nil {
+		t.Fatalf("GetURLStats return error %v", err)
+	}
+
+	if stats.Clicks != 3 || stats.Unique != 2 {
+		t.Errorf("GetURLStats return %d clicks, %d unique; want 3, 2", stats.Clicks, stats.Unique)
+	}
+	if len(stats.Buckets) != 2 || stats.Buckets[0].Clicks != 3 || stats.Buckets[1].Clicks != 0 ||
+		!stats.Buckets[1].Start.Equal(day.Add(24*time.Hour)) {
+		t.Errorf("GetURLStats return buckets %v; want 3 clicks on the first day and none on the second", stats.Buckets)
+	}
+	if len(stats.Referrers) != 1 || stats.Referrers[0] != (models.StatsCount{Value: "b", Clicks: 2}) {
+		t.Errorf("GetURLStats return referrers %v; want [{b 2}]", stats.Referrers)
+	}
+
+	q.Interval = IntervalHour
+	q.To = day.Add(3 * time.Hour)
+	if stats, _ = memStorage.GetURLStats("some_key", "some_user_UUID", q); len(stats.Buckets) != 3 || stats.Buckets[2].Unique != 2 {
+		t.Errorf("GetURLStats return hourly buckets %v; want 3 with 2 unique visitors in the last", stats.Buckets)
+	}
+
+	if _, err = memStorage.GetURLStats("some_key", "another_user", q); err != ErrNotOwner {
+		t.Errorf("GetURLStats return error %v; want %v", err, ErrNotOwner)
+	}
+	if _, err = memStorage.GetURLStats("missing", "some_user_UUID", q); err != ErrNotFound {
+		t.Errorf("GetURLStats return error %v; want %v", err, ErrNotFound)
+	}
+
+	// the stats of a deleted URL are not reported until it is purged
+	memStorage = NewMemoryStorage(nil, test.nLogger, ScopeGlobal)
+	_, _ = memStorage.InsertURL("gone_key", models.DataURL{OriginalURL: "https://www.google.com",
+		ShortURL: "http://localhost:8080/gone_key", UserID: "some_user_UUID"})
+	_ = memStorage.SaveClicks(models.ClickEvent{Time: day.Add(time.Hour), Key: "gone_key"})
+	if err = memStorage.DeleteURLs(models.DeleteURL{UserID: "some_user_UUID", ShortURLs: []string{"gone_key"}}); err != nil {
+		t.Fatalf("DeleteURLs return error %v", err)
+	}
+	if _, err = memStorage.GetURLStats("gone_key", "some_user_UUID", q); err != ErrGone {
+		t.Errorf("GetURLStats return error %v; want %v", err, ErrGone)
+	}
+	if _, err = memStorage.GetURLStats("gone_key", "another_user", q); err != ErrNotOwner {
+		t.Errorf("GetURLStats return error %v; want %v", err, ErrNotOwner)
+	}
+}
+
+type sketchKeeper struct {
+	clickKeeper
+	sketches []models.ClickSketch
+}
+
+func (k *sketchKeeper) LoadClicks(fn func(models.ClickEvent)) error {
+	for _, e := range k.events {
+		fn(e)
+	}
+	return nil
+}
+
+func (k *sketchKeeper) SaveSketches(sketches ...models.ClickSketch) error {
+	k.sketches = append(k.sketches, sketches...)
+	return nil
+}
+
+func (k *sketchKeeper) LoadSketches(fn func(models.ClickSketch)) error {
+	for _, cs := range k.sketches {
+		fn(cs)
+	}
+	return nil
+}
+
+func TestSaveSketches(t *testing.T) {
+	test := beforeEach(t)
+	keeper := &sketchKeeper{clickKeeper: clickKeeper{MockKeeper: test.keeper}}
+	memStorage := NewMemoryStorage(keeper, test.nLogger, ScopeGlobal)
+
+	hour := time.Date(2026, 10, 16, 1, 0, 0, 0, time.UTC)
+	err := memStorage.SaveClicks(
+		models.ClickEvent{Time: hour, Key: "some_key", IP: "1"},
+		models.ClickEvent{Time: hour.Add(time.Minute), Key: "some_key", IP: "1"},
+		models.ClickEvent{Time: hour.Add(2 * time.Minute), Key: "some_key", UserID: "some_user_UUID"},
+	)
+	if err != nil {
+		t.Fatalf("SaveClicks return error %v", err)
+	}
+	if len(keeper.sketches) != 1 || !keeper.sketches[0].Start.Equal(hour) {
+		t.Fatalf("keeper saved sketches %v; want one of the hour %v", keeper.sketches, hour)
+	}
+
+	// the visitors of the reloaded storage are merged from the saved sketches
+	memStorage = NewMemoryStorage(keeper, test.nLogger, ScopeGlobal)
+	q := StatsQuery{From: hour, To: hour.Add(time.Hour), Interval: IntervalHour}
+	stats, err := memStorage.GetURLStats("some_key", "some_user_UUID", q)
+	if err != nil {
+		t.Fatalf("GetURLStats return error %v", err)
+	}
+	if stats.Clicks != 3 || stats.Unique != 2 || stats.Buckets[0].Unique != 2 {
+		t.Errorf("GetURLStats return %d clicks, %d unique; want 3, 2", stats.Clicks, stats.Unique)
+	}
+	if stats.UniqueError <= 0 {
+		t.Errorf("GetURLStats return unique error %v; want the error of the sketches", stats.UniqueError)
+	}
+
+	// a malformed sketch is skipped
+	keeper.sketches[0].Sketch = []byte{0}
+	memStorage = NewMemoryStorage(keeper, test.nLogger, ScopeGlobal)
+	if stats, _ = memStorage.GetURLStats("some_key", "some_user_UUID", q); stats.Clicks != 3 || stats.Unique != 0 {
+		t.Errorf("GetURLStats return %d clicks, %d unique; want 3, 0", stats.Clicks, stats.Unique)
+	}
+}
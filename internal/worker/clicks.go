@@ -0,0 +1,148 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wurt83ow/tinyurl/internal/models"
+	"go.uber.org/zap"
+)
+
+// DefaultClickFlushInterval is the interval between the flushes of the recorded clicks
+// used when none is given.
+const DefaultClickFlushInterval = time.Second
+
+// DefaultClickBatchSize is the number of the recorded clicks flushed without waiting
+// for the next interval, used when none is given.
+const DefaultClickBatchSize = 500
+
+// clickBufferSize is the number of the clicks buffered for the recorder, the clicks
+// recorded while the buffer is full are dropped.
+const clickBufferSize = 4096
+
+// ClickStorage is an interface representing a data storage with a method to save click events.
+type ClickStorage interface {
+	SaveClicks(events ...models.ClickEvent) error
+}
+
+// ClickRecorder is a background worker that saves the click events of the redirects
+// in batches, so that the redirects do not wait for the storage.
+type ClickRecorder struct {
+	wg         *sync.WaitGroup
+	cancelFunc context.CancelFunc
+	log        Log
+	storage    ClickStorage
+	events     chan models.ClickEvent
+	batch      []models.ClickEvent
+	interval   time.Duration
+	size       int
+	dropped    atomic.Int64
+}
+
+// NewClickRecorder creates a new ClickRecorder instance saving the click events to the
+// storage, with the provided logger, interval between the flushes and batch size.
+// Non-positive arguments fall back to DefaultClickFlushInterval and DefaultClickBatchSize.
+func NewClickRecorder(log Log, storage ClickStorage, interval time.Duration, size int) *ClickRecorder {
+	if interval <= 0 {
+		interval = DefaultClickFlushInterval
+	}
+
+	if size <= 0 {
+		size = DefaultClickBatchSize
+	}
+
+	return &ClickRecorder{
+		wg:       new(sync.WaitGroup),
+		log:      log,
+		storage:  storage,
+		events:   make(chan models.ClickEvent, clickBufferSize),
+		batch:    make([]models.ClickEvent, 0, size),
+		interval: interval,
+		size:     size,
+	}
+}
+
+// Start starts the recorder with the given parent context.
+func (c *ClickRecorder) Start(pctx context.Context) {
+	c.log.Warn("Start click recorder")
+	ctx, cancelFunc := context.WithCancel(pctx)
+	c.cancelFunc = cancelFunc
+	c.wg.Add(1)
+	go c.run(ctx)
+}
+
+// Stop stops the recorder and waits for the buffered clicks to be saved.
+func (c *ClickRecorder) Stop() {
+	c.cancelFunc()
+	c.wg.Wait()
+	c.log.Warn("Click recorder exited!")
+}
+
+// Record adds the click event to the recorder's buffer without blocking.
+// The event is dropped if the buffer is full.
+func (c *ClickRecorder) Record(e models.ClickEvent) {
+	select {
+	case c.events <- e:
+	default:
+		c.dropped.Add(1)
+	}
+}
+
+// run is a goroutine that collects the click events and saves them once the batch
+// is full or periodically. The buffered events are saved when the context is done.
+func (c *ClickRecorder) run(ctx context.Context) {
+	defer c.wg.Done()
+
+	t := time.NewTicker(c.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.drain()
+			return
+		case e := <-c.events:
+			c.batch = append(c.batch, e)
+			if len(c.batch) >= c.size {
+				c.flush()
+			}
+		case <-t.C:
+			c.flush()
+		}
+	}
+}
+
+// drain saves the events left in the buffer.
+func (c *ClickRecorder) drain() {
+	for {
+		select {
+		case e := <-c.events:
+			c.batch = append(c.batch, e)
+			if len(c.batch) >= c.size {
+				c.flush()
+			}
+		default:
+			c.flush()
+			return
+		}
+	}
+}
+
+// flush saves the collected events and reports the events dropped since the last flush.
+// The storage may keep the saved slice, a new batch is started.
+func (c *ClickRecorder) flush() {
+	if n := c.dropped.Swap(0); n != 0 {
+		c.log.Info("click events dropped", zap.Int64("count", n))
+	}
+
+	if len(c.batch) == 0 {
+		return
+	}
+
+	if err := c.storage.SaveClicks(c.batch...); err != nil {
+		c.log.Info("cannot save click events", zap.Error(err), zap.Int("count", len(c.batch)))
+	}
+	c.batch = make([]models.ClickEvent, 0, c.size)
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/wurt83ow/tinyurl/internal/models"
+)
+
+// MockClickStorage is a mock implementation of the ClickStorage interface for testing.
+type MockClickStorage struct {
+	mu     sync.Mutex
+	events []models.ClickEvent
+	saves  int
+}
+
+func (m *MockClickStorage) SaveClicks(events ...models.ClickEvent) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.events = append(m.events, events...)
+	m.saves++
+	return nil
+}
+
+func (m *MockClickStorage) saved() (int, int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.events), m.saves
+}
+
+func TestClickRecorder_Batch(t *testing.T) {
+	log := new(MockLog)
+	log.On("Warn", mock.Anything, mock.Anything)
+	storage := new(MockClickStorage)
+	recorder := NewClickRecorder(log, storage, time.Hour, 2)
+
+	recorder.Start(context.Background())
+	for i := 0; i < 5; i++ {
+		recorder.Record(models.ClickEvent{Key: "abc"})
+	}
+
+	// the full batches are saved without waiting for the interval
+	deadline := time.Now().Add(time.Second)
+	for n, _ := storage.saved(); n < 4 && time.Now().Before(deadline); n, _ = storage.saved() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n, saves := storage.saved(); n != 4 || saves != 2 {
+		t.Fatalf("saved %d events in %d batches; want 4 in 2", n, saves)
+	}
+
+	// the rest is saved on stop
+	recorder.Stop()
+	if n, _ := storage.saved(); n != 5 {
+		t.Errorf("saved %d events after Stop; want 5", n)
+	}
+}
+
+func TestClickRecorder_Drop(t *testing.T) {
+	log := new(MockLog)
+	log.On("Info", "click events dropped", mock.Anything).Once()
+	storage := new(MockClickStorage)
+	recorder := NewClickRecorder(log, storage, time.Hour, DefaultClickBatchSize)
+
+	// the recorder is not started, the events over the buffer are dropped without blocking
+	for i := 0; i < clickBufferSize+3; i++ {
+		recorder.Record(models.ClickEvent{Key: "abc"})
+	}
+	if n := recorder.dropped.Load(); n != 3 {
+		t.Errorf("dropped %d events; want 3", n)
+	}
+
+	recorder.flush()
+	log.AssertExpectations(t)
+}
+
+func TestNewClickRecorder_Defaults(t *testing.T) {
+	recorder := NewClickRecorder(new(MockLog), new(MockClickStorage), 0, 0)
+
+	if recorder.interval != DefaultClickFlushInterval || recorder.size != DefaultClickBatchSize {
+		t.Errorf("NewClickRecorder %v, %d; want %v, %d", recorder.interval, recorder.size,
+			DefaultClickFlushInterval, DefaultClickBatchSize)
+	}
+}
@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DefaultSweepInterval is the interval between the sweeps of the expired URLs
+// used when none is given.
+const DefaultSweepInterval = time.Minute
+
+// DefaultPurgeInterval is the interval between the purges of the deleted URLs
+// used when none is given.
+const DefaultPurgeInterval = time.Hour
+
+// DefaultPurgeGrace is the period the deleted URLs can be restored in before
+// they are purged, used when none is given.
+const DefaultPurgeGrace = 30 * 24 * time.Hour
+
+// ExpiringStorage is an interface representing a data storage with a method to expire URLs.
+type ExpiringStorage interface {
+	ExpireURLs(now time.Time) (int, error)
+}
+
+// PurgingStorage is an interface representing a data storage with a method to purge deleted URLs.
+type PurgingStorage interface {
+	PurgeURLs(before time.Time) (int, error)
+}
+
+// Sweeper is a background worker that periodically sweeps the URLs of the storage:
+// it marks the expired URLs as deleted or purges the URLs deleted long ago.
+type Sweeper struct {
+	wg         *sync.WaitGroup
+	cancelFunc context.CancelFunc
+	log        Log
+	name       string
+	sweepFunc  func(now time.Time) (int, error)
+	interval   time.Duration
+}
+
+// NewSweeper creates a new Sweeper instance marking the expired URLs of the storage
+// as deleted, with the provided logger and interval between the sweeps.
+// A non-positive interval falls back to DefaultSweepInterval.
+func NewSweeper(log Log, storage ExpiringStorage, interval time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = DefaultSweepInterval
+	}
+
+	return &Sweeper{
+		wg:        new(sync.WaitGroup),
+		log:       log,
+		name:      "expired",
+		sweepFunc: storage.ExpireURLs,
+		interval:  interval,
+	}
+}
+
+// NewPurger creates a new Sweeper instance purging the URLs of the storage deleted
+// more than grace ago, with the provided logger and interval between the purges.
+// Non-positive arguments fall back to DefaultPurgeInterval and DefaultPurgeGrace.
+func NewPurger(log Log, storage PurgingStorage, interval time.Duration, grace time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+
+	if grace <= 0 {
+		grace = DefaultPurgeGrace
+	}
+
+	return &Sweeper{
+		wg:   new(sync.WaitGroup),
+		log:  log,
+		name: "purged",
+		sweepFunc: func(now time.Time) (int, error) {
+			return storage.PurgeURLs(now.Add(-grace))
+		},
+		interval: interval,
+	}
+}
+
+// Start starts the sweeper with the given parent context.
+func (s *Sweeper) Start(pctx context.Context) {
+	s.log.Warn("Start " + s.name + " sweeper")
+	ctx, cancelFunc := context.WithCancel(pctx)
+	s.cancelFunc = cancelFunc
+	s.wg.Add(1)
+	go s.run(ctx)
+}
+
+// Stop stops the sweeper and waits for the current sweep to complete.
+func (s *Sweeper) Stop() {
+	s.cancelFunc()
+	s.wg.Wait()
+	s.log.Warn("Sweeper of " + s.name + " urls exited!")
+}
+
+// run is a goroutine that periodically sweeps the URLs.
+func (s *Sweeper) run(ctx context.Context) {
+	defer s.wg.Done()
+
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			s.sweep(now)
+		}
+	}
+}
+
+// sweep sweeps the URLs at the time now.
+func (s *Sweeper) sweep(now time.Time) {
+	count, err := s.sweepFunc(now)
+	if err != nil {
+		s.log.Info("cannot sweep "+s.name+" urls", zap.Error(err))
+		return
+	}
+
+	if count != 0 {
+		s.log.Info(s.name+" urls", zap.Int("count", count))
+	}
+}
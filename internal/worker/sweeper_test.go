@@ -0,0 +1,91 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap/zapcore"
+)
+
+// MockExpiringStorage is a mock implementation of the ExpiringStorage interface for testing.
+type MockExpiringStorage struct {
+	mock.Mock
+}
+
+func (m *MockExpiringStorage) ExpireURLs(now time.Time) (int, error) {
+	args := m.Called(now)
+	return args.Int(0), args.Error(1)
+}
+
+// MockPurgingStorage is a mock implementation of the PurgingStorage interface for testing.
+type MockPurgingStorage struct {
+	mock.Mock
+}
+
+func (m *MockPurgingStorage) PurgeURLs(before time.Time) (int, error) {
+	args := m.Called(before)
+	return args.Int(0), args.Error(1)
+}
+
+func TestSweeper_Sweep(t *testing.T) {
+	log := new(MockLog)
+	storage := new(MockExpiringStorage)
+	sweeper := NewSweeper(log, storage, 10*time.Millisecond)
+
+	log.On("Warn", mock.Anything, []zapcore.Field(nil))
+	log.On("Info", "expired urls", mock.Anything)
+	storage.On("ExpireURLs", mock.Anything).Return(1, nil)
+
+	sweeper.Start(context.Background())
+
+	// Wait for a few ticks of the sweeper
+	time.Sleep(50 * time.Millisecond)
+
+	sweeper.Stop()
+
+	storage.AssertCalled(t, "ExpireURLs", mock.Anything)
+	log.AssertCalled(t, "Info", "expired urls", mock.Anything)
+}
+
+func TestNewSweeper_DefaultInterval(t *testing.T) {
+	sweeper := NewSweeper(new(MockLog), new(MockExpiringStorage), 0)
+
+	if sweeper.interval != DefaultSweepInterval {
+		t.Errorf("NewSweeper interval %v; want %v", sweeper.interval, DefaultSweepInterval)
+	}
+}
+
+func TestPurger_Sweep(t *testing.T) {
+	log := new(MockLog)
+	storage := new(MockPurgingStorage)
+	purger := NewPurger(log, storage, time.Hour, 24*time.Hour)
+
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+	log.On("Info", "purged urls", mock.Anything).Once()
+	storage.On("PurgeURLs", now.Add(-24*time.Hour)).Return(2, nil).Once()
+
+	purger.sweep(now)
+
+	storage.AssertExpectations(t)
+	log.AssertExpectations(t)
+}
+
+func TestNewPurger_Defaults(t *testing.T) {
+	log := new(MockLog)
+	storage := new(MockPurgingStorage)
+	purger := NewPurger(log, storage, 0, 0)
+
+	if purger.interval != DefaultPurgeInterval {
+		t.Errorf("NewPurger interval %v; want %v", purger.interval, DefaultPurgeInterval)
+	}
+
+	now := time.Now()
+	storage.On("PurgeURLs", now.Add(-DefaultPurgeGrace)).Return(0, nil).Once()
+
+	purger.sweep(now)
+
+	storage.AssertExpectations(t)
+}
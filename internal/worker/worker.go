@@ -1,4 +1,4 @@
-// Package worker provides a background worker for deleting URLs from storage.
+// Package worker provides background workers for deleting and expiring URLs in storage.
 // It includes interfaces and an implementation for managing jobs.
 package worker
 
@@ -18,19 +18,28 @@ type Log interface {
 	Info(string, ...zapcore.Field)
 }
 
-// Storage is an interface representing a data storage with a method to delete URLs.
+// Storage is an interface representing a data storage with methods to delete and restore URLs.
 type Storage interface {
 	DeleteURLs(delUrls ...models.DeleteURL) error
+	RestoreURLs(resUrls ...models.DeleteURL) error
 }
 
-// Worker is an interface representing a background worker for deleting URLs.
+// Worker is an interface representing a background worker for deleting and restoring URLs.
 type Worker interface {
 	// Start starts the worker with the given parent context.
 	Start(pctx context.Context)
 	// Stop stops the worker.
 	Stop()
-	// Add adds a job to the worker's job channel.
+	// Add adds a deletion job to the worker's job channel.
 	Add(models.DeleteURL)
+	// Restore adds a restoration job to the worker's job channel.
+	Restore(models.DeleteURL)
+}
+
+// job is a deletion or a restoration of the user's URLs.
+type job struct {
+	restore bool
+	data    models.DeleteURL
 }
 
 // worker is an implementation of the Worker interface.
@@ -39,8 +48,8 @@ type worker struct {
 	cancelFunc context.CancelFunc
 	log        Log
 	storage    Storage
-	jobChan    chan models.DeleteURL
-	result     []models.DeleteURL
+	jobChan    chan job
+	result     []job
 }
 
 // NewWorker creates a new Worker instance with the provided logger and storage.
@@ -49,8 +58,8 @@ func NewWorker(log Log, storage Storage) Worker {
 		wg:      new(sync.WaitGroup),
 		log:     log,
 		storage: storage,
-		jobChan: make(chan models.DeleteURL, 1024),
-		result:  make([]models.DeleteURL, 0),
+		jobChan: make(chan job, 1024),
+		result:  make([]job, 0),
 	}
 
 	return &w
@@ -73,12 +82,18 @@ func (w *worker) Stop() {
 	w.log.Warn("All workers exited!")
 }
 
-// Add adds a job to the worker's job channel.
+// Add adds a deletion job to the worker's job channel.
 func (w *worker) Add(d models.DeleteURL) {
-	w.jobChan <- d
+	w.jobChan <- job{data: d}
 }
 
-// spawnWorkers is a goroutine that handles jobs and periodically performs the actual deletion.
+// Restore adds a restoration job to the worker's job channel.
+func (w *worker) Restore(d models.DeleteURL) {
+	w.jobChan <- job{restore: true, data: d}
+}
+
+// spawnWorkers is a goroutine that handles jobs and periodically performs the actual deletion
+// and restoration.
 func (w *worker) spawnWorkers(ctx context.Context) {
 	defer w.wg.Done()
 
@@ -96,13 +111,32 @@ func (w *worker) spawnWorkers(ctx context.Context) {
 	}
 }
 
-// doWork performs the actual deletion of URLs from storage.
+// doWork performs the actual deletion and restoration of URLs in storage.
+// The jobs are applied in the order they were added, consecutive jobs of
+// the same kind are applied in one go.
 func (w *worker) doWork(ctx context.Context) {
-	if len(w.result) != 0 {
-		err := w.storage.DeleteURLs(w.result...)
-		if err != nil {
-			w.log.Info("cannot save delUrls", zap.Error(err))
+	for len(w.result) != 0 {
+		n := 1
+		for n < len(w.result) && w.result[n].restore == w.result[0].restore {
+			n++
 		}
-		w.result = nil
+
+		urls := make([]models.DeleteURL, n)
+		for i, j := range w.result[:n] {
+			urls[i] = j.data
+		}
+
+		if w.result[0].restore {
+			if err := w.storage.RestoreURLs(urls...); err != nil {
+				w.log.Info("cannot save resUrls", zap.Error(err))
+			}
+		} else {
+			if err := w.storage.DeleteURLs(urls...); err != nil {
+				w.log.Info("cannot save delUrls", zap.Error(err))
+			}
+		}
+
+		w.result = w.result[n:]
 	}
+	w.result = nil
 }
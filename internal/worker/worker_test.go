@@ -33,6 +33,11 @@ func (m *MockStorage) DeleteURLs(delUrls ...models.DeleteURL) error {
 	return args.Error(0)
 }
 
+func (m *MockStorage) RestoreURLs(resUrls ...models.DeleteURL) error {
+	args := m.Called(resUrls)
+	return args.Error(0)
+}
+
 func TestWorker_StartStop(t *testing.T) {
 	// Create a new instance of your worker with a mock logger and storage
 	log := new(MockLog)
@@ -96,3 +101,41 @@ func TestWorker_DoWork(t *testing.T) {
 	// Check that the Warn method was called with the expected arguments
 	log.AssertExpectations(t)
 }
+
+func TestWorker_DoWorkOrder(t *testing.T) {
+	log := new(MockLog)
+	storage := new(MockStorage)
+	w := NewWorker(log, storage).(*worker)
+
+	del1 := models.DeleteURL{UserID: "user", ShortURLs: []string{"a"}}
+	del2 := models.DeleteURL{UserID: "user", ShortURLs: []string{"b"}}
+	res := models.DeleteURL{UserID: "user", ShortURLs: []string{"a"}}
+
+	w.result = []job{{data: del1}, {data: del2}, {restore: true, data: res}, {data: del2}}
+
+	var calls []string
+	storage.On("DeleteURLs", []models.DeleteURL{del1, del2}).Return(nil).Once().
+		Run(func(mock.Arguments) { calls = append(calls, "delete") })
+	storage.On("RestoreURLs", []models.DeleteURL{res}).Return(nil).Once().
+		Run(func(mock.Arguments) { calls = append(calls, "restore") })
+	storage.On("DeleteURLs", []models.DeleteURL{del2}).Return(nil).Once().
+		Run(func(mock.Arguments) { calls = append(calls, "delete") })
+
+	w.doWork(context.Background())
+
+	storage.AssertExpectations(t)
+
+	want := []string{"delete", "restore", "delete"}
+	if len(calls) != len(want) {
+		t.Fatalf("doWork calls %v; want %v", calls, want)
+	}
+	for i := range want {
+		if calls[i] != want[i] {
+			t.Errorf("doWork calls %v; want %v", calls, want)
+		}
+	}
+
+	if len(w.result) != 0 {
+		t.Errorf("doWork left %d jobs", len(w.result))
+	}
+}
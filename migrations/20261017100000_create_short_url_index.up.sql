@@ -0,0 +1 @@
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_short_url ON dataurl (short_url);
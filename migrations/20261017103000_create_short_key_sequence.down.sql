@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS short_key_seq;
@@ -0,0 +1,2 @@
+CREATE SEQUENCE IF NOT EXISTS short_key_seq MINVALUE 0;
+SELECT setval('short_key_seq', (SELECT count(*) FROM dataurl));
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS uniq_scope_url;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_url ON dataurl (original_url);
+ALTER TABLE dataurl DROP COLUMN IF EXISTS scope_id;
@@ -0,0 +1,3 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS scope_id VARCHAR(50) NOT NULL DEFAULT '';
+DROP INDEX IF EXISTS uniq_url;
+CREATE UNIQUE INDEX IF NOT EXISTS uniq_scope_url ON dataurl (scope_id, original_url);
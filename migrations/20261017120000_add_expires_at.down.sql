@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_expires_at;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS expires_at;
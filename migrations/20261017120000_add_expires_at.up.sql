@@ -0,0 +1,2 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+CREATE INDEX IF NOT EXISTS idx_expires_at ON dataurl (expires_at) WHERE expires_at IS NOT NULL;
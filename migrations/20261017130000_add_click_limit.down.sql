@@ -0,0 +1,2 @@
+ALTER TABLE dataurl DROP COLUMN IF EXISTS clicks;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS max_clicks;
@@ -0,0 +1,2 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS max_clicks INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS clicks INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS password_hash BYTEA;
@@ -0,0 +1,2 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+UPDATE dataurl SET deleted_at = now() WHERE is_deleted AND deleted_at IS NULL;
@@ -0,0 +1 @@
+ALTER TABLE dataurl DROP COLUMN IF EXISTS redirect_code;
@@ -0,0 +1 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS redirect_code INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+ALTER TABLE dataurl DROP COLUMN IF EXISTS utm_campaign;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS utm_medium;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS utm_source;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS query_policy;
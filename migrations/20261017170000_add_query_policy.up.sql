@@ -0,0 +1,4 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS query_policy TEXT NOT NULL DEFAULT '';
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS utm_source TEXT NOT NULL DEFAULT '';
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS utm_medium TEXT NOT NULL DEFAULT '';
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS utm_campaign TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS url_rules (
+	url_id VARCHAR(50) NOT NULL REFERENCES dataurl (correlation_id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	platform TEXT NOT NULL DEFAULT '',
+	language TEXT NOT NULL DEFAULT '',
+	referrer TEXT NOT NULL DEFAULT '',
+	url TEXT NOT NULL,
+	PRIMARY KEY (url_id, position)
+	);
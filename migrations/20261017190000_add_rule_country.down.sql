@@ -0,0 +1 @@
+ALTER TABLE url_rules DROP COLUMN IF EXISTS country;
@@ -0,0 +1 @@
+ALTER TABLE url_rules ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS url_variants;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS sticky;
@@ -0,0 +1,9 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS sticky BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE TABLE IF NOT EXISTS url_variants (
+	url_id VARCHAR(50) NOT NULL REFERENCES dataurl (correlation_id) ON DELETE CASCADE,
+	position INTEGER NOT NULL,
+	url TEXT NOT NULL,
+	weight INTEGER NOT NULL,
+	clicks INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (url_id, position)
+	);
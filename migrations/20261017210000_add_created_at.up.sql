@@ -0,0 +1 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ;
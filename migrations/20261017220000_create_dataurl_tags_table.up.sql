@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS dataurl_tags (
+	url_id VARCHAR(50) NOT NULL REFERENCES dataurl (correlation_id) ON DELETE CASCADE,
+	tag VARCHAR(32) NOT NULL,
+	PRIMARY KEY (url_id, tag)
+	);
+CREATE INDEX IF NOT EXISTS idx_dataurl_tags_tag ON dataurl_tags (tag);
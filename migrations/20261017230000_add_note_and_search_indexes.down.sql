@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_dataurl_tags_tag_trgm;
+DROP INDEX IF EXISTS idx_dataurl_note_trgm;
+DROP INDEX IF EXISTS idx_dataurl_original_url_trgm;
+DROP INDEX IF EXISTS idx_dataurl_key_trgm;
+ALTER TABLE dataurl DROP COLUMN IF EXISTS note;
@@ -0,0 +1,6 @@
+ALTER TABLE dataurl ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_dataurl_key_trgm ON dataurl USING gin ((reverse(split_part(reverse(short_url), '/', 1))) gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_dataurl_original_url_trgm ON dataurl USING gin (original_url gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_dataurl_note_trgm ON dataurl USING gin (note gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_dataurl_tags_tag_trgm ON dataurl_tags USING gin (tag gin_trgm_ops);
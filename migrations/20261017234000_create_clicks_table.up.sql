@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS clicks (
+	id BIGSERIAL PRIMARY KEY,
+	short_url TEXT NOT NULL,
+	domain VARCHAR(255) NOT NULL DEFAULT '',
+	clicked_at TIMESTAMPTZ NOT NULL,
+	referrer TEXT NOT NULL DEFAULT '',
+	user_agent TEXT NOT NULL DEFAULT '',
+	ip VARCHAR(64) NOT NULL DEFAULT '',
+	user_id VARCHAR(50) NOT NULL DEFAULT ''
+	);
+CREATE INDEX IF NOT EXISTS idx_clicks_url_time ON clicks (domain, short_url, clicked_at);
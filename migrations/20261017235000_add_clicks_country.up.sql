@@ -0,0 +1 @@
+ALTER TABLE clicks ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
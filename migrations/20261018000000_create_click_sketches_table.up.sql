@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS click_sketches (
+	short_url TEXT NOT NULL,
+	domain VARCHAR(255) NOT NULL DEFAULT '',
+	bucket_start TIMESTAMPTZ NOT NULL,
+	sketch BYTEA NOT NULL,
+	PRIMARY KEY (domain, short_url, bucket_start)
+	);